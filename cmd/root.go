@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -12,8 +13,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/daemon"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/discovery"
+	"github.com/derekxwang/tcs/internal/events"
 	"github.com/derekxwang/tcs/internal/monitor"
 	"github.com/derekxwang/tcs/internal/scheduler"
 	"github.com/derekxwang/tcs/internal/tmux"
@@ -71,10 +74,15 @@ func init() {
 	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(windowCmd)
 	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(groupCmd)
 	rootCmd.AddCommand(messageCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(eventsCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(sendCmd)
+	rootCmd.AddCommand(detectCmd)
 }
 
 // initConfig reads in config file and ENV variables
@@ -114,9 +122,14 @@ This command will:
 var tuiCmd = &cobra.Command{
 	Use:   "tui",
 	Short: "Start the terminal user interface",
-	Long:  `Launch the interactive TUI dashboard for monitoring and controlling Claude usage.`,
+	Long: `Launch the interactive TUI dashboard for monitoring and controlling Claude usage.
+
+With --attach the TUI follows a running 'tcs daemon' instead of starting its own
+scheduler and window discovery. Processing and window scans are forwarded to the
+daemon. Without a running daemon it falls back to standalone mode.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runTUI()
+		attach, _ := cmd.Flags().GetBool("attach")
+		return runTUI(attach)
 	},
 }
 
@@ -153,6 +166,46 @@ var windowScanCmd = &cobra.Command{
 	},
 }
 
+var windowSuperviseCmd = &cobra.Command{
+	Use:   "supervise <target>",
+	Short: "Set what happens when Claude exits in a window",
+	Long: `Set the supervision policy of a window:
+  none     - do nothing (messages are held until Claude is back)
+  notify   - show a tmux message when Claude exits
+  restart  - run the restart command with backoff until Claude is back
+
+Examples:
+  tcs window supervise work:1 --policy restart
+  tcs window supervise work:1 --policy restart --cmd "claude --continue --model opus"
+  tcs window supervise work:1 --policy default`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, _ := cmd.Flags().GetString("policy")
+		command, _ := cmd.Flags().GetString("cmd")
+		return runWindowSupervise(args[0], policy, command, cmd.Flags().Changed("cmd"))
+	},
+}
+
+var windowSpawnCmd = &cobra.Command{
+	Use:   "spawn",
+	Short: "Launch a new Claude window",
+	Long: `Create a tmux window, start Claude Code in it, wait for the prompt and register
+the window with its message queue. The session is created if it does not exist.
+
+Examples:
+  tcs window spawn --session work --name api --cwd ~/src/api
+  tcs window spawn --session work --name api --cmd "claude --resume" --message "continue"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, _ := cmd.Flags().GetString("session")
+		name, _ := cmd.Flags().GetString("name")
+		cwd, _ := cmd.Flags().GetString("cwd")
+		command, _ := cmd.Flags().GetString("cmd")
+		message, _ := cmd.Flags().GetString("message")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		return runWindowSpawn(session, name, cwd, command, message, timeout)
+	},
+}
+
 // Queue management commands
 var queueCmd = &cobra.Command{
 	Use:   "queue",
@@ -181,6 +234,88 @@ var queueStatusCmd = &cobra.Command{
 	},
 }
 
+var queuePauseCmd = &cobra.Command{
+	Use:   "pause <target>",
+	Short: "Stop sending messages from a window's queue",
+	Long: `Pause a window's queue. Pending messages stay queued until the queue is
+resumed, and routes skip the window meanwhile.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runQueueSetActive(args[0], false)
+	},
+}
+
+var queueResumeCmd = &cobra.Command{
+	Use:   "resume <target>",
+	Short: "Resume sending messages from a paused queue",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runQueueSetActive(args[0], true)
+	},
+}
+
+var queueSetCmd = &cobra.Command{
+	Use:   "set <target>",
+	Short: "Change a window's queue settings",
+	Long: `Change the priority of a window's queue or the minimum time between two
+messages sent to the window.
+
+Examples:
+  tcs queue set work:1 --priority 8
+  tcs queue set work:1 --min-spacing 2m
+  tcs queue set work:1 --min-spacing 0`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		priority, _ := cmd.Flags().GetInt("priority")
+		spacing, _ := cmd.Flags().GetDuration("min-spacing")
+		return runQueueSet(args[0], priority, cmd.Flags().Changed("priority"), spacing, cmd.Flags().Changed("min-spacing"))
+	},
+}
+
+// Window group commands
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage window groups",
+	Long: `Manage named pools of windows. Messages sent to "group:<name>" go to the
+least busy idle member of the group.`,
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a window group",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		description, _ := cmd.Flags().GetString("description")
+		return runGroupCreate(args[0], description)
+	},
+}
+
+var groupAddCmd = &cobra.Command{
+	Use:   "add <name> <target>...",
+	Short: "Add windows to a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupAdd(args[0], args[1:])
+	},
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <target>...",
+	Short: "Remove windows from a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupRemove(args[0], args[1:])
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List window groups and their members",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupList()
+	},
+}
+
 // Message scheduling commands
 var messageCmd = &cobra.Command{
 	Use:   "message",
@@ -191,12 +326,24 @@ var messageCmd = &cobra.Command{
 var messageAddCmd = &cobra.Command{
 	Use:   "add <target> <content>",
 	Short: "Schedule a message to a window target",
-	Long:  `Schedule a message to a tmux window target (format: session:window, e.g., "project:0")`,
-	Args:  cobra.ExactArgs(2),
+	Long: `Schedule a message to a tmux window target (format: session:window, e.g., "project:0").
+
+The target can also be a route that picks a Claude window when the message is sent:
+  any                      any Claude window
+  any:model=opus,idle      an Opus window where Claude is not busy
+  any:mode=plan            a window in plan mode
+  any:version=1.0          a window running Claude Code 1.0.x
+  group:backend            the least busy idle window in the "backend" group
+
+Use --expires for messages that are worthless if sent late. It takes a
+duration after the scheduled time (30m, 2h) or a time (14:30,
+"2025-01-15 09:30"). Messages not sent by then are marked expired.`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		priority, _ := cmd.Flags().GetInt("priority")
 		when, _ := cmd.Flags().GetString("when")
-		return runMessageAdd(args[0], args[1], priority, when)
+		expires, _ := cmd.Flags().GetString("expires")
+		return runMessageAdd(args[0], args[1], priority, when, expires)
 	},
 }
 
@@ -236,6 +383,34 @@ var messageDeleteCmd = &cobra.Command{
 	},
 }
 
+var messageFailedCmd = &cobra.Command{
+	Use:   "failed [target]",
+	Short: "List failed messages with their attempt history",
+	Long: `List the dead-letter queue: messages that ran out of attempts, optionally
+filtered by target window, with every attempt that was made to send them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := ""
+		if len(args) > 0 {
+			target = args[0]
+		}
+		return runMessageFailed(target)
+	},
+}
+
+var messageRequeueCmd = &cobra.Command{
+	Use:   "requeue [message-id...]",
+	Short: "Send failed messages again",
+	Long: `Move failed messages back to the queue, due now and with their attempts reset.
+Use --all to requeue every failed message and --target to send them to another
+window or route.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		target, _ := cmd.Flags().GetString("target")
+		return runMessageRequeue(args, all, target)
+	},
+}
+
 // Status command
 var statusCmd = &cobra.Command{
 	Use:   "status",
@@ -245,6 +420,45 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// Events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show the event log",
+	Long: `Show recent message, window, usage and error events recorded by the daemon
+and the TUI. Use --follow to keep printing new events as they happen.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		follow, _ := cmd.Flags().GetBool("follow")
+		limit, _ := cmd.Flags().GetInt("limit")
+		kind, _ := cmd.Flags().GetString("kind")
+		return runEvents(follow, limit, kind)
+	},
+}
+
+// Pause commands
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Stop sending scheduled messages",
+	Long: `Stop all schedulers, including those of other tcs processes, from sending
+messages until 'tcs resume', or for a while with --for. Messages keep queueing
+and go out once sending resumes.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		duration, _ := cmd.Flags().GetDuration("for")
+		reason, _ := cmd.Flags().GetString("reason")
+		return runPause(duration, reason)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume sending scheduled messages",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runResume()
+	},
+}
+
 // Config commands
 var configCmd = &cobra.Command{
 	Use:   "config",
@@ -277,30 +491,98 @@ var sendCmd = &cobra.Command{
 	},
 }
 
+// Detection commands
+var detectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Inspect Claude detection",
+	Long:  `Inspect how TCS decides whether a tmux window runs Claude.`,
+}
+
+var detectExplainCmd = &cobra.Command{
+	Use:   "explain <target>",
+	Short: "Show which detection rules fire for a window",
+	Long: `Evaluate the Claude detection rules (tmux.detection_rules) against a window and
+print every rule that fired, its weight and the final score.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDetectExplain(args[0])
+	},
+}
+
 func init() {
+	// TUI flags
+	tuiCmd.Flags().Bool("attach", false, "Follow a running daemon instead of starting a scheduler")
+
+	// Detect subcommands
+	detectCmd.AddCommand(detectExplainCmd)
+
 	// Window subcommands
 	windowCmd.AddCommand(windowListCmd)
 	windowCmd.AddCommand(windowScanCmd)
+	windowCmd.AddCommand(windowSpawnCmd)
+	windowCmd.AddCommand(windowSuperviseCmd)
+
+	// Window flags
+	windowSpawnCmd.Flags().String("session", "", "Session to create the window in (created if missing)")
+	windowSpawnCmd.Flags().String("name", "", "Name of the new window")
+	windowSpawnCmd.Flags().String("cwd", "", "Working directory for the new window")
+	windowSpawnCmd.Flags().String("cmd", tmux.DefaultClaudeCommand, "Command used to start Claude")
+	windowSpawnCmd.Flags().String("message", "", "First message to send once Claude is ready")
+	windowSpawnCmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for the Claude prompt")
+	_ = windowSpawnCmd.MarkFlagRequired("session")
+
+	windowSuperviseCmd.Flags().String("policy", "", "Supervision policy: none, notify, restart or default")
+	windowSuperviseCmd.Flags().String("cmd", "", "Restart command (empty uses tmux.restart_command)")
+	_ = windowSuperviseCmd.MarkFlagRequired("policy")
 
 	// Queue subcommands
 	queueCmd.AddCommand(queueListCmd)
 	queueCmd.AddCommand(queueStatusCmd)
+	queueCmd.AddCommand(queuePauseCmd)
+	queueCmd.AddCommand(queueResumeCmd)
+	queueCmd.AddCommand(queueSetCmd)
+
+	queueSetCmd.Flags().Int("priority", 5, "Queue priority (1-10)")
+	queueSetCmd.Flags().Duration("min-spacing", 0, "Minimum time between messages (0 disables)")
+
+	// Group subcommands
+	groupCmd.AddCommand(groupCreateCmd)
+	groupCmd.AddCommand(groupAddCmd)
+	groupCmd.AddCommand(groupRemoveCmd)
+	groupCmd.AddCommand(groupListCmd)
+
+	groupCreateCmd.Flags().String("description", "", "Description of the group")
 
 	// Message subcommands
 	messageCmd.AddCommand(messageAddCmd)
 	messageCmd.AddCommand(messageListCmd)
 	messageCmd.AddCommand(messageEditCmd)
 	messageCmd.AddCommand(messageDeleteCmd)
+	messageCmd.AddCommand(messageFailedCmd)
+	messageCmd.AddCommand(messageRequeueCmd)
 
 	// Message flags
 	messageAddCmd.Flags().Int("priority", 5, "Message priority (1-10)")
 	messageAddCmd.Flags().String("when", "now", "When to send (now, +5m, 14:30, etc.)")
+	messageAddCmd.Flags().String("expires", "", "Deadline: a duration after the scheduled time (30m) or a time (14:30)")
 
 	messageEditCmd.Flags().String("content", "", "New message content")
 	messageEditCmd.Flags().String("target", "", "New target window")
 	messageEditCmd.Flags().Int("priority", -1, "New priority (1-10)")
 	messageEditCmd.Flags().String("when", "", "New schedule time")
 
+	messageRequeueCmd.Flags().Bool("all", false, "Requeue all failed messages")
+	messageRequeueCmd.Flags().String("target", "", "Send the messages to this window or route instead")
+
+	// Events flags
+	eventsCmd.Flags().BoolP("follow", "f", false, "Keep printing new events until interrupted")
+	eventsCmd.Flags().Int("limit", 50, "Number of recent events to show")
+	eventsCmd.Flags().String("kind", "", "Only show events of this kind (message, window, usage, error)")
+
+	// Pause flags
+	pauseCmd.Flags().Duration("for", 0, "Resume automatically after this long (e.g. 2h)")
+	pauseCmd.Flags().String("reason", "", "Why sending is paused, shown in status and the dashboard")
+
 	// Config subcommands
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configShowCmd)
@@ -348,6 +630,23 @@ func runWindowList() error {
 			fmt.Printf("  Window %d: %s (%s)\n", window.WindowIndex, window.WindowName, claudeStatus)
 			fmt.Printf("    Target: %s\n", window.Target)
 			fmt.Printf("    Priority: %d\n", window.Priority)
+			if window.SupervisionPolicy != "" {
+				fmt.Printf("    Supervision: %s\n", window.SupervisionPolicy)
+			}
+			if window.HasClaude && window.ClaudeModel+window.ClaudePermissionMode+window.ClaudeVersion != "" {
+				fmt.Printf("    Claude: model %s, mode %s, version %s\n",
+					valueOrUnknown(window.ClaudeModel), valueOrUnknown(window.ClaudePermissionMode), valueOrUnknown(window.ClaudeVersion))
+			}
+			if window.ClaudePID > 0 {
+				started := ""
+				if window.ClaudeStartedAt != nil {
+					started = fmt.Sprintf(", started %s", window.ClaudeStartedAt.Format(time.RFC3339))
+				}
+				fmt.Printf("    Claude Process: PID %d%s\n", window.ClaudePID, started)
+				if window.ClaudeArgs != "" {
+					fmt.Printf("    Claude Args: %s\n", window.ClaudeArgs)
+				}
+			}
 			fmt.Printf("    Last Seen: %s\n", window.LastSeen.Format(time.RFC3339))
 			if window.LastActivity != nil {
 				fmt.Printf("    Last Activity: %s\n", window.LastActivity.Format(time.RFC3339))
@@ -383,45 +682,20 @@ func runWindowScan() error {
 	windowCount := 0
 	claudeCount := 0
 
+	// Detect Claude using configured detection method, reading the process table once
+	tmuxConfig := config.GetTmuxConfig()
+	detector := tmuxClient.NewClaudeDetector(
+		tmuxConfig.ClaudeDetectionMethod,
+		tmuxConfig.ClaudeProcessNames,
+		utils.ConfiguredRuleSet(),
+	)
+
 	for _, session := range sessions {
 		for _, window := range session.Windows {
-			// Detect Claude using configured detection method
-			hasClaude := false
-			cfg := config.Get()
-			detectionMethod := cfg.Tmux.ClaudeDetectionMethod
-			processNames := cfg.Tmux.ClaudeProcessNames
-
-			switch detectionMethod {
-			case "process":
-				// Process-based detection only
-				processDetected, err := tmuxClient.DetectClaudeProcessWithNames(window.Target, processNames)
-				if err == nil && processDetected {
-					hasClaude = true
-				}
-			case "text":
-				// Content-based detection only
-				content, err := tmuxClient.CapturePane(window.Target, 50)
-				if err == nil {
-					hasClaude = utils.IsClaudeWindow(content)
-				}
-			case "both":
-				fallthrough
-			default:
-				// Try process detection first (more reliable for Claude Code)
-				processDetected, err := tmuxClient.DetectClaudeProcessWithNames(window.Target, processNames)
-				if err == nil && processDetected {
-					hasClaude = true
-				} else {
-					// Fallback to content-based detection
-					content, err := tmuxClient.CapturePane(window.Target, 50)
-					if err == nil {
-						hasClaude = utils.IsClaudeWindow(content)
-					}
-				}
-			}
+			hasClaude, process := detector.Detect(window.Target)
 
 			// Create or update window
-			_, err = database.CreateOrUpdateTmuxWindow(
+			dbWindow, err := database.CreateOrUpdateTmuxWindow(
 				database.GetDB(),
 				window.SessionName,
 				window.WindowIndex,
@@ -433,6 +707,30 @@ func runWindowScan() error {
 				continue
 			}
 
+			// Record the Claude process details when known
+			pid := 0
+			var startedAt *time.Time
+			var args []string
+			if process != nil && process.PID > 0 {
+				pid = process.PID
+				startedAt = &process.StartTime
+				args = process.Args
+			}
+			if err := database.UpdateTmuxWindowClaudeProcess(database.GetDB(), dbWindow.ID, pid, startedAt, args); err != nil {
+				fmt.Printf("Warning: failed to record Claude process for %s: %v\n", window.Target, err)
+			}
+
+			// Record the model, permission mode and version
+			if hasClaude {
+				status := tmuxClient.ReadClaudeStatus(window.Target, process)
+				err = database.UpdateTmuxWindowClaudeStatus(database.GetDB(), dbWindow.ID, status.Model, status.PermissionMode, status.Version)
+			} else {
+				err = database.ClearTmuxWindowClaudeStatus(database.GetDB(), dbWindow.ID)
+			}
+			if err != nil {
+				fmt.Printf("Warning: failed to record Claude status for %s: %v\n", window.Target, err)
+			}
+
 			windowCount++
 			if hasClaude {
 				claudeCount++
@@ -445,6 +743,232 @@ func runWindowScan() error {
 	return nil
 }
 
+func runWindowSupervise(target, policy, command string, commandSet bool) error {
+	switch policy {
+	case database.SupervisionNone, database.SupervisionNotify, database.SupervisionRestart:
+	case "default":
+		policy = ""
+	default:
+		return fmt.Errorf("invalid policy '%s' (expected none, notify, restart or default)", policy)
+	}
+
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	window, err := database.GetTmuxWindow(database.GetDB(), target)
+	if err != nil {
+		return fmt.Errorf("window '%s' not found (run 'tcs window scan' first): %w", target, err)
+	}
+
+	updates := map[string]interface{}{
+		"supervision_policy": policy,
+	}
+	if commandSet {
+		updates["restart_command"] = command
+	}
+
+	if err := database.GetDB().Model(window).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update window: %w", err)
+	}
+	database.InvalidateActiveTmuxWindowsCache()
+
+	if policy == "" {
+		fmt.Printf("Window %s now uses the default supervision policy (%s)\n", target, config.GetTmuxConfig().SupervisionPolicy)
+	} else {
+		fmt.Printf("Window %s supervision policy set to '%s'\n", target, policy)
+	}
+	if policy == database.SupervisionRestart || (policy == "" && config.GetTmuxConfig().SupervisionPolicy == database.SupervisionRestart) {
+		restartCommand := window.RestartCommand
+		if commandSet {
+			restartCommand = command
+		}
+		if restartCommand == "" {
+			restartCommand = config.GetTmuxConfig().RestartCommand
+		}
+		fmt.Printf("Restart command: %s\n", restartCommand)
+	}
+	fmt.Println("Note: supervision runs in 'tcs daemon' or 'tcs tui'.")
+
+	return nil
+}
+
+func runWindowSpawn(session, name, cwd, command, message string, timeout time.Duration) error {
+	if session == "" {
+		return fmt.Errorf("session cannot be empty")
+	}
+	if strings.ContainsAny(session, ":.") {
+		return fmt.Errorf("session name '%s' cannot contain ':' or '.'", session)
+	}
+
+	// Resolve working directory
+	if cwd != "" {
+		if cwd == "~" || strings.HasPrefix(cwd, "~/") {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to resolve home directory: %w", err)
+			}
+			cwd = filepath.Join(homeDir, strings.TrimPrefix(cwd, "~"))
+		}
+		info, err := os.Stat(cwd)
+		if err != nil {
+			return fmt.Errorf("invalid working directory: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("working directory '%s' is not a directory", cwd)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Would spawn window '%s' in session '%s' (cwd: %s) running '%s'\n", name, session, cwd, command)
+		return nil
+	}
+
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	tmuxClient := tmux.NewClient()
+
+	fmt.Printf("Spawning Claude window in session '%s'...\n", session)
+
+	window, spawnErr := tmuxClient.SpawnClaudeWindow(tmux.SpawnOptions{
+		SessionName:    session,
+		WindowName:     name,
+		WorkingDir:     cwd,
+		Command:        command,
+		StartupTimeout: timeout,
+	})
+	if window == nil {
+		return fmt.Errorf("failed to spawn window: %w", spawnErr)
+	}
+
+	// Register the window and its queue even if Claude is slow to start so the
+	// discovery service and scheduler know about it.
+	dbWindow, err := database.CreateOrUpdateTmuxWindow(
+		database.GetDB(),
+		window.SessionName,
+		window.WindowIndex,
+		window.WindowName,
+		spawnErr == nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register window %s: %w", window.Target, err)
+	}
+
+	if _, err := database.GetOrCreateWindowMessageQueue(database.GetDB(), dbWindow.ID); err != nil {
+		return fmt.Errorf("failed to create message queue for %s: %w", window.Target, err)
+	}
+
+	if spawnErr != nil {
+		return fmt.Errorf("window %s created but Claude did not start: %w", window.Target, spawnErr)
+	}
+
+	fmt.Printf("Claude is ready in %s (window ID: %d)\n", window.Target, dbWindow.ID)
+
+	if message == "" {
+		return nil
+	}
+
+	// Deliver the first message and record it in the message history
+	messageSender := tmux.NewMessageSender(tmuxClient)
+	result, sendErr := messageSender.SendMessage(window.Target, message)
+
+	record := &database.Message{
+		WindowID:      dbWindow.ID,
+		Content:       message,
+		ScheduledTime: time.Now(),
+		Priority:      5,
+		Status:        database.MessageStatusSent,
+	}
+	if sendErr != nil {
+		record.Status = database.MessageStatusFailed
+		record.Error = sendErr.Error()
+	} else {
+		now := time.Now()
+		record.SentAt = &now
+	}
+	if err := database.GetDB().Create(record).Error; err != nil {
+		fmt.Printf("Warning: failed to record first message: %v\n", err)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("failed to send first message: %w", sendErr)
+	}
+
+	fmt.Printf("First message sent to %s in %s\n", window.Target, result.Duration)
+	return nil
+}
+
+func runDetectExplain(target string) error {
+	tmuxClient := tmux.NewClient()
+	if !tmuxClient.IsRunning() {
+		return fmt.Errorf("tmux server is not running")
+	}
+
+	if err := tmuxClient.ValidateTarget(target); err != nil {
+		return fmt.Errorf("invalid target '%s': %w", target, err)
+	}
+
+	rules := utils.ConfiguredRuleSet()
+	result, err := tmuxClient.EvaluateDetectionRules(target, rules)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate detection rules: %w", err)
+	}
+
+	tmuxConfig := config.GetTmuxConfig()
+	rulesSource := "built-in"
+	if len(tmuxConfig.DetectionRules) > 0 {
+		rulesSource = "config"
+	}
+
+	fmt.Printf("Detection for %s\n", target)
+	fmt.Printf("  Pane command: %s\n", result.PaneCommand)
+	fmt.Printf("  Rules: %d (%s)\n\n", len(rules.Rules()), rulesSource)
+
+	if len(result.Matches) == 0 {
+		fmt.Println("No rules fired.")
+	} else {
+		fmt.Printf("%-22s %7s  %-12s  %s\n", "RULE", "WEIGHT", "SOURCE", "MATCH")
+		for _, match := range result.Matches {
+			fmt.Printf("%-22s %+7.2f  %-12s  %s\n",
+				truncateString(match.Rule.Name, 22),
+				match.Rule.Weight,
+				match.Source,
+				truncateString(strings.ReplaceAll(match.Match, "\n", " "), 50))
+		}
+	}
+
+	verdict := "not Claude"
+	if result.IsClaude {
+		verdict = "Claude"
+	}
+	fmt.Printf("\nScore: %.2f (threshold %.2f) => %s\n", result.Score, result.Threshold, verdict)
+
+	// Process detection is reported separately since it does not contribute to the score
+	if tmuxConfig.ClaudeDetectionMethod != tmux.DetectionMethodText {
+		processes, err := tmuxClient.ScanClaudeProcesses(tmuxConfig.ClaudeProcessNames)
+		if err != nil {
+			fmt.Printf("Process detection: error (%v)\n", err)
+		} else if process, found := processes[target]; !found {
+			fmt.Printf("Process detection: false (method: %s)\n", tmuxConfig.ClaudeDetectionMethod)
+		} else {
+			fmt.Printf("Process detection: true (method: %s)\n", tmuxConfig.ClaudeDetectionMethod)
+			if process.PID > 0 {
+				fmt.Printf("  PID:     %d\n", process.PID)
+				fmt.Printf("  Started: %s\n", process.StartTime.Format(time.RFC3339))
+				fmt.Printf("  Args:    %s\n", strings.Join(process.Args, " "))
+			}
+		}
+	}
+
+	return nil
+}
+
 func runQueueList() error {
 	// Initialize database
 	if err := database.Initialize(nil); err != nil {
@@ -469,6 +993,11 @@ func runQueueList() error {
 		sessionQueues[window.SessionName] = append(sessionQueues[window.SessionName], window)
 	}
 
+	// Routed messages are counted once they have been sent
+	if err := database.SyncQueueMessageCounts(database.GetDB()); err != nil {
+		return fmt.Errorf("failed to count queued messages: %w", err)
+	}
+
 	fmt.Printf("Message Queues by Session:\n\n")
 
 	for sessionName, sessionWins := range sessionQueues {
@@ -476,16 +1005,16 @@ func runQueueList() error {
 
 		totalPending := 0
 		for _, window := range sessionWins {
-			// Get pending message count for this window
-			var pendingCount int64
-			database.GetDB().Model(&database.Message{}).
-				Where("window_id = ? AND status = ?", window.ID, database.MessageStatusPending).
-				Count(&pendingCount)
+			queue, err := database.GetOrCreateWindowMessageQueue(database.GetDB(), window.ID)
+			if err != nil {
+				fmt.Printf("  %s: Error getting queue: %v\n", window.Target, err)
+				continue
+			}
 
-			totalPending += int(pendingCount)
+			totalPending += queue.MessageCount
 
-			fmt.Printf("  %s (priority: %d) - %d pending messages\n",
-				window.Target, window.Priority, pendingCount)
+			fmt.Printf("  %s (priority: %d, %s) - %d pending messages\n",
+				window.Target, queue.Priority, queue.State(), queue.MessageCount)
 		}
 
 		fmt.Printf("  Total pending: %d messages\n\n", totalPending)
@@ -494,6 +1023,94 @@ func runQueueList() error {
 	return nil
 }
 
+func runQueueSetActive(target string, active bool) error {
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	window, err := database.GetTmuxWindow(database.GetDB(), target)
+	if err != nil {
+		return fmt.Errorf("window '%s' not found (run 'tcs window scan' first): %w", target, err)
+	}
+
+	action := "Paused"
+	if active {
+		action = "Resumed"
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would have %s queue for %s\n", strings.ToLower(action), target)
+		return nil
+	}
+
+	if err := database.SetQueueActive(database.GetDB(), window.ID, active); err != nil {
+		return fmt.Errorf("failed to update queue: %w", err)
+	}
+
+	fmt.Printf("%s queue for %s\n", action, target)
+	return nil
+}
+
+func runQueueSet(target string, priority int, prioritySet bool, spacing time.Duration, spacingSet bool) error {
+	if !prioritySet && !spacingSet {
+		return fmt.Errorf("no changes specified (use --priority or --min-spacing)")
+	}
+	if prioritySet && (priority < 1 || priority > 10) {
+		return fmt.Errorf("priority must be between 1 and 10, got %d", priority)
+	}
+	if spacing < 0 {
+		return fmt.Errorf("min spacing cannot be negative")
+	}
+
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	window, err := database.GetTmuxWindow(database.GetDB(), target)
+	if err != nil {
+		return fmt.Errorf("window '%s' not found (run 'tcs window scan' first): %w", target, err)
+	}
+
+	queue, err := database.GetOrCreateWindowMessageQueue(database.GetDB(), window.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	updates := make(map[string]interface{})
+	if prioritySet {
+		updates["priority"] = priority
+	}
+	if spacingSet {
+		updates["min_spacing"] = spacing
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would update queue for %s: %v\n", target, updates)
+		return nil
+	}
+
+	if err := database.GetDB().Model(queue).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update queue: %w", err)
+	}
+
+	fmt.Printf("Updated queue for %s\n", target)
+	if prioritySet {
+		fmt.Printf("  Priority: %d\n", priority)
+	}
+	if spacingSet {
+		if spacing == 0 {
+			fmt.Printf("  Min Spacing: none\n")
+		} else {
+			fmt.Printf("  Min Spacing: %v\n", spacing)
+		}
+	}
+	return nil
+}
+
 func runQueueStatus(sessionName string) error {
 	// Initialize database
 	if err := database.Initialize(nil); err != nil {
@@ -524,55 +1141,209 @@ func runQueueStatus(sessionName string) error {
 		return nil
 	}
 
-	if sessionName != "" {
-		fmt.Printf("Queue Status for Session: %s\n\n", sessionName)
-	} else {
-		fmt.Printf("Queue Status for All Sessions:\n\n")
+	if sessionName != "" {
+		fmt.Printf("Queue Status for Session: %s\n\n", sessionName)
+	} else {
+		fmt.Printf("Queue Status for All Sessions:\n\n")
+	}
+
+	// Fair shares of the current usage window, by window ID
+	shares, since, err := scheduler.FairShares(database.GetDB())
+	if err != nil {
+		return fmt.Errorf("failed to compute fair shares: %w", err)
+	}
+	sharesByWindow := make(map[uint]scheduler.WindowShare, len(shares))
+	for _, share := range shares {
+		sharesByWindow[share.Window.ID] = share
+	}
+	fmt.Printf("Policy: %s, shares since %s\n\n", config.GetSchedulerConfig().Policy, since.Format("15:04"))
+
+	// Group by session
+	sessionQueues := make(map[string][]database.TmuxWindow)
+	for _, window := range windows {
+		sessionQueues[window.SessionName] = append(sessionQueues[window.SessionName], window)
+	}
+
+	for sessName, sessionWins := range sessionQueues {
+		fmt.Printf("%s:\n", sessName)
+
+		for _, window := range sessionWins {
+			// Get detailed queue info
+			queue, err := database.GetOrCreateWindowMessageQueue(database.GetDB(), window.ID)
+			if err != nil {
+				fmt.Printf("  %s: Error getting queue: %v\n", window.Target, err)
+				continue
+			}
+
+			// Get pending messages
+			messages, err := database.GetPendingMessagesForWindow(database.GetDB(), window.ID, 0)
+			if err != nil {
+				fmt.Printf("  %s: Error getting messages: %v\n", window.Target, err)
+				continue
+			}
+
+			fmt.Printf("  %s:\n", window.Target)
+			fmt.Printf("    Queue Priority: %d\n", queue.Priority)
+			fmt.Printf("    State: %s\n", queue.State())
+			if share, ok := sharesByWindow[window.ID]; ok {
+				fmt.Printf("    Fair Share: %.0f%% (weight %d), Sent: %d, Deficit: %+.1f\n",
+					share.Share*100, share.Weight, share.Sent, share.Deficit)
+			} else {
+				fmt.Printf("    Fair Share: idle (weight %d)\n", max(1, window.Priority))
+			}
+			if queue.MinSpacing > 0 {
+				fmt.Printf("    Min Spacing: %v\n", queue.MinSpacing)
+			}
+			if queue.IsInFlight() {
+				fmt.Printf("    Sending Since: %s\n", queue.InFlightSince.Format("15:04:05"))
+			}
+			fmt.Printf("    Pending Messages: %d\n", len(messages))
+			if queue.LastProcessed != nil {
+				fmt.Printf("    Last Processed: %s\n", queue.LastProcessed.Format(time.RFC3339))
+				if next := queue.NextSendTime(); time.Now().Before(next) {
+					fmt.Printf("    Next Send: %s\n", next.Format("15:04:05"))
+				}
+			} else {
+				fmt.Printf("    Last Processed: never\n")
+			}
+			fmt.Printf("    Has Claude: %t\n", window.HasClaude)
+
+			if len(messages) > 0 {
+				fmt.Printf("    Next Messages:\n")
+				for i, msg := range messages {
+					if i >= 3 { // Show first 3 messages
+						fmt.Printf("      ... and %d more\n", len(messages)-3)
+						break
+					}
+					fmt.Printf("      %d. [P:%d] %s (scheduled: %s)\n",
+						msg.ID, msg.Priority,
+						truncateString(msg.Content, 50),
+						msg.ScheduledTime.Format(time.RFC3339))
+				}
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func runGroupCreate(name, description string) error {
+	if name == "" || strings.ContainsAny(name, ":, ") {
+		return fmt.Errorf("invalid group name '%s' (must not be empty or contain ':', ',' or spaces)", name)
+	}
+
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	if _, err := database.GetWindowGroup(database.GetDB(), name); err == nil {
+		return fmt.Errorf("window group '%s' already exists", name)
+	}
+
+	if dryRun {
+		fmt.Printf("Would create window group '%s'\n", name)
+		return nil
+	}
+
+	if _, err := database.CreateWindowGroup(database.GetDB(), name, description); err != nil {
+		return fmt.Errorf("failed to create window group: %w", err)
+	}
+
+	fmt.Printf("Created window group '%s'. Send messages to it with target 'group:%s'\n", name, name)
+	return nil
+}
+
+func runGroupAdd(name string, targets []string) error {
+	return updateGroupMembers(name, targets, true)
+}
+
+func runGroupRemove(name string, targets []string) error {
+	return updateGroupMembers(name, targets, false)
+}
+
+// updateGroupMembers adds or removes windows from a group
+func updateGroupMembers(name string, targets []string, add bool) error {
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	group, err := database.GetWindowGroup(database.GetDB(), name)
+	if err != nil {
+		return fmt.Errorf("window group '%s' not found: %w", name, err)
+	}
+
+	// Resolve all targets before changing anything
+	windows := make([]*database.TmuxWindow, 0, len(targets))
+	for _, target := range targets {
+		window, err := database.GetTmuxWindow(database.GetDB(), target)
+		if err != nil {
+			return fmt.Errorf("window '%s' not found (run 'tcs window scan' first): %w", target, err)
+		}
+		windows = append(windows, window)
 	}
 
-	// Group by session
-	sessionQueues := make(map[string][]database.TmuxWindow)
 	for _, window := range windows {
-		sessionQueues[window.SessionName] = append(sessionQueues[window.SessionName], window)
+		if dryRun {
+			if add {
+				fmt.Printf("Would add %s to group '%s'\n", window.Target, name)
+			} else {
+				fmt.Printf("Would remove %s from group '%s'\n", window.Target, name)
+			}
+			continue
+		}
+
+		if add {
+			if err := database.AddWindowToGroup(database.GetDB(), group, window); err != nil {
+				return fmt.Errorf("failed to add %s to group '%s': %w", window.Target, name, err)
+			}
+			fmt.Printf("Added %s to group '%s'\n", window.Target, name)
+		} else {
+			if err := database.RemoveWindowFromGroup(database.GetDB(), group, window); err != nil {
+				return fmt.Errorf("failed to remove %s from group '%s': %w", window.Target, name, err)
+			}
+			fmt.Printf("Removed %s from group '%s'\n", window.Target, name)
+		}
 	}
 
-	for sessName, sessionWins := range sessionQueues {
-		fmt.Printf("%s:\n", sessName)
+	return nil
+}
 
-		for _, window := range sessionWins {
-			// Get detailed queue info
-			queue, err := database.GetOrCreateWindowMessageQueue(database.GetDB(), window.ID)
-			if err != nil {
-				fmt.Printf("  %s: Error getting queue: %v\n", window.Target, err)
-				continue
-			}
+func runGroupList() error {
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
 
-			// Get pending messages
-			messages, err := database.GetPendingMessagesForWindow(database.GetDB(), window.ID, 0)
-			if err != nil {
-				fmt.Printf("  %s: Error getting messages: %v\n", window.Target, err)
-				continue
-			}
+	groups, err := database.GetWindowGroups(database.GetDB())
+	if err != nil {
+		return fmt.Errorf("failed to get window groups: %w", err)
+	}
 
-			fmt.Printf("  %s:\n", window.Target)
-			fmt.Printf("    Queue Priority: %d\n", queue.Priority)
-			fmt.Printf("    Pending Messages: %d\n", len(messages))
-			fmt.Printf("    Last Processed: %v\n", queue.LastProcessed)
-			fmt.Printf("    Has Claude: %t\n", window.HasClaude)
+	if len(groups) == 0 {
+		fmt.Println("No window groups. Create one with 'tcs group create <name>'.")
+		return nil
+	}
 
-			if len(messages) > 0 {
-				fmt.Printf("    Next Messages:\n")
-				for i, msg := range messages {
-					if i >= 3 { // Show first 3 messages
-						fmt.Printf("      ... and %d more\n", len(messages)-3)
-						break
-					}
-					fmt.Printf("      %d. [P:%d] %s (scheduled: %s)\n",
-						msg.ID, msg.Priority,
-						truncateString(msg.Content, 50),
-						msg.ScheduledTime.Format(time.RFC3339))
-				}
+	for _, group := range groups {
+		fmt.Printf("Group: %s (%d windows)\n", group.Name, len(group.Windows))
+		if group.Description != "" {
+			fmt.Printf("  %s\n", group.Description)
+		}
+		for _, window := range group.Windows {
+			claudeStatus := "No Claude"
+			if window.HasClaude {
+				claudeStatus = "Has Claude"
 			}
+			if !window.Active {
+				claudeStatus += ", inactive"
+			}
+			fmt.Printf("  - %s %s (%s, priority %d)\n", window.Target, window.WindowName, claudeStatus, window.Priority)
 		}
 		fmt.Println()
 	}
@@ -580,17 +1351,23 @@ func runQueueStatus(sessionName string) error {
 	return nil
 }
 
-func runMessageAdd(target, content string, priority int, when string) error {
-	// Validate target format (session:window)
+func runMessageAdd(target, content string, priority int, when, expires string) error {
+	// Validate target format (session:window or a route)
 	if target == "" {
 		return fmt.Errorf("target cannot be empty")
 	}
-	if !strings.Contains(target, ":") {
-		return fmt.Errorf("target must be in format 'session:window' (e.g., 'project:0'), got: %s", target)
-	}
-	parts := strings.SplitN(target, ":", 2)
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		return fmt.Errorf("invalid target format '%s'. Use 'session:window' (e.g., 'project:0')", target)
+	if scheduler.IsRoute(target) {
+		if _, err := scheduler.ParseRoute(target); err != nil {
+			return fmt.Errorf("invalid route '%s': %w", target, err)
+		}
+	} else {
+		if !strings.Contains(target, ":") {
+			return fmt.Errorf("target must be in format 'session:window' (e.g., 'project:0'), got: %s", target)
+		}
+		parts := strings.SplitN(target, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid target format '%s'. Use 'session:window' (e.g., 'project:0')", target)
+		}
 	}
 
 	// Validate content length (reasonable limit for CLI usage)
@@ -602,11 +1379,20 @@ func runMessageAdd(target, content string, priority int, when string) error {
 	}
 
 	// Parse schedule time with comprehensive validation
-	scheduledTime, err := parseScheduleTime(when)
+	scheduledTime, err := utils.ParseScheduleTime(when, time.Now())
 	if err != nil {
 		return fmt.Errorf("invalid schedule time: %w", err)
 	}
 
+	var expiresAt *time.Time
+	if expires != "" {
+		deadline, err := parseExpiry(expires, scheduledTime)
+		if err != nil {
+			return fmt.Errorf("invalid expiry: %w", err)
+		}
+		expiresAt = &deadline
+	}
+
 	// Initialize components
 	if err := database.Initialize(nil); err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
@@ -633,27 +1419,35 @@ func runMessageAdd(target, content string, priority int, when string) error {
 	}
 
 	// Schedule message
-	message, err := schedulerInstance.ScheduleMessage(target, content, scheduledTime, priority)
+	message, err := schedulerInstance.ScheduleMessageWithExpiry(target, content, scheduledTime, priority, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to schedule message: %w", err)
 	}
 
+	// Recorded here rather than through the scheduler callbacks, which may not
+	// run before this process exits
+	recorder := events.NewRecorder(database.GetDB())
+	recorder.RecordMessageEvent(&scheduler.MessageEvent{
+		Type:      "queued",
+		Message:   message,
+		Window:    &message.Window,
+		Timestamp: time.Now(),
+	})
+
 	fmt.Printf("Scheduled message (ID: %d) for target '%s' at %s with priority %d\n",
 		message.ID, target, scheduledTime.Format(time.RFC3339), priority)
+	if expiresAt != nil {
+		fmt.Printf("Expires at %s if not sent by then\n", expiresAt.Format(time.RFC3339))
+	}
 
 	if when == "now" {
 		// For immediate messages, start scheduler temporarily to process them
 		fmt.Println("Sending message immediately...")
+		recorder.Attach(schedulerInstance, nil, nil)
 		if err := schedulerInstance.Start(); err != nil {
 			return fmt.Errorf("failed to start scheduler: %w", err)
 		}
 
-		// Give the scheduler a moment to initialize and load the queue
-		time.Sleep(100 * time.Millisecond)
-
-		// Add the message directly to the scheduler queue for immediate processing
-		schedulerInstance.AddMessage(message)
-
 		// Trigger immediate processing
 		schedulerInstance.TriggerImmediateProcessing()
 
@@ -667,7 +1461,8 @@ func runMessageAdd(target, content string, priority int, when string) error {
 			// Check if THIS specific message was processed
 			var updatedMessage database.Message
 			err := database.GetDB().First(&updatedMessage, message.ID).Error
-			if err == nil && updatedMessage.Status != database.MessageStatusPending {
+			if err == nil && updatedMessage.Status != database.MessageStatusPending &&
+				updatedMessage.Status != database.MessageStatusSending {
 				// Our specific message was processed
 				if updatedMessage.Status == database.MessageStatusSent {
 					messageProcessed = true
@@ -757,7 +1552,133 @@ func runStatus() error {
 	} else {
 		fmt.Println("  No active usage window")
 	}
+	fmt.Println()
+
+	schedulerConfig := scheduler.DefaultConfig()
+	hold, err := scheduler.CurrentHold(database.GetDB(), schedulerConfig.QuietHours, time.Now())
+	if err != nil {
+		return err
+	}
+	if hold != nil {
+		fmt.Printf("Sending: %s\n", hold)
+	} else {
+		fmt.Println("Sending: active")
+	}
+	for _, period := range schedulerConfig.QuietHours {
+		fmt.Printf("  Quiet Hours: %s\n", period)
+	}
+
+	return nil
+}
+
+func runEvents(follow bool, limit int, kind string) error {
+	switch kind {
+	case "", database.EventKindMessage, database.EventKindWindow, database.EventKindUsage, database.EventKindError:
+	default:
+		return fmt.Errorf("invalid kind '%s' (use message, window, usage or error)", kind)
+	}
+
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	recent, err := database.GetRecentEvents(database.GetDB(), kind, limit)
+	if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+	if len(recent) == 0 && !follow {
+		fmt.Println("No events recorded yet")
+		return nil
+	}
+
+	var lastID uint
+	for _, event := range recent {
+		printEvent(event)
+		lastID = event.ID
+	}
+	if !follow {
+		return nil
+	}
+
+	// Poll for new events until interrupted
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sigChan:
+			return nil
+		case <-ticker.C:
+			newEvents, err := database.GetEventsAfter(database.GetDB(), lastID, kind, 100)
+			if err != nil {
+				return fmt.Errorf("failed to get events: %w", err)
+			}
+			for _, event := range newEvents {
+				printEvent(event)
+				lastID = event.ID
+			}
+		}
+	}
+}
+
+// printEvent prints an event as one line
+func printEvent(event database.Event) {
+	fmt.Printf("%s  %-7s  %-15s  %-20s  %s\n",
+		event.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+		event.Kind, event.Type, truncateString(event.Target, 20), event.Summary)
+}
+
+func runPause(duration time.Duration, reason string) error {
+	if duration < 0 {
+		return fmt.Errorf("--for must be positive")
+	}
+
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	var resumeAt *time.Time
+	if duration > 0 {
+		until := time.Now().Add(duration)
+		resumeAt = &until
+	}
+	if err := database.PauseScheduling(database.GetDB(), resumeAt, reason); err != nil {
+		return fmt.Errorf("failed to pause scheduling: %w", err)
+	}
+
+	if resumeAt != nil {
+		fmt.Printf("Paused sending until %s\n", resumeAt.Format("Jan 2 15:04"))
+	} else {
+		fmt.Println("Paused sending until 'tcs resume'")
+	}
+	return nil
+}
+
+func runResume() error {
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	if err := database.ResumeScheduling(database.GetDB()); err != nil {
+		return fmt.Errorf("failed to resume scheduling: %w", err)
+	}
 
+	hold, err := scheduler.CurrentHold(database.GetDB(), scheduler.DefaultConfig().QuietHours, time.Now())
+	if err != nil {
+		return err
+	}
+	if hold != nil {
+		fmt.Printf("Resumed, but messages are held for %s\n", hold)
+		return nil
+	}
+	fmt.Println("Resumed sending")
 	return nil
 }
 
@@ -788,7 +1709,7 @@ func runConfigShow() error {
 	fmt.Printf("TUI Refresh Rate: %s\n", cfg.TUI.RefreshRate)
 	fmt.Printf("Max Messages: %d\n", cfg.Usage.MaxMessages)
 	fmt.Printf("Usage Window: %s\n", cfg.Usage.WindowDuration)
-	fmt.Printf("Smart Scheduler: %t\n", cfg.Scheduler.SmartEnabled)
+	fmt.Printf("Scheduling Policy: %s\n", cfg.Scheduler.Policy)
 	fmt.Printf("Cron Scheduler: %t\n", cfg.Scheduler.CronEnabled)
 	fmt.Printf("Log Level: %s\n", cfg.Logging.Level)
 
@@ -837,7 +1758,7 @@ func runMessageList(target string) error {
 	defer database.Close()
 
 	// Get messages, optionally filtered by target
-	var messages []database.Message
+	var messages, inFlight []database.Message
 	var err error
 
 	if target != "" {
@@ -851,11 +1772,31 @@ func runMessageList(target string) error {
 		if err != nil {
 			return fmt.Errorf("failed to get messages for target: %w", err)
 		}
+		inFlight, err = database.GetInFlightMessages(database.GetDB(), window.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get in-flight messages for target: %w", err)
+		}
 	} else {
 		messages, err = database.GetPendingMessages(database.GetDB(), 0)
 		if err != nil {
 			return fmt.Errorf("failed to get messages: %w", err)
 		}
+		inFlight, err = database.GetInFlightMessages(database.GetDB(), 0)
+		if err != nil {
+			return fmt.Errorf("failed to get in-flight messages: %w", err)
+		}
+	}
+
+	if len(inFlight) > 0 {
+		fmt.Printf("Sending Now (%d messages):\n", len(inFlight))
+		for _, msg := range inFlight {
+			fmt.Printf("  [%d] Target: %s, Priority: %d\n", msg.ID, msg.Window.Target, msg.Priority)
+			fmt.Printf("      Content: %s\n", truncateString(msg.Content, 80))
+			if msg.LeaseExpiresAt != nil {
+				fmt.Printf("      Lease: %s until %s\n", msg.LeaseOwner, msg.LeaseExpiresAt.Format(time.RFC3339))
+			}
+		}
+		fmt.Println()
 	}
 
 	if len(messages) == 0 {
@@ -887,9 +1828,16 @@ func runMessageList(target string) error {
 	for sessionName, sessionMsgs := range sessionMessages {
 		fmt.Printf("Session: %s (%d messages)\n", sessionName, len(sessionMsgs))
 		for _, msg := range sessionMsgs {
-			fmt.Printf("  [%d] Target: %s, Priority: %d\n", msg.ID, msg.Window.Target, msg.Priority)
+			if msg.Route != "" {
+				fmt.Printf("  [%d] Route: %s (currently %s), Priority: %d\n", msg.ID, msg.Route, msg.Window.Target, msg.Priority)
+			} else {
+				fmt.Printf("  [%d] Target: %s, Priority: %d\n", msg.ID, msg.Window.Target, msg.Priority)
+			}
 			fmt.Printf("      Content: %s\n", truncateString(msg.Content, 80))
 			fmt.Printf("      Scheduled: %s\n", msg.ScheduledTime.Format(time.RFC3339))
+			if msg.ExpiresAt != nil {
+				fmt.Printf("      Expires: %s\n", msg.ExpiresAt.Format(time.RFC3339))
+			}
 			if msg.Retries > 0 {
 				fmt.Printf("      Retries: %d\n", msg.Retries)
 			}
@@ -924,6 +1872,9 @@ func runMessageEdit(messageIDStr, content, target string, priority int, when str
 	if message.Status == database.MessageStatusSent {
 		return fmt.Errorf("cannot edit message %d: already sent", messageID)
 	}
+	if message.Status == database.MessageStatusSending {
+		return fmt.Errorf("cannot edit message %d: it is being sent", messageID)
+	}
 
 	// Prepare updates
 	updates := make(map[string]interface{})
@@ -940,20 +1891,28 @@ func runMessageEdit(messageIDStr, content, target string, priority int, when str
 	}
 
 	if when != "" {
-		scheduledTime, err := parseScheduleTime(when)
+		scheduledTime, err := utils.ParseScheduleTime(when, time.Now())
 		if err != nil {
 			return fmt.Errorf("invalid schedule time: %w", err)
 		}
 		updates["scheduled_time"] = scheduledTime
 	}
 
-	if target != "" {
+	if scheduler.IsRoute(target) {
+		// The window is picked again when the message is sent
+		route, err := scheduler.ParseRoute(target)
+		if err != nil {
+			return fmt.Errorf("invalid route: %w", err)
+		}
+		updates["route"] = route.String()
+	} else if target != "" {
 		// Get new target window
 		window, err := database.GetTmuxWindow(database.GetDB(), target)
 		if err != nil {
 			return fmt.Errorf("target '%s' not found: %w", target, err)
 		}
 		updates["window_id"] = window.ID
+		updates["route"] = ""
 	}
 
 	if len(updates) == 0 {
@@ -961,11 +1920,22 @@ func runMessageEdit(messageIDStr, content, target string, priority int, when str
 	}
 
 	// Apply updates
+	windowIDs := []uint{message.WindowID}
+	if windowID, ok := updates["window_id"].(uint); ok && windowID != message.WindowID {
+		windowIDs = append(windowIDs, windowID)
+	}
 	err = database.GetDB().Model(&message).Updates(updates).Error
 	if err != nil {
 		return fmt.Errorf("failed to update message: %w", err)
 	}
 
+	// Keep the queue counts of both windows accurate
+	for _, windowID := range windowIDs {
+		if err := database.SyncQueueMessageCount(database.GetDB(), windowID); err != nil {
+			return fmt.Errorf("failed to update queue count: %w", err)
+		}
+	}
+
 	fmt.Printf("Updated message %d\n", messageID)
 	return nil
 }
@@ -994,83 +1964,168 @@ func runMessageDelete(messageIDStr string) error {
 	if message.Status == database.MessageStatusSent {
 		return fmt.Errorf("cannot delete message %d: already sent", messageID)
 	}
+	if message.Status == database.MessageStatusSending {
+		return fmt.Errorf("cannot delete message %d: it is being sent", messageID)
+	}
 
 	// Delete message
 	err = database.GetDB().Delete(&message).Error
 	if err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
+	if err := database.SyncQueueMessageCount(database.GetDB(), message.WindowID); err != nil {
+		return fmt.Errorf("failed to update queue count: %w", err)
+	}
 
 	fmt.Printf("Deleted message %d\n", messageID)
 	return nil
 }
 
-// Helper functions (using optimized detection from utils package)
+func runMessageFailed(target string) error {
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	var windowID uint
+	if target != "" {
+		window, err := database.GetTmuxWindow(database.GetDB(), target)
+		if err != nil {
+			return fmt.Errorf("target '%s' not found: %w", target, err)
+		}
+		windowID = window.ID
+	}
 
-// parseScheduleTime parses time input with comprehensive validation
-// Supports: "now", "+duration", "HH:MM", "YYYY-MM-DD HH:MM"
-func parseScheduleTime(when string) (time.Time, error) {
-	if when == "" {
-		return time.Time{}, fmt.Errorf("time cannot be empty")
+	messages, err := database.GetFailedMessages(database.GetDB(), windowID)
+	if err != nil {
+		return fmt.Errorf("failed to get failed messages: %w", err)
 	}
 
-	switch when {
-	case "now":
-		return time.Now(), nil
-	default:
-		// Handle relative time (+duration)
-		if len(when) > 1 && when[0] == '+' {
-			duration, err := time.ParseDuration(when[1:])
-			if err != nil {
-				return time.Time{}, fmt.Errorf("invalid duration format '%s': %w (use formats like +1h, +30m, +5s)", when, err)
-			}
+	if len(messages) == 0 {
+		if target != "" {
+			fmt.Printf("No failed messages for target '%s'\n", target)
+		} else {
+			fmt.Println("No failed messages.")
+		}
+		return nil
+	}
+
+	fmt.Printf("Failed Messages (%d):\n\n", len(messages))
+	for _, msg := range messages {
+		if msg.Route != "" {
+			fmt.Printf("  [%d] Route: %s (last %s), Priority: %d\n", msg.ID, msg.Route, msg.Window.Target, msg.Priority)
+		} else {
+			fmt.Printf("  [%d] Target: %s, Priority: %d\n", msg.ID, msg.Window.Target, msg.Priority)
+		}
+		fmt.Printf("      Content: %s\n", truncateString(msg.Content, 80))
+		fmt.Printf("      Failed: %s after %d attempts\n", msg.UpdatedAt.Format(time.RFC3339), msg.Retries)
+		if msg.Error != "" {
+			fmt.Printf("      Error: %s\n", msg.Error)
+		}
 
-			// Validate reasonable duration limits
-			if duration < 0 {
-				return time.Time{}, fmt.Errorf("duration cannot be negative: %s", when)
+		attempts, err := database.GetMessageAttempts(database.GetDB(), msg.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get attempts for message %d: %w", msg.ID, err)
+		}
+		if len(attempts) > 0 {
+			fmt.Println("      Attempts:")
+		}
+		for _, attempt := range attempts {
+			line := fmt.Sprintf("        #%d %s  %s", attempt.Attempt, attempt.CreatedAt.Format("2006-01-02 15:04:05"), attempt.Outcome)
+			if attempt.ErrorClass != "" {
+				line += "  " + attempt.ErrorClass
 			}
-			if duration > 30*24*time.Hour { // 30 days
-				return time.Time{}, fmt.Errorf("duration too large (max 30 days): %s", when)
+			if attempt.Target != "" {
+				line += "  " + attempt.Target
 			}
-
-			return time.Now().Add(duration), nil
+			if attempt.Error != "" {
+				line += ": " + truncateString(attempt.Error, 80)
+			}
+			fmt.Println(line)
 		}
+		fmt.Println()
+	}
 
-		// Try parsing as time in different formats
-		now := time.Now()
+	fmt.Println("Use 'tcs message requeue <id>' or 'tcs message requeue --all' to send them again.")
+	return nil
+}
 
-		// Try HH:MM format first (most common)
-		if t, err := time.Parse("15:04", when); err == nil {
-			// Combine with today's date
-			scheduledTime := time.Date(now.Year(), now.Month(), now.Day(),
-				t.Hour(), t.Minute(), 0, 0, now.Location())
+func runMessageRequeue(messageIDStrs []string, all bool, target string) error {
+	if all == (len(messageIDStrs) > 0) {
+		return fmt.Errorf("give message IDs or --all")
+	}
 
-			// If time is in the past, schedule for tomorrow
-			if scheduledTime.Before(now) {
-				scheduledTime = scheduledTime.AddDate(0, 0, 1)
-			}
-			return scheduledTime, nil
+	ids := make([]uint, 0, len(messageIDStrs))
+	for _, messageIDStr := range messageIDStrs {
+		messageID, err := strconv.ParseUint(messageIDStr, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid message ID: %s", messageIDStr)
 		}
+		ids = append(ids, uint(messageID))
+	}
 
-		// Try full datetime format: YYYY-MM-DD HH:MM
-		if t, err := time.Parse("2006-01-02 15:04", when); err == nil {
-			if t.Before(now) {
-				return time.Time{}, fmt.Errorf("scheduled time cannot be in the past: %s", when)
-			}
-			return t, nil
+	// Initialize database
+	if err := database.Initialize(nil); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	var windowID uint
+	var route string
+	if scheduler.IsRoute(target) {
+		// The window is picked again when the message is sent
+		parsed, err := scheduler.ParseRoute(target)
+		if err != nil {
+			return fmt.Errorf("invalid route: %w", err)
+		}
+		route = parsed.String()
+	} else if target != "" {
+		window, err := database.GetTmuxWindow(database.GetDB(), target)
+		if err != nil {
+			return fmt.Errorf("target '%s' not found: %w", target, err)
 		}
+		windowID = window.ID
+	}
 
-		// Try date only format: YYYY-MM-DD (schedule for 9 AM)
-		if t, err := time.Parse("2006-01-02", when); err == nil {
-			scheduledTime := time.Date(t.Year(), t.Month(), t.Day(), 9, 0, 0, 0, now.Location())
-			if scheduledTime.Before(now) {
-				return time.Time{}, fmt.Errorf("scheduled date cannot be in the past: %s", when)
-			}
-			return scheduledTime, nil
+	requeued, err := database.RequeueFailedMessages(database.GetDB(), ids, windowID, route)
+	if err != nil {
+		return fmt.Errorf("failed to requeue messages: %w", err)
+	}
+
+	if requeued == 0 {
+		fmt.Println("No failed messages to requeue.")
+		return nil
+	}
+	if len(ids) > 0 && int(requeued) < len(ids) {
+		fmt.Printf("Requeued %d of %d messages; the others have not failed\n", requeued, len(ids))
+		return nil
+	}
+	fmt.Printf("Requeued %d messages\n", requeued)
+	return nil
+}
+
+// Helper functions (using optimized detection from utils package)
+
+// parseExpiry parses a message deadline: a duration after the scheduled time
+// ("30m", "+2h") or any time accepted by utils.ParseScheduleTime
+func parseExpiry(expires string, scheduledTime time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(strings.TrimPrefix(expires, "+")); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("expiry duration must be positive: %s", expires)
 		}
+		return scheduledTime.Add(duration), nil
+	}
 
-		return time.Time{}, fmt.Errorf("invalid time format '%s'. Supported formats: 'now', '+duration', 'HH:MM', 'YYYY-MM-DD HH:MM', 'YYYY-MM-DD'", when)
+	deadline, err := utils.ParseScheduleTime(expires, time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !deadline.After(scheduledTime) {
+		return time.Time{}, fmt.Errorf("expiry %s is not after the scheduled time %s",
+			deadline.Format(time.RFC3339), scheduledTime.Format(time.RFC3339))
 	}
+	return deadline, nil
 }
 
 func truncateString(s string, maxLen int) string {
@@ -1080,8 +2135,15 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-func runTUI() error {
-	return tui.Run()
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func runTUI(attach bool) error {
+	return tui.Run(attach)
 }
 
 func runDaemon() error {
@@ -1094,6 +2156,13 @@ func runDaemon() error {
 	}
 	defer database.Close()
 
+	// A second daemon would compete for the same queue
+	if running, err := daemon.Find(database.GetDB()); err != nil {
+		return err
+	} else if running != nil {
+		return fmt.Errorf("a daemon is already running as %s", running.Owner)
+	}
+
 	// Initialize components
 	tmuxClient := tmux.NewClient()
 	usageMonitor := monitor.NewUsageMonitor(database.GetDB())
@@ -1127,6 +2196,15 @@ func runDaemon() error {
 		return fmt.Errorf("failed to initialize scheduler: %w", err)
 	}
 
+	// Resume held messages as soon as Claude is back in a window
+	windowDiscovery.OnClaudeRestored(func(window *database.TmuxWindow) {
+		schedulerInstance.ResumeWindow(window.ID)
+	})
+
+	// Record scheduler, discovery and usage events for 'tcs events'
+	events.NewRecorder(database.GetDB()).Attach(schedulerInstance, windowDiscovery, usageMonitor)
+	usageMonitor.StartMonitoring(time.Minute)
+
 	// Start the scheduler
 	fmt.Println("Starting scheduler...")
 	if err := schedulerInstance.Start(); err != nil {
@@ -1139,10 +2217,27 @@ func runDaemon() error {
 		}
 	}()
 
+	// Announce the daemon and run what attached TUIs ask for
+	server := daemon.NewServer(database.GetDB())
+	server.Handle(daemon.RequestProcess, func() error {
+		schedulerInstance.TriggerImmediateProcessing()
+		return nil
+	})
+	server.Handle(daemon.RequestRescan, windowDiscovery.ForceRescan)
+	if err := server.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon server: %w", err)
+	}
+	defer func() {
+		if err := server.Stop(); err != nil {
+			fmt.Printf("Warning: failed to stop daemon server: %v\n", err)
+		}
+	}()
+
 	fmt.Println("✅ TCS Daemon is running")
 	fmt.Println("  - Window Discovery: Scanning tmux windows every 30s")
 	fmt.Println("  - Scheduler: Processing message queue")
 	fmt.Println("  - Usage Monitor: Tracking Claude usage")
+	fmt.Println("  - TUI: Follow with 'tcs tui --attach'")
 	fmt.Println("Press Ctrl+C to stop")
 
 	// Set up signal handling for graceful shutdown
@@ -1214,11 +2309,12 @@ func runInit() error {
 	for _, session := range sessions {
 		for _, window := range session.Windows {
 			// Detect Claude
-			hasClaude := false
-			content, err := tmuxClient.CapturePane(window.Target, 50)
-			if err == nil {
-				hasClaude = utils.IsClaudeWindow(content)
-			}
+			hasClaude := tmuxClient.DetectClaude(
+				window.Target,
+				tmux.DetectionMethodText,
+				nil,
+				utils.ConfiguredRuleSet(),
+			)
 
 			// Create or update window
 			_, err = database.CreateOrUpdateTmuxWindow(
@@ -696,3 +696,53 @@ func (r *ClaudeDataReader) calculateP90CostLimit(sessions []SessionSummary) floa
 	p90Value := costs[p90Index]
 	return p90Value * 1.2
 }
+
+// UsageHistory holds token usage over time for the dashboard charts
+type UsageHistory struct {
+	WindowStart  time.Time
+	WindowEnd    time.Time
+	HourlyTokens []int            // tokens in each hour of the current session
+	Sessions     []SessionSummary // sessions of the history period, oldest first
+}
+
+// GetUsageHistory returns the tokens per hour of the current session and the
+// sessions that ended in the last days
+func (r *ClaudeDataReader) GetUsageHistory(days int) (*UsageHistory, error) {
+	entries, err := r.ReadUsageEntries(max(days*24, 72))
+	if err != nil {
+		return nil, err
+	}
+
+	windowStart, windowEnd := r.findCurrentSession(entries)
+	history := &UsageHistory{
+		WindowStart:  windowStart,
+		WindowEnd:    windowEnd,
+		HourlyTokens: HourlyTokens(entries, windowStart, windowEnd),
+	}
+
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	for _, session := range r.groupEntriesIntoSessions(entries) {
+		if session.EndTime.After(cutoff) {
+			history.Sessions = append(history.Sessions, session)
+		}
+	}
+	return history, nil
+}
+
+// HourlyTokens sums the tokens of the entries in each hour from start to end
+func HourlyTokens(entries []UsageEntry, start, end time.Time) []int {
+	hours := int(end.Sub(start).Hours())
+	if hours <= 0 {
+		return nil
+	}
+
+	tokens := make([]int, hours)
+	for _, entry := range entries {
+		if entry.Timestamp.Before(start) || !entry.Timestamp.Before(end) {
+			continue
+		}
+		hour := min(int(entry.Timestamp.Sub(start).Hours()), hours-1)
+		tokens[hour] += entry.InputTokens + entry.OutputTokens
+	}
+	return tokens
+}
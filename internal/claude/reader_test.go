@@ -240,3 +240,25 @@ func TestClaudeDataReader_DirectoryTraversal(t *testing.T) {
 
 	t.Log("✓ Directory traversal working correctly")
 }
+
+func TestHourlyTokens(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	entries := []UsageEntry{
+		{Timestamp: start.Add(-time.Minute), InputTokens: 1000},
+		{Timestamp: start.Add(10 * time.Minute), InputTokens: 100, OutputTokens: 200},
+		{Timestamp: start.Add(50 * time.Minute), InputTokens: 50},
+		{Timestamp: start.Add(4*time.Hour + 59*time.Minute), OutputTokens: 10},
+		{Timestamp: start.Add(5 * time.Hour), InputTokens: 1000},
+	}
+
+	tokens := HourlyTokens(entries, start, start.Add(5*time.Hour))
+	expected := []int{350, 0, 0, 0, 10}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d hours, got %d", len(expected), len(tokens))
+	}
+	for i := range expected {
+		if tokens[i] != expected[i] {
+			t.Errorf("Hour %d: expected %d tokens, got %d", i, expected[i], tokens[i])
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration
@@ -44,19 +49,100 @@ type DatabaseConfig struct {
 
 // TUIConfig holds TUI configuration
 type TUIConfig struct {
-	RefreshRate   time.Duration `mapstructure:"refresh_rate" json:"refresh_rate"`
-	Theme         string        `mapstructure:"theme" json:"theme"`
-	ShowDebugInfo bool          `mapstructure:"show_debug_info" json:"show_debug_info"`
+	RefreshRate   time.Duration     `mapstructure:"refresh_rate" json:"refresh_rate"`
+	Theme         string            `mapstructure:"theme" json:"theme"`
+	ShowDebugInfo bool              `mapstructure:"show_debug_info" json:"show_debug_info"`
+	Templates     []MessageTemplate `mapstructure:"templates" json:"templates"` // snippets offered by the message composer
+
+	// Key bindings by view and action, replacing the defaults; see the help view (?) for the names
+	Keys map[string]map[string][]string `mapstructure:"keys" json:"keys,omitempty"`
+
+	Layout TUILayout `mapstructure:"layout" json:"layout"`
+}
+
+// TUILayout holds the proportions of resizable panels, saved when they are dragged
+type TUILayout struct {
+	WindowsSplit float64 `mapstructure:"windows_split" json:"windows_split"` // share of the windows view's table rows for the windows table
+}
+
+// DefaultWindowsSplit gives the windows table a little more room than the queue table
+const DefaultWindowsSplit = 0.55
+
+// MessageTemplate is a snippet that can be inserted into a message in the composer
+type MessageTemplate struct {
+	Name    string `mapstructure:"name" json:"name"`
+	Content string `mapstructure:"content" json:"content"`
+}
+
+// DefaultMessageTemplates returns the templates offered when none are configured
+func DefaultMessageTemplates() []MessageTemplate {
+	return []MessageTemplate{
+		{Name: "continue", Content: "Please continue with the next step."},
+		{Name: "status", Content: "Summarize what you have done so far and what is left to do."},
+		{Name: "tests", Content: "Run the tests and fix any failures."},
+		{Name: "commit", Content: "Review your changes and commit them with a descriptive message."},
+	}
 }
 
 // SchedulerConfig holds scheduler configuration
 type SchedulerConfig struct {
-	SmartEnabled          bool          `mapstructure:"smart_enabled" json:"smart_enabled"`
+	Policy                string        `mapstructure:"policy" json:"policy"`                 // fair-share, priority, deadline or paced
+	PacedInterval         time.Duration `mapstructure:"paced_interval" json:"paced_interval"` // minimum time between messages for the paced policy
 	CronEnabled           bool          `mapstructure:"cron_enabled" json:"cron_enabled"`
 	ProcessingInterval    time.Duration `mapstructure:"processing_interval" json:"processing_interval"`
 	MaxConcurrentMessages int           `mapstructure:"max_concurrent_messages" json:"max_concurrent_messages"`
-	RetryAttempts         int           `mapstructure:"retry_attempts" json:"retry_attempts"`
-	RetryDelay            time.Duration `mapstructure:"retry_delay" json:"retry_delay"`
+	RetryDelay            time.Duration `mapstructure:"retry_delay" json:"retry_delay"` // how long routed messages wait when no window matches
+	Retry                 RetryConfig   `mapstructure:"retry" json:"retry"`
+	QuietHours            []QuietHours  `mapstructure:"quiet_hours" json:"quiet_hours"` // times when nothing is sent
+}
+
+// QuietHours is a recurring period in which no messages are sent, e.g.
+// 22:00-07:00 every night. A period that ends before it starts runs past
+// midnight and belongs to the day it starts on.
+type QuietHours struct {
+	Name     string   `mapstructure:"name" json:"name,omitempty"`
+	Start    string   `mapstructure:"start" json:"start"`                 // HH:MM
+	End      string   `mapstructure:"end" json:"end"`                     // HH:MM
+	Days     []string `mapstructure:"days" json:"days,omitempty"`         // mon-sun; empty means every day
+	Timezone string   `mapstructure:"timezone" json:"timezone,omitempty"` // IANA name; empty means local time
+}
+
+// RetryConfig holds the retry policy for each class of send error
+type RetryConfig struct {
+	TargetNotFound RetryPolicyConfig `mapstructure:"target_not_found" json:"target_not_found"` // the window is gone
+	TmuxDown       RetryPolicyConfig `mapstructure:"tmux_down" json:"tmux_down"`               // no tmux server
+	PaneBusy       RetryPolicyConfig `mapstructure:"pane_busy" json:"pane_busy"`               // Claude is still working
+	Other          RetryPolicyConfig `mapstructure:"other" json:"other"`                       // any other error
+}
+
+// RetryPolicyConfig describes how a class of failed sends is retried
+type RetryPolicyConfig struct {
+	Backoff     time.Duration `mapstructure:"backoff" json:"backoff"`           // Delay before the first retry
+	MaxBackoff  time.Duration `mapstructure:"max_backoff" json:"max_backoff"`   // Upper bound for the delay
+	Multiplier  float64       `mapstructure:"multiplier" json:"multiplier"`     // Growth of the delay per attempt
+	Jitter      float64       `mapstructure:"jitter" json:"jitter"`             // Random spread of the delay, 0-1
+	MaxAttempts int           `mapstructure:"max_attempts" json:"max_attempts"` // Attempts before giving up, including the first
+	DeadLetter  bool          `mapstructure:"dead_letter" json:"dead_letter"`   // Keep given-up messages as failed; false deletes them
+}
+
+// Classes returns the retry policies by error class name
+func (r RetryConfig) Classes() map[string]RetryPolicyConfig {
+	return map[string]RetryPolicyConfig{
+		"target_not_found": r.TargetNotFound,
+		"tmux_down":        r.TmuxDown,
+		"pane_busy":        r.PaneBusy,
+		"other":            r.Other,
+	}
+}
+
+// DefaultRetryConfig returns the default retry policies
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		TargetNotFound: RetryPolicyConfig{Backoff: time.Minute, MaxBackoff: 10 * time.Minute, Multiplier: 2, Jitter: 0.2, MaxAttempts: 3, DeadLetter: true},
+		TmuxDown:       RetryPolicyConfig{Backoff: 30 * time.Second, MaxBackoff: 15 * time.Minute, Multiplier: 2, Jitter: 0.2, MaxAttempts: 10, DeadLetter: true},
+		PaneBusy:       RetryPolicyConfig{Backoff: 15 * time.Second, MaxBackoff: 2 * time.Minute, Multiplier: 1.5, Jitter: 0.3, MaxAttempts: 20, DeadLetter: true},
+		Other:          RetryPolicyConfig{Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute, Multiplier: 2, Jitter: 0.2, MaxAttempts: 4, DeadLetter: true},
+	}
 }
 
 // UsageConfig holds usage monitoring configuration
@@ -82,6 +168,29 @@ type TmuxConfig struct {
 	MessageDelay          time.Duration `mapstructure:"message_delay" json:"message_delay"`
 	ClaudeDetectionMethod string        `mapstructure:"claude_detection_method" json:"claude_detection_method"` // "process", "text", or "both"
 	ClaudeProcessNames    []string      `mapstructure:"claude_process_names" json:"claude_process_names"`       // Process names to look for
+
+	// Rule-based Claude detection (empty rules use the built-in set)
+	DetectionRules     []DetectionRuleConfig `mapstructure:"detection_rules" json:"detection_rules"`
+	DetectionThreshold float64               `mapstructure:"detection_threshold" json:"detection_threshold"` // Score needed to classify a window as Claude
+
+	// Supervision of windows whose Claude session exits
+	SupervisionPolicy  string        `mapstructure:"supervision_policy" json:"supervision_policy"`     // Default policy: "none", "notify" or "restart"
+	RestartCommand     string        `mapstructure:"restart_command" json:"restart_command"`           // Command used to restart Claude
+	RestartBackoff     time.Duration `mapstructure:"restart_backoff" json:"restart_backoff"`           // Delay before the first restart attempt
+	RestartMaxBackoff  time.Duration `mapstructure:"restart_max_backoff" json:"restart_max_backoff"`   // Upper bound for the restart delay
+	RestartMaxAttempts int           `mapstructure:"restart_max_attempts" json:"restart_max_attempts"` // Give up after this many failed restarts
+
+	// Scheduled messages wait this long for someone typing in or scrolling a window (0 disables the check)
+	HumanBusyGrace time.Duration `mapstructure:"human_busy_grace" json:"human_busy_grace"`
+}
+
+// DetectionRuleConfig describes a weighted Claude detection rule.
+// A rule fires when all of its patterns match; negative weights count against Claude.
+type DetectionRuleConfig struct {
+	Name        string  `mapstructure:"name" json:"name"`
+	Pattern     string  `mapstructure:"pattern" json:"pattern,omitempty"`           // Regex matched against pane content
+	PaneCommand string  `mapstructure:"pane_command" json:"pane_command,omitempty"` // Regex matched against #{pane_current_command}
+	Weight      float64 `mapstructure:"weight" json:"weight"`
 }
 
 // ClaudeConfig holds Claude data processing configuration
@@ -96,6 +205,9 @@ type ClaudeConfig struct {
 // global configuration instance
 var appConfig *Config
 
+// configFile is the file the configuration was loaded from, empty if there was none
+var configFile string
+
 // Load loads the configuration from file and environment variables
 func Load(configPath string) (*Config, error) {
 	// Set up viper
@@ -143,6 +255,7 @@ func Load(configPath string) (*Config, error) {
 
 	// Set global config
 	appConfig = config
+	configFile = v.ConfigFileUsed()
 
 	return config, nil
 }
@@ -160,14 +273,25 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("tui.refresh_rate", 5*time.Second)
 	v.SetDefault("tui.theme", "default")
 	v.SetDefault("tui.show_debug_info", false)
+	v.SetDefault("tui.layout.windows_split", DefaultWindowsSplit)
 
 	// Scheduler defaults
-	v.SetDefault("scheduler.smart_enabled", true)
+	v.SetDefault("scheduler.policy", "fair-share")
+	v.SetDefault("scheduler.paced_interval", time.Minute)
 	v.SetDefault("scheduler.cron_enabled", true)
 	v.SetDefault("scheduler.processing_interval", 10*time.Second)
 	v.SetDefault("scheduler.max_concurrent_messages", 3)
-	v.SetDefault("scheduler.retry_attempts", 3)
 	v.SetDefault("scheduler.retry_delay", 30*time.Second)
+	retry := DefaultRetryConfig()
+	for class, policy := range retry.Classes() {
+		prefix := "scheduler.retry." + class + "."
+		v.SetDefault(prefix+"backoff", policy.Backoff)
+		v.SetDefault(prefix+"max_backoff", policy.MaxBackoff)
+		v.SetDefault(prefix+"multiplier", policy.Multiplier)
+		v.SetDefault(prefix+"jitter", policy.Jitter)
+		v.SetDefault(prefix+"max_attempts", policy.MaxAttempts)
+		v.SetDefault(prefix+"dead_letter", policy.DeadLetter)
+	}
 
 	// Usage defaults
 	v.SetDefault("usage.max_messages", 1000)
@@ -187,6 +311,13 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("tmux.message_delay", 500*time.Millisecond)
 	v.SetDefault("tmux.claude_detection_method", "both") // "process", "text", or "both"
 	v.SetDefault("tmux.claude_process_names", []string{"claude-code", "claude_code", "claude"})
+	v.SetDefault("tmux.detection_threshold", 1.0)
+	v.SetDefault("tmux.supervision_policy", "none") // "none", "notify", or "restart"
+	v.SetDefault("tmux.restart_command", "claude --continue")
+	v.SetDefault("tmux.restart_backoff", 10*time.Second)
+	v.SetDefault("tmux.restart_max_backoff", 5*time.Minute)
+	v.SetDefault("tmux.restart_max_attempts", 5)
+	v.SetDefault("tmux.human_busy_grace", time.Minute)
 
 	// Claude data processing defaults
 	v.SetDefault("claude.data_directory", "")             // Empty means use default ~/.claude
@@ -217,6 +348,44 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("processing interval must be at least 1 second")
 	}
 
+	switch config.Scheduler.Policy {
+	case "", "priority", "fair-share", "deadline":
+	case "paced":
+		if config.Scheduler.PacedInterval <= 0 {
+			return fmt.Errorf("paced policy needs a positive paced_interval")
+		}
+	default:
+		return fmt.Errorf("invalid scheduler policy: %s (expected priority, fair-share, deadline or paced)", config.Scheduler.Policy)
+	}
+
+	for class, policy := range config.Scheduler.Retry.Classes() {
+		if policy.MaxAttempts < 1 {
+			return fmt.Errorf("retry policy %s needs max_attempts of at least 1", class)
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return fmt.Errorf("retry policy %s needs a jitter between 0 and 1", class)
+		}
+		if policy.Backoff < 0 || policy.MaxBackoff < 0 {
+			return fmt.Errorf("retry policy %s cannot have a negative backoff", class)
+		}
+	}
+
+	for i, quiet := range config.Scheduler.QuietHours {
+		if err := validateQuietHours(quiet); err != nil {
+			return fmt.Errorf("quiet hours %d (%s): %w", i+1, quiet.Name, err)
+		}
+	}
+
+	if split := config.TUI.Layout.WindowsSplit; split <= 0 || split >= 1 {
+		return fmt.Errorf("tui layout windows_split must be between 0 and 1")
+	}
+
+	for i, template := range config.TUI.Templates {
+		if template.Name == "" || template.Content == "" {
+			return fmt.Errorf("template %d needs a name and content", i+1)
+		}
+	}
+
 	// Validate usage limits
 	if config.Usage.MaxMessages < 1 {
 		return fmt.Errorf("max messages must be at least 1")
@@ -228,6 +397,29 @@ func validateConfig(config *Config) error {
 
 	// NOTE: Claude reset hour validation removed - now using dynamic 5-hour windows
 
+	// Validate detection rules
+	for i, rule := range config.Tmux.DetectionRules {
+		if rule.Pattern == "" && rule.PaneCommand == "" {
+			return fmt.Errorf("detection rule %d (%s) needs a pattern or pane_command", i+1, rule.Name)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("detection rule %d (%s) has an invalid pattern: %w", i+1, rule.Name, err)
+		}
+		if _, err := regexp.Compile(rule.PaneCommand); err != nil {
+			return fmt.Errorf("detection rule %d (%s) has an invalid pane_command: %w", i+1, rule.Name, err)
+		}
+	}
+
+	// Validate supervision policy
+	switch config.Tmux.SupervisionPolicy {
+	case "", "none", "notify", "restart":
+	default:
+		return fmt.Errorf("invalid supervision policy: %s (expected none, notify or restart)", config.Tmux.SupervisionPolicy)
+	}
+	if config.Tmux.HumanBusyGrace < 0 {
+		return fmt.Errorf("tmux human_busy_grace must not be negative")
+	}
+
 	// Validate logging level
 	validLevels := []string{"debug", "info", "warn", "error", "fatal"}
 	levelValid := false
@@ -281,21 +473,126 @@ func GetTUIConfig() TUIConfig {
 			RefreshRate:   5 * time.Second,
 			Theme:         "default",
 			ShowDebugInfo: false,
+			Layout:        TUILayout{WindowsSplit: DefaultWindowsSplit},
 		}
 	}
 	return appConfig.TUI
 }
 
+// SaveTUILayout saves the panel proportions to the tui.layout section of the
+// config file, leaving the rest of the file and its comments as they are
+func SaveTUILayout(layout TUILayout) error {
+	path := configFile
+	if path == "" {
+		path = getDefaultConfigPath()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a mapping", path)
+	}
+
+	layoutNode := yamlMapping(yamlMapping(root, "tui"), "layout")
+	yamlSetScalar(layoutNode, "windows_split", strconv.FormatFloat(layout.WindowsSplit, 'f', 2, 64))
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if appConfig != nil {
+		appConfig.TUI.Layout = layout
+	}
+	return nil
+}
+
+// yamlMapping returns the mapping under key in a YAML mapping node, adding it
+// or replacing a value that isn't a mapping
+func yamlMapping(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			if value.Kind != yaml.MappingNode {
+				*value = yaml.Node{Kind: yaml.MappingNode}
+			}
+			return value
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+// yamlSetScalar sets key in a YAML mapping node to a scalar value
+func yamlSetScalar(node *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1].Kind = yaml.ScalarNode
+			node.Content[i+1].Tag = ""
+			node.Content[i+1].Value = value
+			node.Content[i+1].Content = nil
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value})
+}
+
+// validateQuietHours checks the times, days and timezone of a quiet period
+func validateQuietHours(quiet QuietHours) error {
+	for _, clock := range []string{quiet.Start, quiet.End} {
+		if _, err := time.Parse("15:04", clock); err != nil {
+			return fmt.Errorf("invalid time '%s' (expected HH:MM)", clock)
+		}
+	}
+	if quiet.Start == quiet.End {
+		return fmt.Errorf("start and end are the same")
+	}
+	for _, day := range quiet.Days {
+		switch strings.ToLower(day) {
+		case "mon", "tue", "wed", "thu", "fri", "sat", "sun":
+		default:
+			return fmt.Errorf("invalid day '%s' (expected mon-sun)", day)
+		}
+	}
+	if _, err := time.LoadLocation(quiet.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone '%s': %w", quiet.Timezone, err)
+	}
+	return nil
+}
+
 // GetSchedulerConfig returns the scheduler configuration
 func GetSchedulerConfig() SchedulerConfig {
 	if appConfig == nil {
 		return SchedulerConfig{
-			SmartEnabled:          true,
+			Policy:                "fair-share",
+			PacedInterval:         time.Minute,
 			CronEnabled:           true,
 			ProcessingInterval:    10 * time.Second,
 			MaxConcurrentMessages: 3,
-			RetryAttempts:         3,
 			RetryDelay:            30 * time.Second,
+			Retry:                 DefaultRetryConfig(),
 		}
 	}
 	return appConfig.Scheduler
@@ -331,9 +628,18 @@ func GetLoggingConfig() LoggingConfig {
 func GetTmuxConfig() TmuxConfig {
 	if appConfig == nil {
 		return TmuxConfig{
-			DiscoveryInterval:   30 * time.Second,
-			HealthCheckInterval: 60 * time.Second,
-			MessageDelay:        500 * time.Millisecond,
+			DiscoveryInterval:     30 * time.Second,
+			HealthCheckInterval:   60 * time.Second,
+			MessageDelay:          500 * time.Millisecond,
+			ClaudeDetectionMethod: "both",
+			ClaudeProcessNames:    []string{"claude-code", "claude_code", "claude"},
+			DetectionThreshold:    1.0,
+			SupervisionPolicy:     "none",
+			RestartCommand:        "claude --continue",
+			RestartBackoff:        10 * time.Second,
+			RestartMaxBackoff:     5 * time.Minute,
+			RestartMaxAttempts:    5,
+			HumanBusyGrace:        time.Minute,
 		}
 	}
 	return appConfig.Tmux
@@ -403,14 +709,16 @@ func GenerateDefaultConfig(configPath string) error {
 			RefreshRate:   5 * time.Second,
 			Theme:         "default",
 			ShowDebugInfo: false,
+			Layout:        TUILayout{WindowsSplit: DefaultWindowsSplit},
 		},
 		Scheduler: SchedulerConfig{
-			SmartEnabled:          true,
+			Policy:                "fair-share",
+			PacedInterval:         time.Minute,
 			CronEnabled:           true,
 			ProcessingInterval:    10 * time.Second,
 			MaxConcurrentMessages: 3,
-			RetryAttempts:         3,
 			RetryDelay:            30 * time.Second,
+			Retry:                 DefaultRetryConfig(),
 		},
 		Usage: UsageConfig{
 			MaxMessages:        1000,
@@ -428,6 +736,13 @@ func GenerateDefaultConfig(configPath string) error {
 			DiscoveryInterval:   30 * time.Second,
 			HealthCheckInterval: 60 * time.Second,
 			MessageDelay:        500 * time.Millisecond,
+			DetectionThreshold:  1.0,
+			SupervisionPolicy:   "none",
+			RestartCommand:      "claude --continue",
+			RestartBackoff:      10 * time.Second,
+			RestartMaxBackoff:   5 * time.Minute,
+			RestartMaxAttempts:  5,
+			HumanBusyGrace:      time.Minute,
 		},
 	}
 
@@ -0,0 +1,287 @@
+// Package daemon lets a TUI follow a running 'tcs daemon' instead of starting
+// its own scheduler. Both processes share the database, so the daemon records
+// a heartbeat there and runs the requests that attached TUIs leave for it.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+)
+
+// Requests an attached TUI can leave for the daemon
+const (
+	RequestProcess = "process" // run a processing round now
+	RequestRescan  = "rescan"  // scan the tmux windows now
+)
+
+// HeartbeatInterval is how often the daemon records that it is alive. A
+// daemon that missed three heartbeats is considered gone.
+const HeartbeatInterval = 5 * time.Second
+
+// PollInterval is how often the daemon looks for requests
+const PollInterval = time.Second
+
+// Info describes a running daemon
+type Info struct {
+	Owner    string // host:pid of the daemon process
+	LastSeen time.Time
+}
+
+// Find returns the running daemon, or nil when there is none
+func Find(db *gorm.DB) (*Info, error) {
+	var state database.SchedulerState
+	err := db.Where("name = ?", database.SchedulerDaemon).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the daemon: %w", err)
+	}
+
+	if state.Status != database.SchedulerStatusRunning || state.LastRun == nil ||
+		time.Since(*state.LastRun) > 3*HeartbeatInterval {
+		return nil, nil
+	}
+	return &Info{Owner: state.Owner, LastSeen: *state.LastRun}, nil
+}
+
+// Server runs in the daemon, keeping its heartbeat and handling requests
+type Server struct {
+	db       *gorm.DB
+	owner    string
+	handlers map[string]func() error
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewServer creates a server for this process
+func NewServer(db *gorm.DB) *Server {
+	return &Server{
+		db:       db,
+		owner:    database.LeaseOwnerID(),
+		handlers: make(map[string]func() error),
+	}
+}
+
+// Handle sets the function that runs requests of a kind
+func (s *Server) Handle(kind string, fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = fn
+}
+
+// Start announces the daemon and starts handling requests. It fails when
+// another daemon is already running on the database.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("daemon server is already running")
+	}
+
+	other, err := Find(s.db)
+	if err != nil {
+		return err
+	}
+	if other != nil && other.Owner != s.owner {
+		return fmt.Errorf("a daemon is already running as %s", other.Owner)
+	}
+
+	// Requests left while no daemon was running are stale
+	if err := s.db.Unscoped().Where("1 = 1").Delete(&database.DaemonRequest{}).Error; err != nil {
+		return fmt.Errorf("failed to clear old daemon requests: %w", err)
+	}
+
+	state, err := database.GetSchedulerState(s.db, database.SchedulerDaemon)
+	if err != nil {
+		return fmt.Errorf("failed to record the daemon: %w", err)
+	}
+	now := time.Now()
+	if err := s.db.Model(state).Updates(map[string]interface{}{
+		"status":   database.SchedulerStatusRunning,
+		"owner":    s.owner,
+		"last_run": &now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record the daemon: %w", err)
+	}
+
+	s.running = true
+	s.stopCh = make(chan struct{})
+	s.wg.Add(2)
+	go s.heartbeatLoop()
+	go s.pollLoop()
+	return nil
+}
+
+// Stop stops handling requests and marks the daemon as gone
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return s.db.Model(&database.SchedulerState{}).
+		Where("name = ? AND owner = ?", database.SchedulerDaemon, s.owner).
+		Update("status", database.SchedulerStatusIdle).Error
+}
+
+// Heartbeat records that the daemon is alive
+func (s *Server) Heartbeat() error {
+	now := time.Now()
+	return s.db.Model(&database.SchedulerState{}).
+		Where("name = ? AND owner = ?", database.SchedulerDaemon, s.owner).
+		Updates(map[string]interface{}{
+			"status":   database.SchedulerStatusRunning,
+			"last_run": &now,
+		}).Error
+}
+
+// RunPending runs the requests waiting for the daemon, once per kind. A
+// request is removed after it ran, so clients can wait for it.
+func (s *Server) RunPending() error {
+	var requests []database.DaemonRequest
+	if err := s.db.Order("id").Find(&requests).Error; err != nil {
+		return fmt.Errorf("failed to load daemon requests: %w", err)
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	lastID := requests[len(requests)-1].ID
+
+	var errs []error
+	done := make(map[string]bool)
+	for _, request := range requests {
+		if done[request.Kind] {
+			continue
+		}
+		done[request.Kind] = true
+
+		s.mu.Lock()
+		handler := s.handlers[request.Kind]
+		s.mu.Unlock()
+		if handler == nil {
+			errs = append(errs, fmt.Errorf("unknown daemon request %q", request.Kind))
+		} else if err := handler(); err != nil {
+			errs = append(errs, fmt.Errorf("daemon request %q failed: %w", request.Kind, err))
+		}
+
+		// Requests of the same kind left while this one ran wait for the next round
+		if err := s.db.Unscoped().Where("kind = ? AND id <= ?", request.Kind, lastID).
+			Delete(&database.DaemonRequest{}).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove daemon %q requests: %w", request.Kind, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// heartbeatLoop keeps the heartbeat until stopped. It runs apart from the
+// requests, so a long scan doesn't make the daemon look gone.
+func (s *Server) heartbeatLoop() {
+	defer s.wg.Done()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-heartbeat.C:
+			if err := s.Heartbeat(); err != nil {
+				log.Printf("Warning: failed to record daemon heartbeat: %v", err)
+			}
+		}
+	}
+}
+
+// pollLoop runs the pending requests until stopped
+func (s *Server) pollLoop() {
+	defer s.wg.Done()
+
+	poll := time.NewTicker(PollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-poll.C:
+			if err := s.RunPending(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}
+}
+
+// Client leaves requests for a running daemon
+type Client struct {
+	db   *gorm.DB
+	info Info
+}
+
+// NewClient creates a client for the daemon described by info
+func NewClient(db *gorm.DB, info *Info) *Client {
+	return &Client{db: db, info: *info}
+}
+
+// Owner returns the host:pid of the daemon
+func (c *Client) Owner() string {
+	return c.info.Owner
+}
+
+// Request asks the daemon to run a request of the given kind
+func (c *Client) Request(kind string) error {
+	if err := c.db.Create(&database.DaemonRequest{Kind: kind}).Error; err != nil {
+		return fmt.Errorf("failed to send %s request to the daemon: %w", kind, err)
+	}
+	return nil
+}
+
+// RequestAndWait asks the daemon to run a request of the given kind and waits
+// until it ran, the daemon stopped or the timeout passed
+func (c *Client) RequestAndWait(kind string, timeout time.Duration) error {
+	request := database.DaemonRequest{Kind: kind}
+	if err := c.db.Create(&request).Error; err != nil {
+		return fmt.Errorf("failed to send %s request to the daemon: %w", kind, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var left int64
+		if err := c.db.Model(&database.DaemonRequest{}).Where("id = ?", request.ID).Count(&left).Error; err != nil {
+			return fmt.Errorf("failed to check the %s request: %w", kind, err)
+		}
+		if left == 0 {
+			return nil
+		}
+		if !c.Alive() {
+			return fmt.Errorf("the daemon %s stopped before running the %s request", c.info.Owner, kind)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the daemon %s didn't run the %s request within %s", c.info.Owner, kind, timeout)
+		}
+		time.Sleep(PollInterval / 4)
+	}
+}
+
+// Alive reports whether the daemon is still running
+func (c *Client) Alive() bool {
+	info, err := Find(c.db)
+	return err == nil && info != nil && info.Owner == c.info.Owner
+}
@@ -136,12 +136,16 @@ func runMigrations(db *gorm.DB) error {
 	// Auto-migrate all models (window-based architecture)
 	err := db.AutoMigrate(
 		&Message{},            // window-based messages
+		&MessageAttempt{},     // send attempt history
 		&UsageWindow{},        // 5-hour usage tracking
 		&AppConfig{},          // application configuration
 		&TmuxSession{},        // tmux session discovery
 		&SchedulerState{},     // scheduler state management
 		&TmuxWindow{},         // window-based architecture core
 		&WindowMessageQueue{}, // per-window message queues
+		&WindowGroup{},        // window pools for routed messages
+		&Event{},              // event log
+		&DaemonRequest{},      // requests for the running daemon
 	)
 	if err != nil {
 		return fmt.Errorf("auto-migration failed: %w", err)
@@ -178,6 +182,11 @@ func createCustomIndexes(db *gorm.DB) error {
 func initializeDefaultData(db *gorm.DB) error {
 	// Initialize scheduler states
 	schedulers := []SchedulerState{
+		{
+			Name:    SchedulerGlobal,
+			Enabled: true,
+			Status:  SchedulerStatusIdle,
+		},
 		{
 			Name:    SchedulerSmart,
 			Enabled: true,
@@ -2,6 +2,8 @@ package database
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,11 +19,31 @@ type Message struct {
 	Content       string     `gorm:"type:text;not null" json:"content"`
 	ScheduledTime time.Time  `gorm:"index" json:"scheduled_time"`
 	Priority      int        `gorm:"default:5;index" json:"priority"`       // 1-10, higher = more important
-	Status        string     `gorm:"default:'pending';index" json:"status"` // pending, sent, failed
+	Status        string     `gorm:"default:'pending';index" json:"status"` // pending, sending, sent, failed, expired
 	Error         string     `gorm:"type:text" json:"error"`
 	SentAt        *time.Time `json:"sent_at"`
 	Retries       int        `gorm:"default:0" json:"retries"`
 	MaxRetries    int        `gorm:"default:3" json:"max_retries"`
+	Route         string     `gorm:"index" json:"route"`      // e.g. "any:model=opus,idle", resolved to a window at dispatch
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at"` // deadline; the message expires instead of going out later
+
+	// Lease held by the scheduler sending the message
+	LeaseOwner     string     `json:"lease_owner,omitempty"`         // e.g. "host:1234"
+	LeaseExpiresAt *time.Time `gorm:"index" json:"lease_expires_at"` // reclaimed as pending after this
+}
+
+// MessageAttempt records one attempt to send a message
+type MessageAttempt struct {
+	gorm.Model
+	MessageID   uint          `gorm:"not null;index" json:"message_id"`
+	WindowID    uint          `gorm:"index" json:"window_id"`
+	Target      string        `json:"target"`
+	Attempt     int           `json:"attempt"`              // 1 for the first attempt
+	Outcome     string        `gorm:"index" json:"outcome"` // sent, retry, dead-letter, dropped
+	ErrorClass  string        `gorm:"index" json:"error_class,omitempty"`
+	Error       string        `gorm:"type:text" json:"error,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	NextRetryAt *time.Time    `json:"next_retry_at,omitempty"`
 }
 
 // UsageWindow tracks 5-hour usage windows
@@ -64,6 +86,28 @@ type TmuxWindow struct {
 	Active       bool       `gorm:"default:true" json:"active"`                 // enabled for scheduling
 	LastSeen     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"last_seen"` // last discovery time
 	LastActivity *time.Time `json:"last_activity"`                              // last message activity
+
+	// Supervision settings (empty values fall back to the tmux config defaults)
+	SupervisionPolicy string `json:"supervision_policy"` // none, notify, restart
+	RestartCommand    string `json:"restart_command"`    // e.g. "claude --continue"
+
+	// Claude process details from the last scan (empty when unknown)
+	ClaudePID       int        `gorm:"default:0" json:"claude_pid"`
+	ClaudeStartedAt *time.Time `json:"claude_started_at"`
+	ClaudeArgs      string     `gorm:"type:text" json:"claude_args"` // argv joined with spaces
+
+	// How Claude is running, from argv and the status line (empty when unknown)
+	ClaudeModel          string `json:"claude_model"`           // e.g. "opus" or "Sonnet 4.5"
+	ClaudePermissionMode string `json:"claude_permission_mode"` // default, acceptEdits, plan, bypassPermissions
+	ClaudeVersion        string `json:"claude_version"`         // Claude Code version
+}
+
+// WindowGroup is a named pool of windows that messages can be routed to
+type WindowGroup struct {
+	gorm.Model
+	Name        string       `gorm:"uniqueIndex;not null" json:"name"`
+	Description string       `json:"description"`
+	Windows     []TmuxWindow `gorm:"many2many:window_group_members;" json:"windows"`
 }
 
 // WindowMessageQueue represents a message queue for a specific tmux window
@@ -75,28 +119,79 @@ type WindowMessageQueue struct {
 	Active        bool       `gorm:"default:true" json:"active"`     // queue enabled
 	MessageCount  int        `gorm:"default:0" json:"message_count"` // pending messages
 	LastProcessed *time.Time `json:"last_processed"`                 // last message processed
+
+	// Delivery limits
+	MinSpacing    time.Duration `gorm:"default:0" json:"min_spacing"` // minimum time between messages
+	InFlightSince *time.Time    `json:"in_flight_since"`              // set while a message is being sent
 }
 
 // SchedulerState tracks the state of different schedulers
 type SchedulerState struct {
 	gorm.Model
-	Name       string     `gorm:"uniqueIndex;not null" json:"name"` // "smart", "cron"
+	Name       string     `gorm:"uniqueIndex;not null" json:"name"` // "global", "smart", "cron"
 	Enabled    bool       `gorm:"default:true" json:"enabled"`
+	PausedAt   *time.Time `json:"paused_at"`
+	PausedFor  string     `json:"paused_for,omitempty"` // reason given when pausing
+	ResumeAt   *time.Time `json:"resume_at"`            // end of a timed pause; nil pauses until resumed
 	LastRun    *time.Time `json:"last_run"`
 	NextRun    *time.Time `json:"next_run"`
 	RunCount   int        `gorm:"default:0" json:"run_count"`
 	ErrorCount int        `gorm:"default:0" json:"error_count"`
 	Status     string     `gorm:"default:'idle'" json:"status"` // idle, running, error
+	Owner      string     `json:"owner,omitempty"`              // process running it, for the daemon
+}
+
+// DaemonRequest asks the running daemon for something only its services can
+// do, such as a processing round. TUIs attached to the daemon leave them.
+type DaemonRequest struct {
+	gorm.Model
+	Kind string `gorm:"index;not null" json:"kind"` // process, rescan
+}
+
+// Event records something the schedulers, window discovery or usage monitor
+// did, for the events view and 'tcs events'
+type Event struct {
+	gorm.Model
+	Kind      string `gorm:"index" json:"kind"` // message, window, usage, error
+	Type      string `gorm:"index" json:"type"` // e.g. sent, discovered, threshold
+	Target    string `gorm:"index" json:"target,omitempty"`
+	MessageID uint   `gorm:"index" json:"message_id,omitempty"`
+	Summary   string `gorm:"type:text" json:"summary"`
 }
 
 // Constants for message statuses
 const (
 	MessageStatusPending = "pending"
+	MessageStatusSending = "sending" // claimed by a scheduler and being sent
 	MessageStatusSent    = "sent"
 	MessageStatusFailed  = "failed"
+	MessageStatusExpired = "expired" // deadline passed before the message could be sent
 	MessageStatusRetry   = "retry"
 )
 
+// Constants for message attempt outcomes
+const (
+	AttemptOutcomeSent       = "sent"
+	AttemptOutcomeRetry      = "retry"       // the message was scheduled again
+	AttemptOutcomeDeadLetter = "dead-letter" // out of attempts, the message was kept as failed
+	AttemptOutcomeDropped    = "dropped"     // out of attempts, the message was deleted
+)
+
+// Constants for event kinds
+const (
+	EventKindMessage = "message" // queued, processing, sent, retrying, failed, deferred, expired
+	EventKindWindow  = "window"  // discovered, lost, claude_lost, claude_restored
+	EventKindUsage   = "usage"   // threshold
+	EventKindError   = "error"   // scheduler, discovery
+)
+
+// Constants for window supervision policies
+const (
+	SupervisionNone    = "none"
+	SupervisionNotify  = "notify"
+	SupervisionRestart = "restart"
+)
+
 // Constants for scheduler states
 const (
 	SchedulerStatusIdle    = "idle"
@@ -106,8 +201,10 @@ const (
 
 // Constants for scheduler names
 const (
-	SchedulerSmart = "smart"
-	SchedulerCron  = "cron"
+	SchedulerGlobal = "global" // pause switch for all sending
+	SchedulerSmart  = "smart"
+	SchedulerCron   = "cron"
+	SchedulerDaemon = "daemon" // heartbeat of 'tcs daemon'
 )
 
 // Helper methods
@@ -125,9 +222,9 @@ func (uw *UsageWindow) TimeRemaining() time.Duration {
 	return time.Until(uw.EndTime)
 }
 
-// CanRetry checks if a message can be retried
-func (m *Message) CanRetry() bool {
-	return m.Status == MessageStatusFailed && m.Retries < m.MaxRetries
+// IsExpired checks if a message's deadline has passed
+func (m *Message) IsExpired() bool {
+	return m.ExpiresAt != nil && time.Now().After(*m.ExpiresAt)
 }
 
 // IsScheduled checks if a message is ready to be sent
@@ -188,13 +285,413 @@ func UpdateMessageStatus(db *gorm.DB, messageID uint, status string, errorMsg st
 		now := time.Now()
 		updates["sent_at"] = &now
 	}
+	if status != MessageStatusSending {
+		updates["lease_owner"] = ""
+		updates["lease_expires_at"] = nil
+	}
 
 	if errorMsg != "" {
 		updates["error"] = errorMsg
 		updates["retries"] = gorm.Expr("retries + 1")
 	}
 
-	return db.Model(&Message{}).Where("id = ?", messageID).Updates(updates).Error
+	if err := db.Model(&Message{}).Where("id = ?", messageID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// Keep the queue's pending count accurate
+	var message Message
+	if err := db.Select("window_id").First(&message, messageID).Error; err != nil {
+		return nil // Message removed meanwhile; nothing to count
+	}
+	return SyncQueueMessageCount(db, message.WindowID)
+}
+
+// MessageLeaseDuration is how long a scheduler may spend sending a message
+// before other schedulers may reclaim it
+const MessageLeaseDuration = 2 * time.Minute
+
+// LeaseOwnerID identifies this process as a lease owner
+func LeaseOwnerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// ClaimMessage moves a pending message to sending and leases it to owner. The
+// update only succeeds for one caller, so a message is sent once even when
+// several processes schedule from the same database.
+func ClaimMessage(db *gorm.DB, messageID uint, owner string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(lease)
+	result := db.Model(&Message{}).
+		Where("id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)", messageID, MessageStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":           MessageStatusSending,
+			"lease_owner":      owner,
+			"lease_expires_at": &expiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpireOverdueMessages moves pending messages whose deadline has passed to
+// expired, and returns them
+func ExpireOverdueMessages(db *gorm.DB) ([]Message, error) {
+	var overdue []Message
+	if err := db.Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", MessageStatusPending, time.Now()).
+		Preload("Window").Find(&overdue).Error; err != nil {
+		return nil, err
+	}
+	if len(overdue) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(overdue))
+	windowIDs := make(map[uint]bool)
+	for i, message := range overdue {
+		ids[i] = message.ID
+		windowIDs[message.WindowID] = true
+	}
+
+	// Only expire messages that are still pending
+	if err := db.Model(&Message{}).Where("id IN ? AND status = ?", ids, MessageStatusPending).
+		Update("status", MessageStatusExpired).Error; err != nil {
+		return nil, err
+	}
+	for windowID := range windowIDs {
+		if err := SyncQueueMessageCount(db, windowID); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range overdue {
+		overdue[i].Status = MessageStatusExpired
+	}
+	return overdue, nil
+}
+
+// ReleaseMessageLease puts a message leased to owner back to pending
+func ReleaseMessageLease(db *gorm.DB, messageID uint, owner string) error {
+	return db.Model(&Message{}).
+		Where("id = ? AND status = ? AND lease_owner = ?", messageID, MessageStatusSending, owner).
+		Updates(map[string]interface{}{
+			"status":           MessageStatusPending,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		}).Error
+}
+
+// DeferMessage hands a claimed message back to the queue to be sent at a later
+// time. Unlike a failed attempt this does not count against its retries.
+func DeferMessage(db *gorm.DB, messageID uint, owner string, until time.Time) error {
+	return db.Model(&Message{}).
+		Where("id = ? AND status = ? AND lease_owner = ?", messageID, MessageStatusSending, owner).
+		Updates(map[string]interface{}{
+			"status":           MessageStatusPending,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"scheduled_time":   until,
+		}).Error
+}
+
+// ReclaimExpiredLeases puts messages whose lease expired back to pending, e.g.
+// after the sending process crashed. It returns the number of messages reclaimed.
+func ReclaimExpiredLeases(db *gorm.DB) (int64, error) {
+	result := db.Model(&Message{}).
+		Where("status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)", MessageStatusSending, time.Now()).
+		Updates(map[string]interface{}{
+			"status":           MessageStatusPending,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// GetInFlightMessages returns messages currently being sent, optionally only
+// for one window (windowID 0 means all windows)
+func GetInFlightMessages(db *gorm.DB, windowID uint) ([]Message, error) {
+	var messages []Message
+	query := db.Where("status = ?", MessageStatusSending).
+		Order("lease_expires_at ASC").
+		Preload("Window")
+	if windowID != 0 {
+		query = query.Where("window_id = ?", windowID)
+	}
+	err := query.Find(&messages).Error
+	return messages, err
+}
+
+// IsPaused checks if a scheduler is paused. Timed pauses end on their own.
+func (s *SchedulerState) IsPaused() bool {
+	return !s.Enabled && (s.ResumeAt == nil || time.Now().Before(*s.ResumeAt))
+}
+
+// GetSchedulerState returns the state of a scheduler, creating it when missing
+func GetSchedulerState(db *gorm.DB, name string) (*SchedulerState, error) {
+	state := SchedulerState{Name: name, Enabled: true, Status: SchedulerStatusIdle}
+	if err := db.Where(SchedulerState{Name: name}).FirstOrCreate(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// PauseScheduling stops all schedulers from sending messages until
+// ResumeScheduling is called or, for a timed pause, until resumeAt
+func PauseScheduling(db *gorm.DB, resumeAt *time.Time, reason string) error {
+	state, err := GetSchedulerState(db, SchedulerGlobal)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return db.Model(state).Updates(map[string]interface{}{
+		"enabled":    false,
+		"paused_at":  &now,
+		"paused_for": reason,
+		"resume_at":  resumeAt,
+	}).Error
+}
+
+// ResumeScheduling lifts a pause set with PauseScheduling
+func ResumeScheduling(db *gorm.DB) error {
+	state, err := GetSchedulerState(db, SchedulerGlobal)
+	if err != nil {
+		return err
+	}
+	return db.Model(state).Updates(map[string]interface{}{
+		"enabled":    true,
+		"paused_at":  nil,
+		"paused_for": "",
+		"resume_at":  nil,
+	}).Error
+}
+
+// RecordMessageAttempt adds an attempt to a message's history
+func RecordMessageAttempt(db *gorm.DB, attempt *MessageAttempt) error {
+	return db.Create(attempt).Error
+}
+
+// GetMessageAttempts returns the attempt history of a message, oldest first
+func GetMessageAttempts(db *gorm.DB, messageID uint) ([]MessageAttempt, error) {
+	var attempts []MessageAttempt
+	err := db.Where("message_id = ?", messageID).Order("id ASC").Find(&attempts).Error
+	return attempts, err
+}
+
+// RecordEvent adds an event to the event log
+func RecordEvent(db *gorm.DB, event *Event) error {
+	return db.Create(event).Error
+}
+
+// GetRecentEvents returns the latest events of a kind, oldest first. An
+// empty kind returns events of all kinds.
+func GetRecentEvents(db *gorm.DB, kind string, limit int) ([]Event, error) {
+	var events []Event
+	if err := eventsOfKind(db, kind).Order("id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// GetEventsAfter returns the events of a kind recorded after the event with
+// the given ID, oldest first. An empty kind returns events of all kinds.
+func GetEventsAfter(db *gorm.DB, afterID uint, kind string, limit int) ([]Event, error) {
+	var events []Event
+	err := eventsOfKind(db, kind).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// eventsOfKind scopes an event query to a kind unless it is empty
+func eventsOfKind(db *gorm.DB, kind string) *gorm.DB {
+	if kind == "" {
+		return db
+	}
+	return db.Where("kind = ?", kind)
+}
+
+// GetFailedMessages returns the dead-lettered messages, most recently failed
+// first. A windowID of 0 returns those of all windows.
+func GetFailedMessages(db *gorm.DB, windowID uint) ([]Message, error) {
+	var messages []Message
+	query := db.Where("status = ?", MessageStatusFailed).
+		Order("updated_at DESC").
+		Preload("Window")
+	if windowID != 0 {
+		query = query.Where("window_id = ?", windowID)
+	}
+	err := query.Find(&messages).Error
+	return messages, err
+}
+
+// RequeueFailedMessages moves failed messages back to pending, due now, with
+// their retries and error cleared; deadlines that already passed are dropped.
+// An empty ids list requeues every failed message. A non-zero windowID moves
+// the messages to that window, and a route makes them routed again.
+func RequeueFailedMessages(db *gorm.DB, ids []uint, windowID uint, route string) (int64, error) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":           MessageStatusPending,
+		"scheduled_time":   now,
+		"retries":          0,
+		"error":            "",
+		"lease_owner":      "",
+		"lease_expires_at": nil,
+		"expires_at":       gorm.Expr("CASE WHEN expires_at <= ? THEN NULL ELSE expires_at END", now),
+	}
+	if windowID != 0 {
+		updates["window_id"] = windowID
+		updates["route"] = route
+	} else if route != "" {
+		updates["route"] = route
+	}
+
+	return updateFailedMessages(db, ids, windowID, func(tx *gorm.DB) *gorm.DB {
+		return tx.Updates(updates)
+	})
+}
+
+// DeleteFailedMessages deletes failed messages, every one of them when ids is empty
+func DeleteFailedMessages(db *gorm.DB, ids []uint) (int64, error) {
+	return updateFailedMessages(db, ids, 0, func(tx *gorm.DB) *gorm.DB {
+		return tx.Delete(&Message{})
+	})
+}
+
+// updateFailedMessages applies change to the failed messages among ids (all
+// failed messages when ids is empty) in one transaction and updates the
+// message counts of the affected queues, including newWindowID's
+func updateFailedMessages(db *gorm.DB, ids []uint, newWindowID uint, change func(*gorm.DB) *gorm.DB) (int64, error) {
+	var affected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&Message{}).Where("status = ?", MessageStatusFailed)
+		if len(ids) > 0 {
+			query = query.Where("id IN ?", ids)
+		}
+
+		var windowIDs []uint
+		if err := query.Session(&gorm.Session{}).Distinct().Pluck("window_id", &windowIDs).Error; err != nil {
+			return err
+		}
+		if newWindowID != 0 {
+			windowIDs = append(windowIDs, newWindowID)
+		}
+
+		result := change(query)
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+
+		for _, windowID := range windowIDs {
+			if err := SyncQueueMessageCount(tx, windowID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return affected, err
+}
+
+// Bulk message actions
+const (
+	BulkDelete     = "delete"
+	BulkPriority   = "priority"
+	BulkReschedule = "reschedule"
+	BulkRetarget   = "retarget"
+)
+
+// BulkMessageAction is a change made to several messages at once
+type BulkMessageAction struct {
+	Kind       string        // BulkDelete, BulkPriority, BulkReschedule or BulkRetarget
+	Priority   int           // new priority for BulkPriority
+	Shift      time.Duration // moves the scheduled times for BulkReschedule...
+	ScheduleAt *time.Time    // ...unless set, which replaces them
+	Target     string        // window the messages move to for BulkRetarget
+}
+
+// ApplyBulkMessageAction applies action to the messages among ids and updates
+// the message counts of the affected queues. Messages being sent are left
+// alone, and only pending messages are changed by anything but a delete. Run
+// it inside Transaction so that it changes all of the messages or none.
+func ApplyBulkMessageAction(tx *gorm.DB, ids []uint, action BulkMessageAction) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := tx.Model(&Message{}).Where("id IN ?", ids)
+	if action.Kind == BulkDelete {
+		query = query.Where("status <> ?", MessageStatusSending)
+	} else {
+		query = query.Where("status = ?", MessageStatusPending)
+	}
+
+	var messages []Message
+	if err := query.Session(&gorm.Session{}).Find(&messages).Error; err != nil {
+		return 0, err
+	}
+	windowIDs := make(map[uint]bool)
+	for _, message := range messages {
+		windowIDs[message.WindowID] = true
+	}
+
+	var result *gorm.DB
+	switch action.Kind {
+	case BulkDelete:
+		result = query.Delete(&Message{})
+
+	case BulkPriority:
+		if action.Priority < 1 || action.Priority > 10 {
+			return 0, fmt.Errorf("priority must be between 1 and 10")
+		}
+		result = query.Update("priority", action.Priority)
+
+	case BulkReschedule:
+		if action.ScheduleAt != nil {
+			result = query.Update("scheduled_time", *action.ScheduleAt)
+			break
+		}
+		// Shifted times differ per message, so they are saved one by one
+		for _, message := range messages {
+			err := tx.Model(&Message{}).Where("id = ?", message.ID).
+				Update("scheduled_time", message.ScheduledTime.Add(action.Shift)).Error
+			if err != nil {
+				return 0, err
+			}
+		}
+		return int64(len(messages)), nil
+
+	case BulkRetarget:
+		window, err := GetTmuxWindow(tx, action.Target)
+		if err != nil {
+			return 0, fmt.Errorf("window %s not found: %w", action.Target, err)
+		}
+		if _, err := GetOrCreateWindowMessageQueue(tx, window.ID); err != nil {
+			return 0, err
+		}
+		windowIDs[window.ID] = true
+		// A message sent to a window is no longer routed
+		result = query.Updates(map[string]interface{}{"window_id": window.ID, "route": ""})
+
+	default:
+		return 0, fmt.Errorf("unknown bulk action %q", action.Kind)
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	for windowID := range windowIDs {
+		if err := SyncQueueMessageCount(tx, windowID); err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected, nil
 }
 
 // CleanupOldData removes old data to keep database size manageable
@@ -207,6 +704,16 @@ func CleanupOldData(db *gorm.DB, olderThan time.Duration) error {
 		return err
 	}
 
+	// Clean up old attempt history
+	if err := db.Where("created_at < ?", cutoff).Delete(&MessageAttempt{}).Error; err != nil {
+		return err
+	}
+
+	// Clean up old events
+	if err := db.Where("created_at < ?", cutoff).Delete(&Event{}).Error; err != nil {
+		return err
+	}
+
 	// Clean up old usage windows
 	if err := db.Where("active = false AND created_at < ?", cutoff).
 		Delete(&UsageWindow{}).Error; err != nil {
@@ -330,6 +837,105 @@ func CreateOrUpdateTmuxWindow(db *gorm.DB, sessionName string, windowIndex int,
 	return &window, nil
 }
 
+// UpdateTmuxWindowClaudeProcess records the Claude process found in a window.
+// A zero PID clears the process details.
+func UpdateTmuxWindowClaudeProcess(db *gorm.DB, windowID uint, pid int, startedAt *time.Time, args []string) error {
+	updates := map[string]interface{}{
+		"claude_pid":        pid,
+		"claude_started_at": startedAt,
+		"claude_args":       strings.Join(args, " "),
+	}
+	if pid == 0 {
+		updates["claude_started_at"] = nil
+		updates["claude_args"] = ""
+	}
+
+	if err := db.Model(&TmuxWindow{}).Where("id = ?", windowID).Updates(updates).Error; err != nil {
+		return err
+	}
+	InvalidateActiveTmuxWindowsCache()
+	return nil
+}
+
+// UpdateTmuxWindowClaudeStatus records the model, permission mode and version
+// of a window's Claude session. Empty values keep what was recorded before,
+// since the banner and status line are not always visible.
+func UpdateTmuxWindowClaudeStatus(db *gorm.DB, windowID uint, model, permissionMode, version string) error {
+	updates := map[string]interface{}{}
+	if model != "" {
+		updates["claude_model"] = model
+	}
+	if permissionMode != "" {
+		updates["claude_permission_mode"] = permissionMode
+	}
+	if version != "" {
+		updates["claude_version"] = version
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if err := db.Model(&TmuxWindow{}).Where("id = ?", windowID).Updates(updates).Error; err != nil {
+		return err
+	}
+	InvalidateActiveTmuxWindowsCache()
+	return nil
+}
+
+// ClearTmuxWindowClaudeStatus forgets the Claude session details of a window
+func ClearTmuxWindowClaudeStatus(db *gorm.DB, windowID uint) error {
+	updates := map[string]interface{}{
+		"claude_model":           "",
+		"claude_permission_mode": "",
+		"claude_version":         "",
+	}
+	if err := db.Model(&TmuxWindow{}).Where("id = ?", windowID).Updates(updates).Error; err != nil {
+		return err
+	}
+	InvalidateActiveTmuxWindowsCache()
+	return nil
+}
+
+// CreateWindowGroup creates a new window group
+func CreateWindowGroup(db *gorm.DB, name, description string) (*WindowGroup, error) {
+	group := &WindowGroup{Name: name, Description: description}
+	if err := db.Create(group).Error; err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// GetWindowGroup gets a window group by name with its member windows
+func GetWindowGroup(db *gorm.DB, name string) (*WindowGroup, error) {
+	var group WindowGroup
+	err := db.Preload("Windows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("session_name ASC, window_index ASC")
+	}).Where("name = ?", name).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetWindowGroups returns all window groups with their member windows
+func GetWindowGroups(db *gorm.DB) ([]WindowGroup, error) {
+	var groups []WindowGroup
+	err := db.Preload("Windows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("session_name ASC, window_index ASC")
+	}).Order("name ASC").Find(&groups).Error
+	return groups, err
+}
+
+// AddWindowToGroup adds a window to a group
+func AddWindowToGroup(db *gorm.DB, group *WindowGroup, window *TmuxWindow) error {
+	return db.Model(group).Association("Windows").Append(window)
+}
+
+// RemoveWindowFromGroup removes a window from a group
+func RemoveWindowFromGroup(db *gorm.DB, group *WindowGroup, window *TmuxWindow) error {
+	return db.Model(group).Association("Windows").Delete(window)
+}
+
 // GetOrCreateWindowMessageQueue gets or creates a message queue for a window
 func GetOrCreateWindowMessageQueue(db *gorm.DB, windowID uint) (*WindowMessageQueue, error) {
 	var queue WindowMessageQueue
@@ -362,6 +968,119 @@ func GetOrCreateWindowMessageQueue(db *gorm.DB, windowID uint) (*WindowMessageQu
 	return &queue, nil
 }
 
+// QueueInFlightTimeout is how long a queue slot stays taken before it is
+// considered abandoned, e.g. after a crash mid-send
+const QueueInFlightTimeout = 5 * time.Minute
+
+// IsPaused reports whether the queue is paused
+func (q *WindowMessageQueue) IsPaused() bool {
+	return !q.Active
+}
+
+// IsInFlight reports whether a message is currently being sent from the queue
+func (q *WindowMessageQueue) IsInFlight() bool {
+	return q.InFlightSince != nil && time.Since(*q.InFlightSince) < QueueInFlightTimeout
+}
+
+// NextSendTime returns the earliest time the queue may send its next message
+func (q *WindowMessageQueue) NextSendTime() time.Time {
+	if q.MinSpacing <= 0 || q.LastProcessed == nil {
+		return time.Time{}
+	}
+	return q.LastProcessed.Add(q.MinSpacing)
+}
+
+// State describes what the queue is doing: "paused", "sending", "spacing"
+// while waiting out its minimum spacing, or "ready"
+func (q *WindowMessageQueue) State() string {
+	switch {
+	case q.IsPaused():
+		return "paused"
+	case q.IsInFlight():
+		return "sending"
+	case time.Now().Before(q.NextSendTime()):
+		return "spacing"
+	}
+	return "ready"
+}
+
+// AcquireQueueSlot claims a window's queue for sending one message. It returns
+// false when the queue is paused, already has a message in flight, or its
+// minimum spacing has not elapsed. The claim is a conditional update, so only
+// one scheduler wins even across processes.
+func AcquireQueueSlot(db *gorm.DB, windowID uint) (bool, error) {
+	queue, err := GetOrCreateWindowMessageQueue(db, windowID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if queue.IsPaused() || now.Before(queue.NextSendTime()) {
+		return false, nil
+	}
+
+	result := db.Model(&WindowMessageQueue{}).
+		Where("id = ? AND active = ? AND (in_flight_since IS NULL OR in_flight_since < ?)",
+			queue.ID, true, now.Add(-QueueInFlightTimeout)).
+		Update("in_flight_since", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseQueueSlot frees a window's queue after a send attempt. Processed
+// attempts start the queue's minimum spacing.
+func ReleaseQueueSlot(db *gorm.DB, windowID uint, processed bool) error {
+	updates := map[string]interface{}{
+		"in_flight_since": nil,
+	}
+	if processed {
+		updates["last_processed"] = time.Now()
+	}
+
+	if err := db.Model(&WindowMessageQueue{}).Where("window_id = ?", windowID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return SyncQueueMessageCount(db, windowID)
+}
+
+// SetQueueActive pauses or resumes a window's queue
+func SetQueueActive(db *gorm.DB, windowID uint, active bool) error {
+	queue, err := GetOrCreateWindowMessageQueue(db, windowID)
+	if err != nil {
+		return err
+	}
+	return db.Model(queue).Update("active", active).Error
+}
+
+// SyncQueueMessageCount recounts the pending messages of a window's queue.
+// Routed messages are not counted until they are sent, since their window is
+// only provisional while pending.
+func SyncQueueMessageCount(db *gorm.DB, windowID uint) error {
+	var count int64
+	err := db.Model(&Message{}).
+		Where("window_id = ? AND status = ? AND (route = '' OR route IS NULL)", windowID, MessageStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&WindowMessageQueue{}).Where("window_id = ?", windowID).
+		Update("message_count", count).Error
+}
+
+// SyncQueueMessageCounts recounts the pending messages of all queues
+func SyncQueueMessageCounts(db *gorm.DB) error {
+	return db.Exec(`UPDATE window_message_queues SET message_count = (
+		SELECT COUNT(*) FROM messages
+		WHERE messages.window_id = window_message_queues.window_id
+		  AND messages.status = ?
+		  AND messages.deleted_at IS NULL
+		  AND (messages.route = '' OR messages.route IS NULL)
+	)`, MessageStatusPending).Error
+}
+
 // GetPendingMessagesForWindow returns pending messages for a specific window
 func GetPendingMessagesForWindow(db *gorm.DB, windowID uint, limit int) ([]Message, error) {
 	var messages []Message
@@ -378,16 +1097,20 @@ func GetPendingMessagesForWindow(db *gorm.DB, windowID uint, limit int) ([]Messa
 	return messages, err
 }
 
-// GetPendingMessagesForAllWindows returns all pending messages ordered by window priority and message priority
+// GetPendingMessagesForAllWindows returns all pending messages ordered by window priority and message priority.
+// Messages for windows without Claude stay pending until Claude is detected again.
 func GetPendingMessagesForAllWindows(db *gorm.DB, limit int) ([]Message, error) {
 	var messages []Message
 	query := db.Table("messages").
 		Select("messages.*").
 		Joins("JOIN tmux_windows ON messages.window_id = tmux_windows.id").
 		Joins("JOIN window_message_queues ON tmux_windows.id = window_message_queues.window_id").
-		Where("messages.status = ? AND messages.scheduled_time <= ? AND tmux_windows.active = ? AND window_message_queues.active = ?",
-			MessageStatusPending, time.Now(), true, true).
-		Order("window_message_queues.priority DESC, messages.priority DESC, messages.scheduled_time ASC").
+		Where("messages.status = ? AND messages.scheduled_time <= ?", MessageStatusPending, time.Now()).
+		// Routed messages are checked against their route at dispatch instead
+		Where("messages.route <> '' OR (tmux_windows.active = ? AND tmux_windows.has_claude = ? AND window_message_queues.active = ?)",
+			true, true, true).
+		// Earliest deadline first among equal priorities
+		Order("window_message_queues.priority DESC, messages.priority DESC, messages.expires_at IS NULL, messages.expires_at ASC, messages.scheduled_time ASC").
 		Preload("Window")
 
 	if limit > 0 {
@@ -0,0 +1,301 @@
+package discovery
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tmux"
+)
+
+// Supervisor applies per-window supervision policies when Claude exits in a window
+type Supervisor struct {
+	db         *gorm.DB
+	tmuxClient *tmux.Client
+	config     *SupervisorConfig
+
+	mu      sync.Mutex
+	running bool
+	states  map[uint]*restartState
+
+	// Callbacks
+	onRestarted []func(*database.TmuxWindow)
+}
+
+// SupervisorConfig holds supervision configuration
+type SupervisorConfig struct {
+	DefaultPolicy  string        `json:"default_policy"`  // Policy for windows without an explicit one
+	RestartCommand string        `json:"restart_command"` // Default command used to restart Claude
+	InitialBackoff time.Duration `json:"initial_backoff"` // Delay before the first restart attempt
+	MaxBackoff     time.Duration `json:"max_backoff"`     // Upper bound for the restart delay
+	MaxAttempts    int           `json:"max_attempts"`    // Give up after this many failed attempts
+	StartupTimeout time.Duration `json:"startup_timeout"` // How long to wait for the Claude prompt
+}
+
+// DefaultSupervisorConfig returns supervision configuration from the tmux config
+func DefaultSupervisorConfig() *SupervisorConfig {
+	cfg := config.GetTmuxConfig()
+
+	supervisorConfig := &SupervisorConfig{
+		DefaultPolicy:  cfg.SupervisionPolicy,
+		RestartCommand: cfg.RestartCommand,
+		InitialBackoff: cfg.RestartBackoff,
+		MaxBackoff:     cfg.RestartMaxBackoff,
+		MaxAttempts:    cfg.RestartMaxAttempts,
+		StartupTimeout: 30 * time.Second,
+	}
+
+	if supervisorConfig.DefaultPolicy == "" {
+		supervisorConfig.DefaultPolicy = database.SupervisionNone
+	}
+	if supervisorConfig.RestartCommand == "" {
+		supervisorConfig.RestartCommand = "claude --continue"
+	}
+	if supervisorConfig.InitialBackoff <= 0 {
+		supervisorConfig.InitialBackoff = 10 * time.Second
+	}
+	if supervisorConfig.MaxBackoff < supervisorConfig.InitialBackoff {
+		supervisorConfig.MaxBackoff = supervisorConfig.InitialBackoff
+	}
+	if supervisorConfig.MaxAttempts <= 0 {
+		supervisorConfig.MaxAttempts = 5
+	}
+
+	return supervisorConfig
+}
+
+// restartState tracks restart attempts for a single window
+type restartState struct {
+	attempts    int
+	timer       *time.Timer
+	restarting  bool
+	lastRestart time.Time
+}
+
+// NewSupervisor creates a new window supervisor
+func NewSupervisor(db *gorm.DB, tmuxClient *tmux.Client, config *SupervisorConfig) *Supervisor {
+	if config == nil {
+		config = DefaultSupervisorConfig()
+	}
+
+	return &Supervisor{
+		db:         db,
+		tmuxClient: tmuxClient,
+		config:     config,
+		states:     make(map[uint]*restartState),
+	}
+}
+
+// Start enables supervision actions
+func (s *Supervisor) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = true
+}
+
+// Stop cancels pending restarts and disables supervision actions
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running = false
+	for windowID, state := range s.states {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		delete(s.states, windowID)
+	}
+}
+
+// PolicyFor returns the effective supervision policy for a window
+func (s *Supervisor) PolicyFor(window *database.TmuxWindow) string {
+	if window.SupervisionPolicy != "" {
+		return window.SupervisionPolicy
+	}
+	return s.config.DefaultPolicy
+}
+
+// RestartCommandFor returns the effective restart command for a window
+func (s *Supervisor) RestartCommandFor(window *database.TmuxWindow) string {
+	if window.RestartCommand != "" {
+		return window.RestartCommand
+	}
+	return s.config.RestartCommand
+}
+
+// HandleClaudeLost applies the window's policy after Claude disappeared from it
+func (s *Supervisor) HandleClaudeLost(window *database.TmuxWindow) {
+	switch s.PolicyFor(window) {
+	case database.SupervisionNotify:
+		log.Printf("Supervisor: Claude exited in window %s", window.Target)
+		if err := s.tmuxClient.DisplayMessage(window.Target, fmt.Sprintf("TCS: Claude exited in %s", window.Target)); err != nil {
+			log.Printf("Supervisor: failed to notify window %s: %v", window.Target, err)
+		}
+	case database.SupervisionRestart:
+		log.Printf("Supervisor: Claude exited in window %s, scheduling restart", window.Target)
+		s.scheduleRestart(window)
+	}
+}
+
+// HandleClaudeRestored cancels any pending restart once Claude is back in a window
+func (s *Supervisor) HandleClaudeRestored(window *database.TmuxWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.states[window.ID]
+	if !exists {
+		return
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+		state.timer = nil
+	}
+
+	// Claude recovered, so a later exit starts with the full number of attempts
+	state.attempts = 0
+}
+
+// OnRestarted adds a callback for when the supervisor restarted Claude in a window
+func (s *Supervisor) OnRestarted(callback func(*database.TmuxWindow)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRestarted = append(s.onRestarted, callback)
+}
+
+// scheduleRestart schedules the next restart attempt for a window using exponential backoff
+func (s *Supervisor) scheduleRestart(window *database.TmuxWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+
+	state, exists := s.states[window.ID]
+	if !exists {
+		state = &restartState{}
+		s.states[window.ID] = state
+	}
+
+	if state.timer != nil || state.restarting {
+		return // Restart already pending
+	}
+
+	// Claude stayed up long enough since the last restart, so start over
+	if !state.lastRestart.IsZero() && time.Since(state.lastRestart) > s.config.MaxBackoff {
+		state.attempts = 0
+	}
+
+	if state.attempts >= s.config.MaxAttempts {
+		log.Printf("Supervisor: giving up on window %s after %d restart attempts", window.Target, state.attempts)
+		return
+	}
+
+	delay := s.backoff(state.attempts)
+	windowID := window.ID
+	state.timer = time.AfterFunc(delay, func() {
+		s.attemptRestart(windowID)
+	})
+
+	log.Printf("Supervisor: restarting Claude in %s in %v (attempt %d/%d)",
+		window.Target, delay, state.attempts+1, s.config.MaxAttempts)
+}
+
+// backoff returns the delay before the given restart attempt
+func (s *Supervisor) backoff(attempts int) time.Duration {
+	delay := s.config.InitialBackoff
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.config.MaxBackoff {
+			return s.config.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// attemptRestart runs the restart command in a window and waits for Claude
+func (s *Supervisor) attemptRestart(windowID uint) {
+	s.mu.Lock()
+	state, exists := s.states[windowID]
+	if !exists || !s.running {
+		s.mu.Unlock()
+		return
+	}
+	state.timer = nil
+	state.restarting = true
+	state.attempts++
+	s.mu.Unlock()
+
+	// A failed attempt schedules the next one only after this one is over,
+	// otherwise it would still see this restart in progress
+	var retry *database.TmuxWindow
+	defer func() {
+		s.mu.Lock()
+		state.restarting = false
+		state.lastRestart = time.Now()
+		s.mu.Unlock()
+
+		if retry != nil {
+			s.scheduleRestart(retry)
+		}
+	}()
+
+	var window database.TmuxWindow
+	if err := s.db.First(&window, windowID).Error; err != nil {
+		log.Printf("Supervisor: window %d not found: %v", windowID, err)
+		return
+	}
+
+	// Nothing to do if the window went away, Claude came back or the policy changed
+	if !window.Active || window.HasClaude || s.PolicyFor(&window) != database.SupervisionRestart {
+		return
+	}
+
+	command := s.RestartCommandFor(&window)
+	log.Printf("Supervisor: running '%s' in %s", command, window.Target)
+
+	err := s.restart(window.Target, command)
+	if err != nil {
+		log.Printf("Supervisor: restart of %s failed: %v", window.Target, err)
+		retry = &window
+		return
+	}
+
+	if err := s.db.Model(&window).Update("has_claude", true).Error; err != nil {
+		log.Printf("Supervisor: failed to update window %s: %v", window.Target, err)
+	}
+	database.InvalidateActiveTmuxWindowsCache()
+	window.HasClaude = true
+
+	log.Printf("Supervisor: Claude restarted in %s", window.Target)
+	s.emitRestarted(&window)
+}
+
+// restart clears the pane so stale Claude output is not mistaken for a prompt,
+// then starts Claude again
+func (s *Supervisor) restart(target, command string) error {
+	if err := s.tmuxClient.ClearPane(target); err != nil {
+		return err
+	}
+	if err := s.tmuxClient.StartCommand(target, command); err != nil {
+		return err
+	}
+	return s.tmuxClient.WaitForClaudePrompt(target, command, s.config.StartupTimeout)
+}
+
+// emitRestarted emits a restarted event
+func (s *Supervisor) emitRestarted(window *database.TmuxWindow) {
+	s.mu.Lock()
+	callbacks := append([]func(*database.TmuxWindow){}, s.onRestarted...)
+	s.mu.Unlock()
+
+	for _, callback := range callbacks {
+		go callback(window)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
 	"github.com/derekxwang/tcs/internal/config"
 	"github.com/derekxwang/tcs/internal/database"
@@ -30,9 +32,14 @@ type WindowDiscovery struct {
 	// Statistics
 	stats *DiscoveryStats
 
+	// Supervision of windows whose Claude session exits
+	supervisor *Supervisor
+
 	// Callbacks
 	onWindowDiscovered []func(*database.TmuxWindow)
 	onWindowLost       []func(*database.TmuxWindow)
+	onClaudeLost       []func(*database.TmuxWindow)
+	onClaudeRestored   []func(*database.TmuxWindow)
 	onError            []func(error)
 }
 
@@ -79,12 +86,18 @@ func NewWindowDiscovery(db *gorm.DB, tmuxClient *tmux.Client, config *Config) *W
 		config = DefaultConfig()
 	}
 
-	return &WindowDiscovery{
+	wd := &WindowDiscovery{
 		db:         db,
 		tmuxClient: tmuxClient,
 		config:     config,
 		stats:      &DiscoveryStats{},
+		supervisor: NewSupervisor(db, tmuxClient, nil),
 	}
+
+	// A successful restart brings Claude back without waiting for the next scan
+	wd.supervisor.OnRestarted(wd.emitClaudeRestored)
+
+	return wd
 }
 
 // Start starts the window discovery service
@@ -98,6 +111,7 @@ func (wd *WindowDiscovery) Start() error {
 
 	wd.ctx, wd.cancel = context.WithCancel(context.Background())
 	wd.running = true
+	wd.supervisor.Start()
 
 	// Start discovery loop
 	go wd.discoveryLoop()
@@ -120,6 +134,7 @@ func (wd *WindowDiscovery) Stop() error {
 
 	wd.cancel()
 	wd.running = false
+	wd.supervisor.Stop()
 
 	log.Printf("Window discovery stopped")
 	return nil
@@ -183,6 +198,17 @@ func (wd *WindowDiscovery) performScan() {
 		return
 	}
 
+	// Read the process table once for all windows
+	var detector *tmux.ClaudeDetector
+	if wd.config.ClaudeDetection {
+		cfg := config.GetTmuxConfig()
+		detector = wd.tmuxClient.NewClaudeDetector(
+			cfg.ClaudeDetectionMethod,
+			cfg.ClaudeProcessNames,
+			utils.ConfiguredRuleSet(),
+		)
+	}
+
 	// Process sessions concurrently
 	semaphore := make(chan struct{}, wd.config.MaxConcurrentScans)
 	var wg sync.WaitGroup
@@ -196,7 +222,7 @@ func (wd *WindowDiscovery) performScan() {
 			go func(sess tmux.SessionInfo) {
 				defer wg.Done()
 				defer func() { <-semaphore }()
-				wd.processSessions(sess)
+				wd.processSessions(sess, detector)
 			}(session)
 		}
 	}
@@ -208,51 +234,22 @@ func (wd *WindowDiscovery) performScan() {
 }
 
 // processSessions processes a single session and its windows
-func (wd *WindowDiscovery) processSessions(session tmux.SessionInfo) {
+func (wd *WindowDiscovery) processSessions(session tmux.SessionInfo, detector *tmux.ClaudeDetector) {
 	for _, window := range session.Windows {
-		wd.processWindow(window)
+		wd.processWindow(window, detector)
 	}
 }
 
 // processWindow processes a single window
-func (wd *WindowDiscovery) processWindow(windowInfo tmux.WindowInfo) {
+func (wd *WindowDiscovery) processWindow(windowInfo tmux.WindowInfo, detector *tmux.ClaudeDetector) {
 	log.Printf("Processing window: %s (session: %s, index: %d)",
 		windowInfo.Target, windowInfo.SessionName, windowInfo.WindowIndex)
 
 	// Detect if window has Claude (if enabled)
 	hasClaude := false
-	if wd.config.ClaudeDetection {
-		cfg := config.Get()
-		detectionMethod := cfg.Tmux.ClaudeDetectionMethod
-		processNames := cfg.Tmux.ClaudeProcessNames
-
-		switch detectionMethod {
-		case "process":
-			// Process-based detection only
-			processDetected, err := wd.tmuxClient.DetectClaudeProcessWithNames(windowInfo.Target, processNames)
-			if err == nil && processDetected {
-				hasClaude = true
-			}
-		case "text":
-			// Content-based detection only
-			content, err := wd.tmuxClient.CapturePane(windowInfo.Target, 50)
-			if err == nil {
-				hasClaude = wd.isClaudeWindow(content)
-			}
-		case "both":
-		default:
-			// Try process detection first (more reliable for Claude Code)
-			processDetected, err := wd.tmuxClient.DetectClaudeProcessWithNames(windowInfo.Target, processNames)
-			if err == nil && processDetected {
-				hasClaude = true
-			} else {
-				// Fallback to content-based detection
-				content, err := wd.tmuxClient.CapturePane(windowInfo.Target, 50)
-				if err == nil {
-					hasClaude = wd.isClaudeWindow(content)
-				}
-			}
-		}
+	var claudeProcess *tmux.ClaudeProcess
+	if detector != nil {
+		hasClaude, claudeProcess = detector.Detect(windowInfo.Target)
 
 		if hasClaude {
 			wd.mu.Lock()
@@ -269,6 +266,9 @@ func (wd *WindowDiscovery) processWindow(windowInfo tmux.WindowInfo) {
 		return // Don't persist to database
 	}
 
+	// Remember the previous Claude state to detect transitions
+	known, hadClaude := wd.previousClaudeState(windowInfo.Target)
+
 	// Create or update window in database
 	log.Printf("Saving window %s to database", windowInfo.Target)
 	dbWindow, err := database.CreateOrUpdateTmuxWindow(
@@ -284,6 +284,26 @@ func (wd *WindowDiscovery) processWindow(windowInfo tmux.WindowInfo) {
 		return
 	}
 	log.Printf("Successfully saved window %s to database (ID: %d)", windowInfo.Target, dbWindow.ID)
+	dbWindow.HasClaude = hasClaude
+
+	if wd.config.ClaudeDetection {
+		wd.recordClaudeProcess(dbWindow, claudeProcess)
+		wd.recordClaudeStatus(dbWindow, hasClaude, claudeProcess)
+	}
+
+	// Apply the supervision policy when Claude exits or comes back
+	if known && wd.config.ClaudeDetection {
+		switch {
+		case hadClaude && !hasClaude:
+			log.Printf("Claude lost in window %s", dbWindow.Target)
+			wd.emitClaudeLost(dbWindow)
+			wd.supervisor.HandleClaudeLost(dbWindow)
+		case !hadClaude && hasClaude:
+			log.Printf("Claude restored in window %s", dbWindow.Target)
+			wd.supervisor.HandleClaudeRestored(dbWindow)
+			wd.emitClaudeRestored(dbWindow)
+		}
+	}
 
 	// Check if this is a newly discovered window
 	isNewWindow := dbWindow.CreatedAt.After(time.Now().Add(-wd.config.ScanInterval * 2))
@@ -304,6 +324,60 @@ func (wd *WindowDiscovery) processWindow(windowInfo tmux.WindowInfo) {
 	}
 }
 
+// recordClaudeProcess stores the PID, start time and arguments of a window's Claude process
+func (wd *WindowDiscovery) recordClaudeProcess(window *database.TmuxWindow, process *tmux.ClaudeProcess) {
+	pid := 0
+	var startedAt *time.Time
+	var args []string
+	if process != nil && process.PID > 0 {
+		pid = process.PID
+		started := process.StartTime
+		startedAt = &started
+		args = process.Args
+	}
+
+	if err := database.UpdateTmuxWindowClaudeProcess(wd.db, window.ID, pid, startedAt, args); err != nil {
+		wd.emitError(fmt.Errorf("failed to record Claude process for window %s: %w", window.Target, err))
+		return
+	}
+
+	window.ClaudePID = pid
+	window.ClaudeStartedAt = startedAt
+	window.ClaudeArgs = strings.Join(args, " ")
+}
+
+// recordClaudeStatus stores the model, permission mode and version of a window's
+// Claude session, read from its arguments and the status line
+func (wd *WindowDiscovery) recordClaudeStatus(window *database.TmuxWindow, hasClaude bool, process *tmux.ClaudeProcess) {
+	if !hasClaude {
+		if window.ClaudeModel == "" && window.ClaudePermissionMode == "" && window.ClaudeVersion == "" {
+			return
+		}
+		if err := database.ClearTmuxWindowClaudeStatus(wd.db, window.ID); err != nil {
+			wd.emitError(fmt.Errorf("failed to clear Claude status for window %s: %w", window.Target, err))
+			return
+		}
+		window.ClaudeModel, window.ClaudePermissionMode, window.ClaudeVersion = "", "", ""
+		return
+	}
+
+	status := wd.tmuxClient.ReadClaudeStatus(window.Target, process)
+	if err := database.UpdateTmuxWindowClaudeStatus(wd.db, window.ID, status.Model, status.PermissionMode, status.Version); err != nil {
+		wd.emitError(fmt.Errorf("failed to record Claude status for window %s: %w", window.Target, err))
+		return
+	}
+
+	if status.Model != "" {
+		window.ClaudeModel = status.Model
+	}
+	if status.PermissionMode != "" {
+		window.ClaudePermissionMode = status.PermissionMode
+	}
+	if status.Version != "" {
+		window.ClaudeVersion = status.Version
+	}
+}
+
 // cleanupInactiveWindows marks windows as inactive if they haven't been seen recently
 func (wd *WindowDiscovery) cleanupInactiveWindows() {
 	cutoff := time.Now().Add(-wd.config.InactiveTimeout)
@@ -340,9 +414,16 @@ func (wd *WindowDiscovery) cleanupInactiveWindows() {
 	wd.mu.Unlock()
 }
 
-// isClaudeWindow checks if window content indicates a Claude session
-func (wd *WindowDiscovery) isClaudeWindow(content string) bool {
-	return utils.IsClaudeWindow(content)
+// previousClaudeState returns whether a window is already known and whether it had Claude
+func (wd *WindowDiscovery) previousClaudeState(target string) (known bool, hadClaude bool) {
+	var window database.TmuxWindow
+	// Use silent logger for expected "record not found" on new windows
+	err := wd.db.Session(&gorm.Session{Logger: wd.db.Logger.LogMode(logger.Silent)}).
+		Select("has_claude").Where("target = ?", target).First(&window).Error
+	if err != nil {
+		return false, false
+	}
+	return true, window.HasClaude
 }
 
 // countTotalWindows counts total windows across all sessions
@@ -379,6 +460,11 @@ func (wd *WindowDiscovery) ForceRescan() error {
 	return nil
 }
 
+// GetSupervisor returns the supervisor applying window supervision policies
+func (wd *WindowDiscovery) GetSupervisor() *Supervisor {
+	return wd.supervisor
+}
+
 // IsRunning returns whether the service is running
 func (wd *WindowDiscovery) IsRunning() bool {
 	wd.mu.RLock()
@@ -402,6 +488,21 @@ func (wd *WindowDiscovery) OnWindowLost(callback func(*database.TmuxWindow)) {
 	wd.onWindowLost = append(wd.onWindowLost, callback)
 }
 
+// OnClaudeLost adds a callback for when Claude exits in a known window
+func (wd *WindowDiscovery) OnClaudeLost(callback func(*database.TmuxWindow)) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.onClaudeLost = append(wd.onClaudeLost, callback)
+}
+
+// OnClaudeRestored adds a callback for when Claude is detected again in a window
+// that had lost it, either by a scan or after a supervisor restart
+func (wd *WindowDiscovery) OnClaudeRestored(callback func(*database.TmuxWindow)) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.onClaudeRestored = append(wd.onClaudeRestored, callback)
+}
+
 // OnError adds a callback for error events
 func (wd *WindowDiscovery) OnError(callback func(error)) {
 	wd.mu.Lock()
@@ -423,6 +524,20 @@ func (wd *WindowDiscovery) emitWindowLost(window *database.TmuxWindow) {
 	}
 }
 
+// emitClaudeLost emits a Claude lost event
+func (wd *WindowDiscovery) emitClaudeLost(window *database.TmuxWindow) {
+	for _, callback := range wd.onClaudeLost {
+		go callback(window)
+	}
+}
+
+// emitClaudeRestored emits a Claude restored event
+func (wd *WindowDiscovery) emitClaudeRestored(window *database.TmuxWindow) {
+	for _, callback := range wd.onClaudeRestored {
+		go callback(window)
+	}
+}
+
 // emitError emits an error event
 func (wd *WindowDiscovery) emitError(err error) {
 	wd.mu.Lock()
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/discovery"
+	"github.com/derekxwang/tcs/internal/monitor"
+	"github.com/derekxwang/tcs/internal/scheduler"
+)
+
+// UsageThresholds are the usage percentages that are recorded when crossed
+var UsageThresholds = []float64{0.5, 0.75, 0.9, 1.0}
+
+// Recorder persists scheduler, window discovery and usage events to the event table
+type Recorder struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastUsage float64 // usage percentage of the last usage update
+	usageSeen bool    // a usage update has been received
+}
+
+// NewRecorder creates a new event recorder
+func NewRecorder(db *gorm.DB) *Recorder {
+	return &Recorder{db: db}
+}
+
+// Attach subscribes the recorder to the callbacks of the given services; any of them may be nil
+func (r *Recorder) Attach(schedulerInstance *scheduler.Scheduler, windowDiscovery *discovery.WindowDiscovery, usageMonitor *monitor.UsageMonitor) {
+	if schedulerInstance != nil {
+		schedulerInstance.AddMessageCallback(r.RecordMessageEvent)
+		schedulerInstance.AddErrorCallback(func(err error) {
+			r.RecordError("scheduler", err)
+		})
+	}
+
+	if windowDiscovery != nil {
+		windowDiscovery.OnWindowDiscovered(func(window *database.TmuxWindow) {
+			r.RecordWindowEvent("discovered", window)
+		})
+		windowDiscovery.OnWindowLost(func(window *database.TmuxWindow) {
+			r.RecordWindowEvent("lost", window)
+		})
+		windowDiscovery.OnClaudeLost(func(window *database.TmuxWindow) {
+			r.RecordWindowEvent("claude_lost", window)
+		})
+		windowDiscovery.OnClaudeRestored(func(window *database.TmuxWindow) {
+			r.RecordWindowEvent("claude_restored", window)
+		})
+		windowDiscovery.OnError(func(err error) {
+			r.RecordError("discovery", err)
+		})
+	}
+
+	if usageMonitor != nil {
+		usageMonitor.AddUsageCallback(r.RecordUsage)
+	}
+}
+
+// RecordMessageEvent records a message event from the scheduler
+func (r *Recorder) RecordMessageEvent(event *scheduler.MessageEvent) {
+	message := event.Message
+	target := message.Window.Target
+	if event.Window != nil && event.Window.Target != "" {
+		target = event.Window.Target
+	}
+	if message.Route != "" && (target == "" || event.Type == "queued") {
+		target = message.Route // the window is only chosen at dispatch
+	}
+
+	var summary string
+	switch event.Type {
+	case "queued":
+		summary = fmt.Sprintf("Queued with priority %d: %s", message.Priority, snippet(message.Content))
+	case "processing":
+		summary = fmt.Sprintf("Sending, attempt %d: %s", event.Attempt, snippet(message.Content))
+	case "sent":
+		summary = fmt.Sprintf("Sent on attempt %d: %s", event.Attempt, snippet(message.Content))
+	case "expired":
+		summary = "Expired before it could be sent: " + snippet(message.Content)
+	default: // retrying, failed, deferred
+		summary = fmt.Sprintf("%s after attempt %d", strings.ToUpper(event.Type[:1])+event.Type[1:], event.Attempt)
+		if event.Error != nil {
+			summary += ": " + event.Error.Error()
+		}
+	}
+
+	r.record(&database.Event{
+		Kind:      database.EventKindMessage,
+		Type:      event.Type,
+		Target:    target,
+		MessageID: message.ID,
+		Summary:   summary,
+	})
+}
+
+// RecordWindowEvent records a window event from window discovery
+func (r *Recorder) RecordWindowEvent(eventType string, window *database.TmuxWindow) {
+	var summary string
+	switch eventType {
+	case "discovered":
+		summary = fmt.Sprintf("Window %s discovered", window.WindowName)
+		if window.HasClaude {
+			summary += " with Claude"
+		}
+	case "lost":
+		summary = fmt.Sprintf("Window %s is gone", window.WindowName)
+	case "claude_lost":
+		summary = "Claude exited"
+	case "claude_restored":
+		summary = "Claude is running again"
+	default:
+		summary = eventType
+	}
+
+	r.record(&database.Event{
+		Kind:    database.EventKindWindow,
+		Type:    eventType,
+		Target:  window.Target,
+		Summary: summary,
+	})
+}
+
+// RecordUsage records the usage thresholds crossed since the last usage update
+func (r *Recorder) RecordUsage(stats *monitor.UsageStats) {
+	r.mu.Lock()
+	previous, seen := r.lastUsage, r.usageSeen
+	r.lastUsage, r.usageSeen = stats.UsagePercentage, true
+	r.mu.Unlock()
+
+	// The first update is the baseline, so thresholds crossed before a restart aren't recorded again
+	if !seen {
+		return
+	}
+
+	// Only the highest threshold crossed is recorded, so a jump from 40% to 95% is one event
+	crossed := 0.0
+	for _, threshold := range UsageThresholds {
+		if previous < threshold && stats.UsagePercentage >= threshold {
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return
+	}
+
+	r.record(&database.Event{
+		Kind: database.EventKindUsage,
+		Type: "threshold",
+		Summary: fmt.Sprintf("Usage crossed %.0f%%: %d/%d messages, %d/%d tokens, $%.2f/$%.2f",
+			crossed*100, stats.MessagesUsed, stats.MessageLimit,
+			stats.TokensUsed, stats.TokenLimit, stats.CostUsed, stats.CostLimit),
+	})
+}
+
+// RecordError records an error reported by a service
+func (r *Recorder) RecordError(source string, err error) {
+	r.record(&database.Event{
+		Kind:    database.EventKindError,
+		Type:    source,
+		Summary: err.Error(),
+	})
+}
+
+// record saves an event; failures are only logged since events are informational
+func (r *Recorder) record(event *database.Event) {
+	if err := database.RecordEvent(r.db, event); err != nil {
+		log.Printf("Warning: failed to record %s event: %v", event.Kind, err)
+	}
+}
+
+// snippet shortens message content to its first line of at most 60 characters
+func snippet(content string) string {
+	line, _, multiline := strings.Cut(strings.TrimSpace(content), "\n")
+	runes := []rune(line)
+	if len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	if multiline {
+		return line + " ..."
+	}
+	return line
+}
@@ -32,6 +32,10 @@ type UsageMonitor struct {
 	statsInProgress bool
 	cachedStats     *UsageStats
 	cacheTime       time.Time
+
+	// Usage history cache (protected by mu); reading days of Claude data is slow
+	cachedHistory *claude.UsageHistory
+	historyTime   time.Time
 }
 
 // UsageStats represents current usage statistics
@@ -580,6 +584,29 @@ func (um *UsageMonitor) GetHistoricalUsage(days int) ([]database.UsageWindow, er
 	return windows, err
 }
 
+// GetUsageHistory returns the Claude token history of the last days, read at
+// most once a minute
+func (um *UsageMonitor) GetUsageHistory(days int) (*claude.UsageHistory, error) {
+	um.mu.RLock()
+	if um.cachedHistory != nil && time.Since(um.historyTime) < time.Minute {
+		history := um.cachedHistory
+		um.mu.RUnlock()
+		return history, nil
+	}
+	um.mu.RUnlock()
+
+	history, err := um.claudeReader.GetUsageHistory(days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage history: %w", err)
+	}
+
+	um.mu.Lock()
+	um.cachedHistory = history
+	um.historyTime = time.Now()
+	um.mu.Unlock()
+	return history, nil
+}
+
 // PredictUsage predicts when the current window will be exhausted
 func (um *UsageMonitor) PredictUsage() (*UsagePrediction, error) {
 	um.mu.RLock()
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	cron "github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/config"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/tmux"
 )
@@ -17,6 +19,9 @@ import (
 type CronScheduler struct {
 	db            *gorm.DB
 	messageSender *tmux.MessageSender
+	owner         string        // lease owner recorded on claimed messages
+	retry         RetryPolicies // retry policy per error class for failed sends
+	quietHours    []QuietPeriod // periods in which nothing is sent, set by the Scheduler
 
 	// Cron instance
 	cron *cron.Cron
@@ -52,6 +57,8 @@ func NewCronScheduler(
 	return &CronScheduler{
 		db:            db,
 		messageSender: messageSender,
+		owner:         database.LeaseOwnerID(),
+		retry:         RetryPoliciesFromConfig(config.GetSchedulerConfig().Retry),
 		cron:          c,
 		jobs:          make(map[string]cron.EntryID),
 	}
@@ -133,8 +140,8 @@ func (cs *CronScheduler) ScheduleMessage(target, content, cronExpr string, prior
 func (cs *CronScheduler) executeCronJob(jobID, target, content string, priority int) {
 	log.Printf("Executing cron job '%s' for target '%s'", jobID, target)
 
-	// Get or create window
-	window, err := database.GetTmuxWindow(cs.db, target)
+	// Get the window, resolving routes at execution time
+	window, route, err := cs.resolveTarget(target)
 	if err != nil {
 		log.Printf("Cron job '%s' failed: target '%s' not found: %v", jobID, target, err)
 		return
@@ -147,6 +154,7 @@ func (cs *CronScheduler) executeCronJob(jobID, target, content string, priority
 		ScheduledTime: time.Now(),
 		Priority:      priority,
 		Status:        database.MessageStatusPending,
+		Route:         route,
 	}
 
 	if err := cs.db.Create(message).Error; err != nil {
@@ -160,28 +168,86 @@ func (cs *CronScheduler) executeCronJob(jobID, target, content string, priority
 		return
 	}
 
-	// Send the message immediately (cron jobs are exact-time)
-	result, err := cs.messageSender.SendQueuedMessage(
+	// Cron jobs are exact-time, but still respect pauses, quiet hours and the
+	// window's queue. Held messages stay pending for the scheduler.
+	hold, err := CurrentHold(cs.db, cs.quietHours, time.Now())
+	if err != nil {
+		log.Printf("Warning: cron job '%s': %v", jobID, err)
+	}
+	if hold != nil {
+		log.Printf("Cron job '%s': sending is %s, message %d left pending", jobID, hold, message.ID)
+		if err := database.SyncQueueMessageCount(cs.db, window.ID); err != nil {
+			log.Printf("Warning: failed to update queue count: %v", err)
+		}
+		return
+	}
+
+	acquired, err := database.AcquireQueueSlot(cs.db, window.ID)
+	if err != nil || !acquired {
+		if err != nil {
+			log.Printf("Cron job '%s': could not claim queue for '%s': %v", jobID, window.Target, err)
+		} else {
+			log.Printf("Cron job '%s': queue for '%s' is paused or busy, message %d left pending", jobID, window.Target, message.ID)
+		}
+		if err := database.SyncQueueMessageCount(cs.db, window.ID); err != nil {
+			log.Printf("Warning: failed to update queue count: %v", err)
+		}
+		return
+	}
+	claimed, err := database.ClaimMessage(cs.db, message.ID, cs.owner, database.MessageLeaseDuration)
+	if err != nil || !claimed {
+		// Another scheduler picked the message up from the queue first
+		if err := database.ReleaseQueueSlot(cs.db, window.ID, false); err != nil {
+			log.Printf("Warning: failed to release queue for '%s': %v", window.Target, err)
+		}
+		return
+	}
+	// A deferred message was never sent, so it doesn't start the queue's spacing
+	processed := true
+	defer func() {
+		if err := database.ReleaseQueueSlot(cs.db, window.ID, processed); err != nil {
+			log.Printf("Warning: failed to release queue for '%s': %v", window.Target, err)
+		}
+	}()
+
+	// Send the message immediately
+	start := time.Now()
+	_, err = cs.messageSender.SendQueuedMessage(
 		window.Target,
 		content,
 		priority,
 	)
 
+	if errors.Is(err, tmux.ErrHumanBusy) {
+		// Leave the window to whoever is using it; the scheduler sends the message later
+		processed = false
+		retryTime := time.Now().Add(cs.messageSender.HumanBusyGrace())
+		if err := database.DeferMessage(cs.db, message.ID, cs.owner, retryTime); err != nil {
+			log.Printf("Error deferring message: %v", err)
+		}
+		log.Printf("Cron job '%s': message %d deferred until %s: %v", jobID, message.ID, retryTime.Format(time.Kitchen), err)
+		return
+	}
 	if err != nil {
-		// Mark as failed
-		errorMsg := err.Error()
-		if result != nil {
-			errorMsg = result.Error
+		// Failed sends follow the retry policy; retries go through the scheduler queue
+		attempt, dbErr := RecordFailedAttempt(cs.db, cs.retry, message, err, time.Since(start))
+		if dbErr != nil {
+			log.Printf("Error recording failed attempt: %v", dbErr)
 		}
-
-		if dbErr := database.UpdateMessageStatus(cs.db, message.ID, database.MessageStatusFailed, errorMsg); dbErr != nil {
-			log.Printf("Error updating message status: %v", dbErr)
+		if attempt != nil && attempt.Outcome == database.AttemptOutcomeRetry {
+			log.Printf("Cron job '%s' failed to send message, retrying at %s: %v",
+				jobID, attempt.NextRetryAt.Format(time.Kitchen), err)
+			return
 		}
 
 		log.Printf("Cron job '%s' failed to send message: %v", jobID, err)
 		return
 	}
 
+	if err := RecordSentAttempt(cs.db, message, time.Since(start)); err != nil {
+		log.Printf("Warning: failed to record attempt: %v", err)
+	}
+
 	// Success
 	if err := database.UpdateMessageStatus(cs.db, message.ID, database.MessageStatusSent, ""); err != nil {
 		log.Printf("Error updating message status: %v", err)
@@ -195,6 +261,26 @@ func (cs *CronScheduler) executeCronJob(jobID, target, content string, priority
 	log.Printf("Cron job '%s' successfully executed", jobID)
 }
 
+// resolveTarget returns the window for a "session:window" target or a route,
+// along with the canonical route (empty for plain targets)
+func (cs *CronScheduler) resolveTarget(target string) (*database.TmuxWindow, string, error) {
+	if !IsRoute(target) {
+		window, err := database.GetTmuxWindow(cs.db, target)
+		return window, "", err
+	}
+
+	route, err := ParseRoute(target)
+	if err != nil {
+		return nil, "", err
+	}
+
+	window, err := ResolveRoute(cs.db, cs.messageSender, route)
+	if err != nil {
+		return nil, "", err
+	}
+	return window, route.String(), nil
+}
+
 // RemoveJob removes a cron job
 func (cs *CronScheduler) RemoveJob(jobID string) error {
 	cs.mu.Lock()
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/derekxwang/tcs/internal/database"
+)
+
+// WindowShare is a window's weighted fair share of the messages sent in the
+// current usage window
+type WindowShare struct {
+	Window  database.TmuxWindow `json:"window"`
+	Weight  int                 `json:"weight"`  // TmuxWindow.Priority, at least 1
+	Share   float64             `json:"share"`   // fraction of the budget, 0-1; 0 without a backlog
+	Sent    int                 `json:"sent"`    // messages sent or being sent since Since
+	Pending int                 `json:"pending"` // due pending messages
+	Deficit float64             `json:"deficit"` // messages owed: share of all sent minus own sent
+}
+
+// FairShares computes the share and deficit of every window that has due
+// pending messages or sent messages in the current usage window. Only windows
+// with a backlog of due messages split the budget, in proportion to their
+// priority, so a window with priority 8 gets twice the messages of one with
+// priority 4 and an idle window takes nothing from them. Results are ordered
+// by deficit, most owed first, with the idle windows last.
+func FairShares(db *gorm.DB) ([]WindowShare, time.Time, error) {
+	since := time.Now().Add(-5 * time.Hour)
+	// No usage window yet is expected, so keep the lookup quiet
+	quiet := db.Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent)})
+	if usageWindow, err := database.GetCurrentUsageWindow(quiet); err == nil {
+		since = usageWindow.StartTime
+	}
+
+	var sentRows []struct {
+		WindowID uint
+		Count    int
+	}
+	err := db.Model(&database.Message{}).
+		Select("window_id, COUNT(*) AS count").
+		Where("(status = ? AND sent_at >= ?) OR status = ?",
+			database.MessageStatusSent, since, database.MessageStatusSending).
+		Group("window_id").
+		Scan(&sentRows).Error
+	if err != nil {
+		return nil, since, fmt.Errorf("failed to count sent messages: %w", err)
+	}
+
+	var pendingRows []struct {
+		WindowID uint
+		Count    int
+	}
+	err = db.Model(&database.Message{}).
+		Select("window_id, COUNT(*) AS count").
+		Where("status = ? AND scheduled_time <= ?", database.MessageStatusPending, time.Now()).
+		Group("window_id").
+		Scan(&pendingRows).Error
+	if err != nil {
+		return nil, since, fmt.Errorf("failed to count pending messages: %w", err)
+	}
+
+	sent := make(map[uint]int, len(sentRows))
+	pending := make(map[uint]int, len(pendingRows))
+	var ids []uint
+	for _, row := range sentRows {
+		sent[row.WindowID] = row.Count
+		ids = append(ids, row.WindowID)
+	}
+	for _, row := range pendingRows {
+		pending[row.WindowID] = row.Count
+		if _, ok := sent[row.WindowID]; !ok {
+			ids = append(ids, row.WindowID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, since, nil
+	}
+
+	var windows []database.TmuxWindow
+	if err := db.Where("id IN ?", ids).Find(&windows).Error; err != nil {
+		return nil, since, fmt.Errorf("failed to load windows: %w", err)
+	}
+
+	totalWeight, totalSent := 0, 0
+	shares := make([]WindowShare, 0, len(windows))
+	for _, window := range windows {
+		share := WindowShare{
+			Window:  window,
+			Weight:  max(1, window.Priority),
+			Sent:    sent[window.ID],
+			Pending: pending[window.ID],
+		}
+		if share.Pending > 0 {
+			totalWeight += share.Weight
+			totalSent += share.Sent
+		}
+		shares = append(shares, share)
+	}
+
+	for i := range shares {
+		if shares[i].Pending == 0 {
+			continue // Idle windows are owed nothing
+		}
+		shares[i].Share = float64(shares[i].Weight) / float64(totalWeight)
+		shares[i].Deficit = shares[i].Share*float64(totalSent) - float64(shares[i].Sent)
+	}
+
+	sort.SliceStable(shares, func(i, j int) bool {
+		if idle := shares[i].Pending == 0; idle != (shares[j].Pending == 0) {
+			return !idle
+		}
+		if shares[i].Deficit != shares[j].Deficit {
+			return shares[i].Deficit > shares[j].Deficit
+		}
+		return shares[i].Weight > shares[j].Weight
+	})
+	return shares, since, nil
+}
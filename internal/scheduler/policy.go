@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+)
+
+// Scheduling policy names
+const (
+	PolicyPriority  = "priority"
+	PolicyFairShare = "fair-share"
+	PolicyDeadline  = "deadline"
+	PolicyPaced     = "paced"
+)
+
+// PolicyNames lists the available scheduling policies
+var PolicyNames = []string{PolicyPriority, PolicyFairShare, PolicyDeadline, PolicyPaced}
+
+// Policy decides which due messages the scheduler dispatches and in what order.
+// Policies are only used from the processing loop, so they need no locking.
+type Policy interface {
+	// Name returns the policy name
+	Name() string
+	// Order sorts due messages, the one to dispatch first at the front.
+	// Messages arrive ordered by queue priority, message priority and age.
+	Order(messages []database.Message)
+	// Budget returns how many messages may be dispatched now, at most limit
+	Budget(now time.Time, limit int) int
+	// Dispatched records that a message was claimed for sending
+	Dispatched(message *database.Message, now time.Time)
+}
+
+// NewPolicy creates the scheduling policy with the given name. The interval
+// is the minimum time between two messages for the paced policy.
+func NewPolicy(db *gorm.DB, name string, interval time.Duration) (Policy, error) {
+	// The names match those config.validateConfig accepts
+	switch name {
+	case PolicyPriority:
+		return &priorityPolicy{}, nil
+	case "", PolicyFairShare:
+		return &fairSharePolicy{db: db}, nil
+	case PolicyDeadline:
+		return &deadlinePolicy{}, nil
+	case PolicyPaced:
+		if interval <= 0 {
+			return nil, fmt.Errorf("paced policy needs a positive interval")
+		}
+		return &pacedPolicy{interval: interval}, nil
+	}
+	return nil, fmt.Errorf("unknown scheduling policy '%s' (expected %s)", name, strings.Join(PolicyNames, ", "))
+}
+
+// priorityPolicy sends the highest priority messages first
+type priorityPolicy struct{}
+
+func (p *priorityPolicy) Name() string { return PolicyPriority }
+
+func (p *priorityPolicy) Order(messages []database.Message) {} // already in priority order
+
+func (p *priorityPolicy) Budget(now time.Time, limit int) int { return limit }
+
+func (p *priorityPolicy) Dispatched(message *database.Message, now time.Time) {}
+
+// fairSharePolicy is weighted fair queueing across windows: each window gets
+// a share of the usage budget proportional to its priority, and the window
+// furthest behind its share goes first. A window with a long queue of high
+// priority messages cannot starve the others.
+type fairSharePolicy struct {
+	db *gorm.DB
+}
+
+func (p *fairSharePolicy) Name() string { return PolicyFairShare }
+
+func (p *fairSharePolicy) Order(messages []database.Message) {
+	shares, _, err := FairShares(p.db)
+	if err != nil {
+		log.Printf("Warning: fair-share ordering unavailable: %v", err)
+		return
+	}
+
+	rank := make(map[uint]int, len(shares))
+	for i, share := range shares {
+		rank[share.Window.ID] = i
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return rank[messages[i].WindowID] < rank[messages[j].WindowID]
+	})
+}
+
+func (p *fairSharePolicy) Budget(now time.Time, limit int) int { return limit }
+
+func (p *fairSharePolicy) Dispatched(message *database.Message, now time.Time) {} // sends are counted in the database
+
+// deadlinePolicy is earliest deadline first. Messages without a deadline
+// follow, those that have been due the longest first.
+type deadlinePolicy struct{}
+
+func (p *deadlinePolicy) Name() string { return PolicyDeadline }
+
+func (p *deadlinePolicy) Order(messages []database.Message) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		if before, decided := deadlineBefore(&messages[i], &messages[j]); decided {
+			return before
+		}
+		if !messages[i].ScheduledTime.Equal(messages[j].ScheduledTime) {
+			return messages[i].ScheduledTime.Before(messages[j].ScheduledTime)
+		}
+		return messages[i].Priority > messages[j].Priority
+	})
+}
+
+// deadlineBefore compares the deadlines of two messages. Messages with a
+// deadline come before those without. decided is false when the deadlines
+// do not order the messages.
+func deadlineBefore(a, b *database.Message) (before, decided bool) {
+	switch {
+	case a.ExpiresAt == nil && b.ExpiresAt == nil:
+		return false, false
+	case a.ExpiresAt == nil:
+		return false, true
+	case b.ExpiresAt == nil:
+		return true, true
+	case a.ExpiresAt.Equal(*b.ExpiresAt):
+		return false, false
+	}
+	return a.ExpiresAt.Before(*b.ExpiresAt), true
+}
+
+func (p *deadlinePolicy) Budget(now time.Time, limit int) int { return limit }
+
+func (p *deadlinePolicy) Dispatched(message *database.Message, now time.Time) {}
+
+// pacedPolicy sends in priority order but at most one message per interval
+// across all windows
+type pacedPolicy struct {
+	interval     time.Duration
+	lastDispatch time.Time
+}
+
+func (p *pacedPolicy) Name() string { return PolicyPaced }
+
+func (p *pacedPolicy) Order(messages []database.Message) {}
+
+func (p *pacedPolicy) Budget(now time.Time, limit int) int {
+	if now.Sub(p.lastDispatch) < p.interval {
+		return 0
+	}
+	return min(1, limit)
+}
+
+func (p *pacedPolicy) Dispatched(message *database.Message, now time.Time) {
+	p.lastDispatch = now
+}
@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/database"
+)
+
+// weekdays maps the day names used in quiet hours to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// QuietPeriod is a recurring period in which no messages are sent
+type QuietPeriod struct {
+	Name     string
+	start    int                   // minutes after midnight
+	end      int                   // minutes after midnight; before start when the period runs past midnight
+	days     map[time.Weekday]bool // days the period starts on; empty means every day
+	location *time.Location
+}
+
+// ParseQuietHours parses the configured quiet hours
+func ParseQuietHours(settings []config.QuietHours) ([]QuietPeriod, error) {
+	periods := make([]QuietPeriod, 0, len(settings))
+	for _, quiet := range settings {
+		start, err := time.Parse("15:04", quiet.Start)
+		if err != nil {
+			return nil, fmt.Errorf("quiet hours %s: invalid start '%s'", quiet.Name, quiet.Start)
+		}
+		end, err := time.Parse("15:04", quiet.End)
+		if err != nil {
+			return nil, fmt.Errorf("quiet hours %s: invalid end '%s'", quiet.Name, quiet.End)
+		}
+		// LoadLocation("") is UTC, but an empty timezone means local time
+		location := time.Local
+		if quiet.Timezone != "" {
+			location, err = time.LoadLocation(quiet.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("quiet hours %s: %w", quiet.Name, err)
+			}
+		}
+
+		period := QuietPeriod{
+			Name:     quiet.Name,
+			start:    start.Hour()*60 + start.Minute(),
+			end:      end.Hour()*60 + end.Minute(),
+			days:     make(map[time.Weekday]bool),
+			location: location,
+		}
+		for _, day := range quiet.Days {
+			weekday, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return nil, fmt.Errorf("quiet hours %s: invalid day '%s'", quiet.Name, day)
+			}
+			period.days[weekday] = true
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// startsOn checks if the period starts on a weekday
+func (q QuietPeriod) startsOn(day time.Weekday) bool {
+	return len(q.days) == 0 || q.days[day]
+}
+
+// ActiveAt checks if t falls in the period and returns when that occurrence ends
+func (q QuietPeriod) ActiveAt(t time.Time) (time.Time, bool) {
+	local := t.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	endOn := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, q.end/60, q.end%60, 0, 0, q.location)
+	}
+
+	if q.start < q.end {
+		if minute >= q.start && minute < q.end && q.startsOn(local.Weekday()) {
+			return endOn(0), true
+		}
+		return time.Time{}, false
+	}
+
+	// The period runs past midnight
+	if minute >= q.start && q.startsOn(local.Weekday()) {
+		return endOn(1), true
+	}
+	if minute < q.end && q.startsOn((local.Weekday()+6)%7) {
+		return endOn(0), true
+	}
+	return time.Time{}, false
+}
+
+// String describes the period, e.g. "night 22:00-07:00 Europe/Berlin"
+func (q QuietPeriod) String() string {
+	description := fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+	if q.Name != "" {
+		description = q.Name + " " + description
+	}
+	if q.location != time.Local {
+		description += " " + q.location.String()
+	}
+	return description
+}
+
+// Hold is a pause or quiet period that keeps all messages from being sent
+type Hold struct {
+	Paused bool       // paused with 'tcs pause'; otherwise quiet hours
+	Reason string     // pause reason or quiet period
+	Until  *time.Time // nil until resumed
+}
+
+// String describes the hold, e.g. "quiet hours (night 22:00-07:00) until 07:00"
+func (h *Hold) String() string {
+	description := "quiet hours"
+	if h.Paused {
+		description = "paused"
+	}
+	if h.Reason != "" {
+		description += " (" + h.Reason + ")"
+	}
+	if h.Until != nil {
+		description += " until " + h.Until.Local().Format("Jan 2 15:04")
+	}
+	return description
+}
+
+// CurrentHold returns the global pause or quiet period in effect at now, or
+// nil when messages may be sent. A pause wins over quiet hours.
+func CurrentHold(db *gorm.DB, quietHours []QuietPeriod, now time.Time) (*Hold, error) {
+	state, err := database.GetSchedulerState(db, database.SchedulerGlobal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduler state: %w", err)
+	}
+	if state.IsPaused() {
+		return &Hold{Paused: true, Reason: state.PausedFor, Until: state.ResumeAt}, nil
+	}
+
+	var hold *Hold
+	for _, period := range quietHours {
+		end, active := period.ActiveAt(now)
+		if active && (hold == nil || end.After(*hold.Until)) {
+			hold = &Hold{Reason: period.String(), Until: &end}
+		}
+	}
+	return hold, nil
+}
@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tmux"
+)
+
+// Classes of send errors, each with its own retry policy
+const (
+	ErrorClassTargetNotFound = "target_not_found" // the session or window is gone
+	ErrorClassTmuxDown       = "tmux_down"        // no tmux server
+	ErrorClassPaneBusy       = "pane_busy"        // Claude is still working
+	ErrorClassOther          = "other"
+)
+
+// ClassifyError returns the error class of a failed send
+func ClassifyError(err error) string {
+	switch {
+	case errors.Is(err, tmux.ErrTmuxNotRunning):
+		return ErrorClassTmuxDown
+	case errors.Is(err, tmux.ErrTargetNotFound):
+		return ErrorClassTargetNotFound
+	case errors.Is(err, tmux.ErrPaneBusy):
+		return ErrorClassPaneBusy
+	}
+	return ErrorClassOther
+}
+
+// RetryPolicy decides when a failed send is tried again and when to give up
+type RetryPolicy struct {
+	Backoff     time.Duration `json:"backoff"`      // delay before the first retry
+	MaxBackoff  time.Duration `json:"max_backoff"`  // upper bound for the delay
+	Multiplier  float64       `json:"multiplier"`   // growth of the delay per attempt
+	Jitter      float64       `json:"jitter"`       // random spread of the delay, 0-1
+	MaxAttempts int           `json:"max_attempts"` // attempts before giving up, including the first
+	DeadLetter  bool          `json:"dead_letter"`  // keep given-up messages as failed instead of deleting them
+}
+
+// CanRetry reports whether a message may be tried again after the given attempt failed
+func (p RetryPolicy) CanRetry(attempt int) bool {
+	return attempt < p.MaxAttempts
+}
+
+// Delay returns how long to wait after the given failed attempt (1 for the
+// first). The delay grows exponentially up to MaxBackoff, spread by Jitter.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.Backoff) * math.Pow(multiplier, float64(max(0, attempt-1)))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// RetryPolicies holds the retry policy for each error class
+type RetryPolicies map[string]RetryPolicy
+
+// RetryPoliciesFromConfig builds the retry policies from the scheduler settings
+func RetryPoliciesFromConfig(settings config.RetryConfig) RetryPolicies {
+	policies := make(RetryPolicies)
+	for class, policy := range settings.Classes() {
+		policies[class] = RetryPolicy{
+			Backoff:     policy.Backoff,
+			MaxBackoff:  policy.MaxBackoff,
+			Multiplier:  policy.Multiplier,
+			Jitter:      policy.Jitter,
+			MaxAttempts: policy.MaxAttempts,
+			DeadLetter:  policy.DeadLetter,
+		}
+	}
+	return policies
+}
+
+// For returns the policy for an error class, falling back to the policy for other errors
+func (p RetryPolicies) For(class string) RetryPolicy {
+	if policy, ok := p[class]; ok {
+		return policy
+	}
+	if policy, ok := p[ErrorClassOther]; ok {
+		return policy
+	}
+	return RetryPoliciesFromConfig(config.DefaultRetryConfig())[ErrorClassOther]
+}
+
+// RecordSentAttempt adds a successful attempt to the message's history
+func RecordSentAttempt(db *gorm.DB, message *database.Message, duration time.Duration) error {
+	return database.RecordMessageAttempt(db, &database.MessageAttempt{
+		MessageID: message.ID,
+		WindowID:  message.WindowID,
+		Target:    message.Window.Target,
+		Attempt:   message.Retries + 1,
+		Outcome:   database.AttemptOutcomeSent,
+		Duration:  duration,
+	})
+}
+
+// RecordFailedAttempt records a failed attempt and applies the retry policy of the
+// error's class: the message goes back to pending after a backoff, or when it
+// is out of attempts it is dead-lettered as failed or deleted.
+func RecordFailedAttempt(db *gorm.DB, policies RetryPolicies, message *database.Message, sendErr error, duration time.Duration) (*database.MessageAttempt, error) {
+	class := ClassifyError(sendErr)
+	policy := policies.For(class)
+	attempt := &database.MessageAttempt{
+		MessageID:  message.ID,
+		WindowID:   message.WindowID,
+		Target:     message.Window.Target,
+		Attempt:    message.Retries + 1,
+		ErrorClass: class,
+		Error:      sendErr.Error(),
+		Duration:   duration,
+	}
+
+	switch {
+	case policy.CanRetry(attempt.Attempt):
+		retryTime := time.Now().Add(policy.Delay(attempt.Attempt))
+		attempt.Outcome = database.AttemptOutcomeRetry
+		attempt.NextRetryAt = &retryTime
+		if err := db.Model(&database.Message{}).Where("id = ?", message.ID).Updates(map[string]interface{}{
+			"status":           database.MessageStatusPending,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"scheduled_time":   retryTime,
+			"retries":          attempt.Attempt,
+			"max_retries":      policy.MaxAttempts - 1,
+			"error":            attempt.Error,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to schedule retry: %w", err)
+		}
+
+	case policy.DeadLetter:
+		attempt.Outcome = database.AttemptOutcomeDeadLetter
+		if err := db.Model(&database.Message{}).Where("id = ?", message.ID).Updates(map[string]interface{}{
+			"status":           database.MessageStatusFailed,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"retries":          attempt.Attempt,
+			"max_retries":      policy.MaxAttempts - 1,
+			"error":            attempt.Error,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to mark message as failed: %w", err)
+		}
+		if err := database.SyncQueueMessageCount(db, message.WindowID); err != nil {
+			return nil, fmt.Errorf("failed to update queue count: %w", err)
+		}
+
+	default:
+		attempt.Outcome = database.AttemptOutcomeDropped
+		if err := db.Delete(&database.Message{}, message.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to drop message: %w", err)
+		}
+		if err := database.SyncQueueMessageCount(db, message.WindowID); err != nil {
+			return nil, fmt.Errorf("failed to update queue count: %w", err)
+		}
+	}
+
+	if err := database.RecordMessageAttempt(db, attempt); err != nil {
+		return attempt, fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return attempt, nil
+}
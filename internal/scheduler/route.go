@@ -0,0 +1,331 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tmux"
+	"github.com/derekxwang/tcs/internal/utils"
+)
+
+// Route prefixes mark message targets that are resolved to a window when the
+// message is dispatched, e.g. "any:model=opus,idle" or "group:backend"
+const (
+	RoutePrefix      = "any:"
+	GroupRoutePrefix = "group:"
+)
+
+// ErrNoRouteMatch is returned when no window currently satisfies a route
+var ErrNoRouteMatch = errors.New("no window matches route")
+
+// Route is a constraint-based message target
+type Route struct {
+	Group          string `json:"group,omitempty"`           // window group to pick from
+	Model          string `json:"model,omitempty"`           // model family or name, e.g. "opus"
+	PermissionMode string `json:"permission_mode,omitempty"` // canonical permission mode
+	Version        string `json:"version,omitempty"`         // Claude Code version prefix
+	Idle           bool   `json:"idle,omitempty"`            // only windows where Claude is not busy
+}
+
+// IsRoute reports whether a target is a route rather than a "session:window" target
+func IsRoute(target string) bool {
+	return target == "any" || strings.HasPrefix(target, RoutePrefix) || strings.HasPrefix(target, GroupRoutePrefix)
+}
+
+// ParseRoute parses a route such as "any:model=opus,mode=plan,idle" or
+// "group:backend". Group routes always pick an idle member.
+func ParseRoute(target string) (*Route, error) {
+	if !IsRoute(target) {
+		return nil, fmt.Errorf("'%s' is not a route (expected '%s...' or '%s<name>')", target, RoutePrefix, GroupRoutePrefix)
+	}
+
+	route := &Route{}
+	spec := strings.TrimPrefix(strings.TrimPrefix(target, RoutePrefix), "any")
+
+	if strings.HasPrefix(target, GroupRoutePrefix) {
+		name, rest, _ := strings.Cut(strings.TrimPrefix(target, GroupRoutePrefix), ",")
+		route.Group = strings.TrimSpace(name)
+		if route.Group == "" {
+			return nil, fmt.Errorf("group route needs a group name")
+		}
+		route.Idle = true
+		spec = rest
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if !hasValue {
+			if key != "idle" {
+				return nil, fmt.Errorf("unknown route constraint '%s'", part)
+			}
+			route.Idle = true
+			continue
+		}
+		if value == "" {
+			return nil, fmt.Errorf("route constraint '%s' needs a value", key)
+		}
+
+		switch key {
+		case "model":
+			route.Model = strings.ToLower(value)
+		case "mode":
+			mode := utils.NormalizePermissionMode(value)
+			if mode == "" {
+				return nil, fmt.Errorf("unknown permission mode '%s'", value)
+			}
+			route.PermissionMode = mode
+		case "version":
+			route.Version = strings.TrimPrefix(value, "v")
+		default:
+			return nil, fmt.Errorf("unknown route constraint '%s'", key)
+		}
+	}
+
+	return route, nil
+}
+
+// String returns the canonical form of the route
+func (r *Route) String() string {
+	var parts []string
+	prefix := RoutePrefix
+	if r.Group != "" {
+		prefix = GroupRoutePrefix + r.Group
+		if r.Model != "" || r.PermissionMode != "" || r.Version != "" {
+			prefix += ","
+		}
+	}
+	if r.Model != "" {
+		parts = append(parts, "model="+r.Model)
+	}
+	if r.PermissionMode != "" {
+		parts = append(parts, "mode="+r.PermissionMode)
+	}
+	if r.Version != "" {
+		parts = append(parts, "version="+r.Version)
+	}
+	if r.Idle && r.Group == "" {
+		parts = append(parts, "idle")
+	}
+	return prefix + strings.Join(parts, ",")
+}
+
+// Matches reports whether a window satisfies the route's static constraints.
+// Idleness is checked separately since it needs a look at the pane.
+func (r *Route) Matches(window *database.TmuxWindow) bool {
+	if !window.Active || !window.HasClaude {
+		return false
+	}
+	if r.Model != "" && !utils.ModelMatches(window.ClaudeModel, r.Model) {
+		return false
+	}
+	if r.PermissionMode != "" && window.ClaudePermissionMode != r.PermissionMode {
+		return false
+	}
+	if r.Version != "" && !strings.HasPrefix(window.ClaudeVersion, r.Version) {
+		return false
+	}
+	return true
+}
+
+// Candidates returns the windows matching the route's static constraints
+// whose queue can take a message, least busy first: fewest pending messages, then highest window priority,
+// then least recently active
+func (r *Route) Candidates(db *gorm.DB) ([]database.TmuxWindow, error) {
+	var windows []database.TmuxWindow
+	if r.Group != "" {
+		group, err := database.GetWindowGroup(db, r.Group)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Errorf("window group '%s' not found", r.Group)
+			}
+			return nil, fmt.Errorf("failed to load window group '%s': %w", r.Group, err)
+		}
+		windows = group.Windows
+	} else if err := db.Where("active = ? AND has_claude = ?", true, true).Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load windows: %w", err)
+	}
+
+	blocked, err := blockedQueues(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []database.TmuxWindow
+	for i := range windows {
+		if r.Matches(&windows[i]) && !blocked[windows[i].ID] {
+			matched = append(matched, windows[i])
+		}
+	}
+	if len(matched) < 2 {
+		return matched, nil
+	}
+
+	depths, err := queueDepths(db)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := &matched[i], &matched[j]
+		if depths[a.ID] != depths[b.ID] {
+			return depths[a.ID] < depths[b.ID]
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return lastActivityBefore(a, b)
+	})
+	return matched, nil
+}
+
+// blockedQueues returns the windows whose queue cannot take a message right
+// now: paused, already sending, or still within its minimum spacing
+func blockedQueues(db *gorm.DB) (map[uint]bool, error) {
+	var queues []database.WindowMessageQueue
+	if err := db.Find(&queues).Error; err != nil {
+		return nil, fmt.Errorf("failed to load queues: %w", err)
+	}
+
+	now := time.Now()
+	blocked := make(map[uint]bool)
+	for i := range queues {
+		queue := &queues[i]
+		if queue.IsPaused() || queue.IsInFlight() || now.Before(queue.NextSendTime()) {
+			blocked[queue.WindowID] = true
+		}
+	}
+	return blocked, nil
+}
+
+// queueDepths counts due pending messages per window. Routed messages are not
+// counted since their window is only provisional.
+func queueDepths(db *gorm.DB) (map[uint]int, error) {
+	var rows []struct {
+		WindowID uint
+		Count    int
+	}
+	err := db.Model(&database.Message{}).
+		Select("window_id, COUNT(*) AS count").
+		Where("status = ? AND scheduled_time <= ? AND (route = '' OR route IS NULL)",
+			database.MessageStatusPending, time.Now()).
+		Group("window_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queued messages: %w", err)
+	}
+
+	depths := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		depths[row.WindowID] = row.Count
+	}
+	return depths, nil
+}
+
+// lastActivityBefore reports whether window a was used less recently than b.
+// Windows that were never used come first.
+func lastActivityBefore(a, b *database.TmuxWindow) bool {
+	switch {
+	case a.LastActivity == nil && b.LastActivity == nil:
+		return a.ID < b.ID
+	case a.LastActivity == nil:
+		return true
+	case b.LastActivity == nil:
+		return false
+	}
+	return a.LastActivity.Before(*b.LastActivity)
+}
+
+// ResolveRoute picks the window a routed message should be sent to.
+// Idle routes skip windows where Claude is busy responding.
+func ResolveRoute(db *gorm.DB, sender *tmux.MessageSender, route *Route) (*database.TmuxWindow, error) {
+	candidates, err := route.Candidates(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		window := &candidates[i]
+		if route.Idle && sender != nil {
+			idle, err := sender.IsClaudeIdle(window.Target)
+			if err != nil || !idle {
+				continue
+			}
+		}
+		return window, nil
+	}
+
+	return nil, fmt.Errorf("%w '%s'", ErrNoRouteMatch, route)
+}
+
+// provisionalWindow picks a window to record on a routed message when it is
+// queued. The route is resolved again at dispatch.
+func provisionalWindow(db *gorm.DB, route *Route) (*database.TmuxWindow, error) {
+	candidates, err := route.Candidates(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) > 0 {
+		return &candidates[0], nil
+	}
+
+	// No window matches yet; any member of the group will do until dispatch
+	if route.Group != "" {
+		group, err := database.GetWindowGroup(db, route.Group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load window group '%s': %w", route.Group, err)
+		}
+		if len(group.Windows) == 0 {
+			return nil, fmt.Errorf("window group '%s' has no windows", route.Group)
+		}
+		return &group.Windows[0], nil
+	}
+
+	// Likewise any known window for other routes
+	var window database.TmuxWindow
+	if err := db.Order("active DESC, id ASC").First(&window).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w '%s': no windows discovered yet", ErrNoRouteMatch, route)
+		}
+		return nil, fmt.Errorf("failed to find a window: %w", err)
+	}
+	return &window, nil
+}
+
+// resolveMessageRoute points a routed message at the window chosen for it now.
+// Messages without a route are left unchanged.
+func resolveMessageRoute(db *gorm.DB, sender *tmux.MessageSender, message *database.Message) (*database.TmuxWindow, error) {
+	route, err := ParseRoute(message.Route)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := ResolveRoute(db, sender, route)
+	if err != nil {
+		return nil, err
+	}
+
+	if window.ID != message.WindowID {
+		if err := db.Model(&database.Message{}).Where("id = ?", message.ID).
+			Update("window_id", window.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to assign message %d to window %s: %w", message.ID, window.Target, err)
+		}
+		message.WindowID = window.ID
+	}
+	message.Window = *window
+
+	return window, nil
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -11,19 +12,22 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/config"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/monitor"
 	"github.com/derekxwang/tcs/internal/tmux"
 )
 
-// Scheduler coordinates message scheduling across different strategies
+// Scheduler dispatches queued messages to tmux windows. A Policy decides the
+// order, and each message is claimed in the database before it is sent.
 type Scheduler struct {
-	db             *gorm.DB
-	tmuxClient     *tmux.Client
-	messageSender  *tmux.MessageSender
-	usageMonitor   *monitor.UsageMonitor
-	smartScheduler *SmartScheduler
-	cronScheduler  *CronScheduler
+	db            *gorm.DB
+	tmuxClient    *tmux.Client
+	messageSender *tmux.MessageSender
+	usageMonitor  *monitor.UsageMonitor
+	policy        Policy
+	cronScheduler *CronScheduler
+	owner         string // lease owner recorded on claimed messages
 
 	// Configuration
 	config *Config
@@ -33,6 +37,8 @@ type Scheduler struct {
 	running bool
 	ctx     context.Context
 	cancel  context.CancelFunc
+	trigger chan struct{} // requests an immediate processing round
+	hold    string        // pause or quiet period last seen, to log changes once
 
 	// Statistics
 	stats *SchedulerStats
@@ -44,25 +50,36 @@ type Scheduler struct {
 
 // Config holds scheduler configuration
 type Config struct {
-	SmartSchedulerEnabled bool          `json:"smart_scheduler_enabled"`
+	Policy                string        `json:"policy"`         // fair-share, priority, deadline or paced
+	PacedInterval         time.Duration `json:"paced_interval"` // minimum time between messages for the paced policy
 	CronSchedulerEnabled  bool          `json:"cron_scheduler_enabled"`
 	ProcessingInterval    time.Duration `json:"processing_interval"`
 	MaxConcurrentMessages int           `json:"max_concurrent_messages"`
-	RetryAttempts         int           `json:"retry_attempts"`
-	RetryDelay            time.Duration `json:"retry_delay"`
+	RetryDelay            time.Duration `json:"retry_delay"`      // how long routed messages wait when no window matches
+	Retry                 RetryPolicies `json:"retry"`            // retry policy per error class
+	QuietHours            []QuietPeriod `json:"quiet_hours"`      // periods in which nothing is sent
+	HumanBusyGrace        time.Duration `json:"human_busy_grace"` // how long to leave a window alone while someone uses it
 	HealthCheckInterval   time.Duration `json:"health_check_interval"`
 	StatsUpdateInterval   time.Duration `json:"stats_update_interval"`
 }
 
-// DefaultConfig returns default scheduler configuration
+// DefaultConfig returns the scheduler configuration from the loaded settings
 func DefaultConfig() *Config {
+	settings := config.GetSchedulerConfig()
+	quietHours, err := ParseQuietHours(settings.QuietHours)
+	if err != nil {
+		log.Printf("Warning: ignoring quiet hours: %v", err)
+	}
 	return &Config{
-		SmartSchedulerEnabled: true,
-		CronSchedulerEnabled:  true,
-		ProcessingInterval:    10 * time.Second,
-		MaxConcurrentMessages: 3,
-		RetryAttempts:         3,
-		RetryDelay:            30 * time.Second,
+		Policy:                settings.Policy,
+		PacedInterval:         settings.PacedInterval,
+		CronSchedulerEnabled:  settings.CronEnabled,
+		ProcessingInterval:    settings.ProcessingInterval,
+		MaxConcurrentMessages: settings.MaxConcurrentMessages,
+		RetryDelay:            settings.RetryDelay,
+		Retry:                 RetryPoliciesFromConfig(settings.Retry),
+		QuietHours:            quietHours,
+		HumanBusyGrace:        config.GetTmuxConfig().HumanBusyGrace,
 		HealthCheckInterval:   60 * time.Second,
 		StatsUpdateInterval:   30 * time.Second,
 	}
@@ -70,7 +87,7 @@ func DefaultConfig() *Config {
 
 // MessageEvent represents events in message processing
 type MessageEvent struct {
-	Type      string               `json:"type"` // queued, processing, sent, failed, retrying
+	Type      string               `json:"type"` // queued, processing, sent, failed, retrying, deferred, expired
 	Message   *database.Message    `json:"message"`
 	Window    *database.TmuxWindow `json:"window"`
 	Result    *tmux.SendResult     `json:"result,omitempty"`
@@ -105,19 +122,31 @@ func NewScheduler(
 		config = DefaultConfig()
 	}
 
+	policy, err := NewPolicy(db, config.Policy, config.PacedInterval)
+	if err != nil {
+		log.Printf("Warning: %v, using the %s policy", err, PolicyFairShare)
+		policy = &fairSharePolicy{db: db}
+	}
+
 	messageSender := tmux.NewMessageSender(tmuxClient)
-	smartScheduler := NewSmartScheduler(db, messageSender, usageMonitor)
+	messageSender.SetHumanBusyGrace(config.HumanBusyGrace)
 	cronScheduler := NewCronScheduler(db, messageSender)
+	if config.Retry != nil {
+		cronScheduler.retry = config.Retry
+	}
+	cronScheduler.quietHours = config.QuietHours
 
 	return &Scheduler{
-		db:             db,
-		tmuxClient:     tmuxClient,
-		messageSender:  messageSender,
-		usageMonitor:   usageMonitor,
-		smartScheduler: smartScheduler,
-		cronScheduler:  cronScheduler,
-		config:         config,
-		stats:          &SchedulerStats{},
+		db:            db,
+		tmuxClient:    tmuxClient,
+		messageSender: messageSender,
+		usageMonitor:  usageMonitor,
+		policy:        policy,
+		cronScheduler: cronScheduler,
+		owner:         database.LeaseOwnerID(),
+		config:        config,
+		trigger:       make(chan struct{}, 1),
+		stats:         &SchedulerStats{},
 	}
 }
 
@@ -126,11 +155,13 @@ func (s *Scheduler) Initialize() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Initialize sub-schedulers
-	if s.config.SmartSchedulerEnabled {
-		if err := s.smartScheduler.Initialize(); err != nil {
-			return fmt.Errorf("failed to initialize smart scheduler: %w", err)
-		}
+	// Messages left sending by a crashed process are sent again
+	reclaimed, err := database.ReclaimExpiredLeases(s.db)
+	if err != nil {
+		return fmt.Errorf("failed to reclaim expired message leases: %w", err)
+	}
+	if reclaimed > 0 {
+		log.Printf("Reclaimed %d messages whose lease expired", reclaimed)
 	}
 
 	if s.config.CronSchedulerEnabled {
@@ -140,10 +171,7 @@ func (s *Scheduler) Initialize() error {
 	}
 
 	// Initialize stats
-	s.stats.ActiveSchedulers = []string{}
-	if s.config.SmartSchedulerEnabled {
-		s.stats.ActiveSchedulers = append(s.stats.ActiveSchedulers, "smart")
-	}
+	s.stats.ActiveSchedulers = []string{s.policy.Name()}
 	if s.config.CronSchedulerEnabled {
 		s.stats.ActiveSchedulers = append(s.stats.ActiveSchedulers, "cron")
 	}
@@ -165,13 +193,7 @@ func (s *Scheduler) Start() error {
 	s.running = true
 	s.stats.LastProcessed = time.Now()
 
-	// Start sub-schedulers
-	if s.config.SmartSchedulerEnabled {
-		if err := s.smartScheduler.Start(); err != nil {
-			return fmt.Errorf("failed to start smart scheduler: %w", err)
-		}
-	}
-
+	// Start the cron scheduler
 	if s.config.CronSchedulerEnabled {
 		if err := s.cronScheduler.Start(); err != nil {
 			return fmt.Errorf("failed to start cron scheduler: %w", err)
@@ -187,7 +209,7 @@ func (s *Scheduler) Start() error {
 	// Start stats update loop
 	go s.statsUpdateLoop()
 
-	log.Printf("Scheduler started with processing interval: %v", s.config.ProcessingInterval)
+	log.Printf("Scheduler started with %s policy and processing interval: %v", s.policy.Name(), s.config.ProcessingInterval)
 	return nil
 }
 
@@ -204,13 +226,7 @@ func (s *Scheduler) Stop() error {
 	s.cancel()
 	s.running = false
 
-	// Stop sub-schedulers
-	if s.config.SmartSchedulerEnabled {
-		if err := s.smartScheduler.Stop(); err != nil {
-			log.Printf("Error stopping smart scheduler: %v", err)
-		}
-	}
-
+	// Stop the cron scheduler
 	if s.config.CronSchedulerEnabled {
 		if err := s.cronScheduler.Stop(); err != nil {
 			log.Printf("Error stopping cron scheduler: %v", err)
@@ -221,8 +237,24 @@ func (s *Scheduler) Stop() error {
 	return nil
 }
 
-// ScheduleMessage schedules a message for delivery to a tmux window target
+// ScheduleMessage schedules a message for delivery to a tmux window target,
+// or to a route such as "any:model=opus,idle"
 func (s *Scheduler) ScheduleMessage(target, content string, scheduledTime time.Time, priority int) (*database.Message, error) {
+	return s.ScheduleMessageWithExpiry(target, content, scheduledTime, priority, nil)
+}
+
+// ScheduleMessageWithExpiry schedules a message that expires instead of being
+// sent after expiresAt. A nil expiresAt means the message never expires.
+func (s *Scheduler) ScheduleMessageWithExpiry(target, content string, scheduledTime time.Time, priority int, expiresAt *time.Time) (*database.Message, error) {
+	if expiresAt != nil && !expiresAt.After(scheduledTime) {
+		return nil, fmt.Errorf("message would expire at %s, before it is due at %s",
+			expiresAt.Format(time.RFC3339), scheduledTime.Format(time.RFC3339))
+	}
+
+	if IsRoute(target) {
+		return s.scheduleRoutedMessage(target, content, scheduledTime, priority, expiresAt)
+	}
+
 	// Get or create tmux window
 	window, err := database.GetTmuxWindow(s.db, target)
 	if err != nil {
@@ -257,11 +289,15 @@ func (s *Scheduler) ScheduleMessage(target, content string, scheduledTime time.T
 		ScheduledTime: scheduledTime,
 		Priority:      priority,
 		Status:        database.MessageStatusPending,
+		ExpiresAt:     expiresAt,
 	}
 
 	if err := s.db.Create(message).Error; err != nil {
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
+	if err := database.SyncQueueMessageCount(s.db, window.ID); err != nil {
+		log.Printf("Warning: failed to update queue count for %s: %v", target, err)
+	}
 
 	// Load window relationship
 	if err := s.db.Preload("Window").First(message, message.ID).Error; err != nil {
@@ -277,6 +313,54 @@ func (s *Scheduler) ScheduleMessage(target, content string, scheduledTime time.T
 	return message, nil
 }
 
+// scheduleRoutedMessage queues a message whose window is chosen at dispatch.
+// The message records a provisional window until then.
+func (s *Scheduler) scheduleRoutedMessage(target, content string, scheduledTime time.Time, priority int, expiresAt *time.Time) (*database.Message, error) {
+	route, err := ParseRoute(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route: %w", err)
+	}
+
+	window, err := provisionalWindow(s.db, route)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pending messages are only found through the queue of their window
+	if _, err := database.GetOrCreateWindowMessageQueue(s.db, window.ID); err != nil {
+		return nil, fmt.Errorf("failed to get queue for window %s: %w", window.Target, err)
+	}
+
+	message := &database.Message{
+		WindowID:      window.ID,
+		Content:       content,
+		ScheduledTime: scheduledTime,
+		Priority:      priority,
+		Status:        database.MessageStatusPending,
+		Route:         route.String(),
+		ExpiresAt:     expiresAt,
+	}
+
+	if err := s.db.Create(message).Error; err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+	if err := database.SyncQueueMessageCount(s.db, window.ID); err != nil {
+		log.Printf("Warning: failed to update queue count for %s: %v", window.Target, err)
+	}
+
+	// Load window relationship
+	if err := s.db.Preload("Window").First(message, message.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load message with window: %w", err)
+	}
+
+	s.emitMessageEvent("queued", message, nil, nil, 0)
+
+	log.Printf("Scheduled message for route '%s' at %v with priority %d",
+		message.Route, scheduledTime, priority)
+
+	return message, nil
+}
+
 // ScheduleMessageNow schedules a message for immediate delivery
 func (s *Scheduler) ScheduleMessageNow(target, content string, priority int) (*database.Message, error) {
 	return s.ScheduleMessage(target, content, time.Now(), priority)
@@ -302,25 +386,52 @@ func (s *Scheduler) processingLoop() {
 			return
 		case <-ticker.C:
 			s.processMessages()
+		case <-s.trigger:
+			s.processMessages()
 		}
 	}
 }
 
 // processMessages processes pending messages
 func (s *Scheduler) processMessages() {
+	// Late messages are worthless, so expire them rather than send them. This
+	// runs before the usage check so deadlines pass while usage is exhausted.
+	s.expireOverdueMessages()
+
 	// Check if we have available usage
 	availableUsage := s.usageMonitor.GetAvailableUsage()
 	if availableUsage <= 0 {
 		return // No usage available
 	}
 
-	// Get pending messages for all windows
-	limit := min(s.config.MaxConcurrentMessages, availableUsage)
-	messages, err := database.GetPendingMessagesForAllWindows(s.db, limit)
+	now := time.Now()
+	if s.holdSending(now) {
+		return
+	}
+	limit := s.policy.Budget(now, min(s.config.MaxConcurrentMessages, availableUsage))
+	if limit <= 0 {
+		return
+	}
+
+	// Get pending messages for all windows and claim them in policy order.
+	// Each window sends one message at a time.
+	pending, err := database.GetPendingMessagesForAllWindows(s.db, 0)
 	if err != nil {
 		s.emitError(fmt.Errorf("failed to get pending messages: %w", err))
 		return
 	}
+	s.policy.Order(pending)
+
+	var messages []database.Message
+	for i := range pending {
+		if len(messages) >= limit {
+			break
+		}
+		if s.claimMessage(&pending[i]) {
+			s.policy.Dispatched(&pending[i], now)
+			messages = append(messages, pending[i])
+		}
+	}
 
 	if len(messages) == 0 {
 		return // No messages to process
@@ -330,9 +441,14 @@ func (s *Scheduler) processMessages() {
 	semaphore := make(chan struct{}, s.config.MaxConcurrentMessages)
 	var wg sync.WaitGroup
 
-	for _, message := range messages {
+	for i, message := range messages {
 		select {
 		case <-s.ctx.Done():
+			// Hand back messages that were never started
+			for _, unsent := range messages[i:] {
+				s.releaseClaim(&unsent)
+			}
+			wg.Wait()
 			return
 		case semaphore <- struct{}{}:
 			wg.Add(1)
@@ -347,9 +463,63 @@ func (s *Scheduler) processMessages() {
 	wg.Wait()
 }
 
-// processMessage processes a single message
+// holdSending checks for a global pause or quiet hours and logs when they
+// start and end
+func (s *Scheduler) holdSending(now time.Time) bool {
+	hold, err := CurrentHold(s.db, s.config.QuietHours, now)
+	if err != nil {
+		s.emitError(err)
+		return false
+	}
+
+	description := ""
+	if hold != nil {
+		description = hold.String()
+	}
+	if description != s.hold {
+		if hold != nil {
+			log.Printf("Scheduler holding messages: %s", description)
+		} else {
+			log.Printf("Scheduler sending messages again")
+		}
+		s.hold = description
+	}
+	return hold != nil
+}
+
+// Hold returns the global pause or quiet period in effect, or nil
+func (s *Scheduler) Hold() (*Hold, error) {
+	return CurrentHold(s.db, s.config.QuietHours, time.Now())
+}
+
+// expireOverdueMessages moves pending messages past their deadline to expired
+func (s *Scheduler) expireOverdueMessages() {
+	expired, err := database.ExpireOverdueMessages(s.db)
+	if err != nil {
+		s.emitError(fmt.Errorf("failed to expire overdue messages: %w", err))
+		return
+	}
+
+	for i := range expired {
+		message := &expired[i]
+		s.emitMessageEvent("expired", message, nil, nil, message.Retries)
+		log.Printf("Message %d expired: deadline %s passed before it could be sent",
+			message.ID, message.ExpiresAt.Format(time.RFC3339))
+	}
+}
+
+// processMessage processes a single message whose queue was claimed with claimMessage
 func (s *Scheduler) processMessage(message *database.Message) {
+	// A deferred message was never sent, so it doesn't start the queue's spacing
+	processed := true
+	defer func() {
+		if err := database.ReleaseQueueSlot(s.db, message.WindowID, processed); err != nil {
+			s.emitError(fmt.Errorf("failed to release queue for window %d: %w", message.WindowID, err))
+		}
+	}()
+
 	s.emitMessageEvent("processing", message, nil, nil, message.Retries+1)
+	start := time.Now()
 
 	// Send the message to the window target
 	result, err := s.messageSender.SendQueuedMessage(
@@ -358,14 +528,25 @@ func (s *Scheduler) processMessage(message *database.Message) {
 		message.Priority,
 	)
 
+	// Someone is using the window, which is not the message's fault
+	if errors.Is(err, tmux.ErrHumanBusy) {
+		processed = false
+		s.deferMessage(message, err)
+		return
+	}
+
 	// Update statistics
 	s.updateMessageStats(result, err)
 
 	if err != nil {
-		s.handleMessageFailure(message, err)
+		s.handleMessageFailure(message, err, time.Since(start))
 		return
 	}
 
+	if err := RecordSentAttempt(s.db, message, result.Duration); err != nil {
+		s.emitError(fmt.Errorf("failed to record attempt for message %d: %w", message.ID, err))
+	}
+
 	// Success - update message status
 	if err := database.UpdateMessageStatus(s.db, message.ID, database.MessageStatusSent, ""); err != nil {
 		s.emitError(fmt.Errorf("failed to update message status: %w", err))
@@ -387,6 +568,83 @@ func (s *Scheduler) processMessage(message *database.Message) {
 	s.stats.TotalProcessed++
 }
 
+// claimMessage picks the window for routed messages, claims the window's queue
+// and then the message itself. It returns false when the queue is paused, busy
+// or spacing messages out, or when another scheduler claimed the message first.
+func (s *Scheduler) claimMessage(message *database.Message) bool {
+	if message.Route != "" {
+		if _, err := resolveMessageRoute(s.db, s.messageSender, message); err != nil {
+			s.deferRoutedMessage(message, err)
+			return false
+		}
+	}
+
+	acquired, err := database.AcquireQueueSlot(s.db, message.WindowID)
+	if err != nil {
+		s.emitError(fmt.Errorf("failed to claim queue for window %d: %w", message.WindowID, err))
+		return false
+	}
+	if !acquired {
+		return false
+	}
+
+	claimed, err := database.ClaimMessage(s.db, message.ID, s.owner, database.MessageLeaseDuration)
+	if err != nil || !claimed {
+		if err != nil {
+			s.emitError(fmt.Errorf("failed to claim message %d: %w", message.ID, err))
+		}
+		if err := database.ReleaseQueueSlot(s.db, message.WindowID, false); err != nil {
+			s.emitError(fmt.Errorf("failed to release queue for window %d: %w", message.WindowID, err))
+		}
+		return false
+	}
+
+	message.Status = database.MessageStatusSending
+	message.LeaseOwner = s.owner
+	return true
+}
+
+// releaseClaim hands a claimed message that was not sent back to the queue
+func (s *Scheduler) releaseClaim(message *database.Message) {
+	if err := database.ReleaseMessageLease(s.db, message.ID, s.owner); err != nil {
+		s.emitError(fmt.Errorf("failed to release message %d: %w", message.ID, err))
+	}
+	if err := database.ReleaseQueueSlot(s.db, message.WindowID, false); err != nil {
+		s.emitError(fmt.Errorf("failed to release queue for window %d: %w", message.WindowID, err))
+	}
+}
+
+// deferRoutedMessage postpones a routed message that no window can take right now.
+// This does not count as a failed attempt.
+func (s *Scheduler) deferRoutedMessage(message *database.Message, err error) {
+	if !errors.Is(err, ErrNoRouteMatch) {
+		s.handleMessageFailure(message, err, 0)
+		return
+	}
+
+	retryTime := time.Now().Add(s.config.RetryDelay)
+	if dbErr := s.db.Model(&database.Message{}).Where("id = ?", message.ID).
+		Update("scheduled_time", retryTime).Error; dbErr != nil {
+		s.emitError(fmt.Errorf("failed to defer message %d: %w", message.ID, dbErr))
+		return
+	}
+
+	log.Printf("Message %d deferred until %s: %v", message.ID, retryTime.Format(time.Kitchen), err)
+}
+
+// deferMessage hands a claimed message back to the queue until the human-busy
+// grace period has passed. This does not count as a failed attempt.
+func (s *Scheduler) deferMessage(message *database.Message, err error) {
+	retryTime := time.Now().Add(s.config.HumanBusyGrace)
+	if dbErr := database.DeferMessage(s.db, message.ID, s.owner, retryTime); dbErr != nil {
+		s.emitError(fmt.Errorf("failed to defer message %d: %w", message.ID, dbErr))
+		return
+	}
+
+	s.emitMessageEvent("deferred", message, nil, err, message.Retries)
+	log.Printf("Message %d deferred until %s: %v", message.ID, retryTime.Format(time.Kitchen), err)
+}
+
 // updateWindowActivity updates the last activity time for a window
 func (s *Scheduler) updateWindowActivity(windowID uint) error {
 	now := time.Now()
@@ -395,38 +653,35 @@ func (s *Scheduler) updateWindowActivity(windowID uint) error {
 		Update("last_activity", &now).Error
 }
 
-// handleMessageFailure handles a failed message
-func (s *Scheduler) handleMessageFailure(message *database.Message, err error) {
+// handleMessageFailure records a failed attempt and retries or gives up on
+// the message according to the retry policy for the error's class
+func (s *Scheduler) handleMessageFailure(message *database.Message, err error, duration time.Duration) {
 	s.stats.TotalFailed++
 	s.stats.TotalProcessed++
 
-	// Check if we can retry
-	if message.CanRetry() {
-		// Schedule retry
-		retryTime := time.Now().Add(s.config.RetryDelay)
-		if err := s.db.Model(message).Updates(map[string]interface{}{
-			"scheduled_time": retryTime,
-			"retries":        message.Retries + 1,
-			"error":          err.Error(),
-		}).Error; err != nil {
-			s.emitError(fmt.Errorf("failed to schedule retry: %w", err))
+	attempt, dbErr := RecordFailedAttempt(s.db, s.config.Retry, message, err, duration)
+	if dbErr != nil {
+		s.emitError(fmt.Errorf("message %d: %w", message.ID, dbErr))
+		if attempt == nil {
 			return
 		}
+	}
 
-		s.emitMessageEvent("retrying", message, nil, err, message.Retries+1)
+	switch attempt.Outcome {
+	case database.AttemptOutcomeRetry:
+		s.emitMessageEvent("retrying", message, nil, err, attempt.Attempt)
 		s.stats.TotalRetries++
-		log.Printf("Scheduled retry for message %d (attempt %d/%d)",
-			message.ID, message.Retries+1, message.MaxRetries)
-	} else {
-		// Mark as permanently failed
-		if dbErr := database.UpdateMessageStatus(s.db, message.ID, database.MessageStatusFailed, err.Error()); dbErr != nil {
-			s.emitError(fmt.Errorf("failed to mark message as failed: %w", dbErr))
-			return
-		}
-
-		s.emitMessageEvent("failed", message, nil, err, message.Retries+1)
-		log.Printf("Message %d permanently failed after %d attempts: %v",
-			message.ID, message.Retries, err)
+		log.Printf("Message %d failed (%s), retry %d/%d at %s: %v",
+			message.ID, attempt.ErrorClass, attempt.Attempt, s.config.Retry.For(attempt.ErrorClass).MaxAttempts-1,
+			attempt.NextRetryAt.Format(time.Kitchen), err)
+	case database.AttemptOutcomeDropped:
+		s.emitMessageEvent("failed", message, nil, err, attempt.Attempt)
+		log.Printf("Message %d dropped after %d attempts (%s): %v",
+			message.ID, attempt.Attempt, attempt.ErrorClass, err)
+	default:
+		s.emitMessageEvent("failed", message, nil, err, attempt.Attempt)
+		log.Printf("Message %d permanently failed after %d attempts (%s): %v",
+			message.ID, attempt.Attempt, attempt.ErrorClass, err)
 	}
 }
 
@@ -459,6 +714,18 @@ func (s *Scheduler) performHealthCheck() {
 		return
 	}
 
+	// Correct queue counts changed outside the scheduler, e.g. by the CLI
+	if err := database.SyncQueueMessageCounts(s.db); err != nil {
+		s.emitError(fmt.Errorf("failed to sync queue counts: %w", err))
+	}
+
+	// Requeue messages whose sender went away mid-send
+	if reclaimed, err := database.ReclaimExpiredLeases(s.db); err != nil {
+		s.emitError(fmt.Errorf("failed to reclaim expired message leases: %w", err))
+	} else if reclaimed > 0 {
+		log.Printf("Reclaimed %d messages whose lease expired", reclaimed)
+	}
+
 	// Check usage monitor
 	if _, err := s.usageMonitor.GetCurrentStats(); err != nil {
 		s.emitError(fmt.Errorf("usage monitor health check failed: %w", err))
@@ -523,24 +790,26 @@ func (s *Scheduler) updateMessageStats(result *tmux.SendResult, err error) {
 	}
 }
 
-// AddMessage adds a message directly to the scheduler queue
-func (s *Scheduler) AddMessage(message *database.Message) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if s.smartScheduler != nil {
-		s.smartScheduler.AddMessage(message)
+// TriggerImmediateProcessing runs a processing round now instead of waiting
+// for the next tick
+func (s *Scheduler) TriggerImmediateProcessing() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+		// A round is already pending
 	}
 }
 
-// TriggerImmediateProcessing triggers immediate message processing
-func (s *Scheduler) TriggerImmediateProcessing() {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// ResumeWindow resumes delivery of messages held while Claude was unavailable
+// in a window. They stayed pending, so a processing round picks them up.
+func (s *Scheduler) ResumeWindow(windowID uint) {
+	log.Printf("Claude is back in window %d, processing its held messages", windowID)
+	s.TriggerImmediateProcessing()
+}
 
-	if s.smartScheduler != nil {
-		s.smartScheduler.TriggerImmediateProcessing()
-	}
+// Policy returns the scheduling policy in use
+func (s *Scheduler) Policy() Policy {
+	return s.policy
 }
 
 // GetStats returns current scheduler statistics
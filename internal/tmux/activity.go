@@ -0,0 +1,105 @@
+package tmux
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WindowActivity describes what a person is doing in a window
+type WindowActivity struct {
+	ClientActivity time.Time `json:"client_activity"` // last keystroke of a client viewing the window; zero if none
+	WindowActivity time.Time `json:"window_activity"` // last output in the window
+	InMode         bool      `json:"in_mode"`         // the pane is in copy or another mode
+	Input          string    `json:"input"`           // text typed into Claude's prompt but not submitted
+}
+
+// GetWindowActivity returns the activity of the clients viewing a window. It
+// does not fill in Input, which comes from the pane content.
+func (c *Client) GetWindowActivity(target string) (*WindowActivity, error) {
+	sessionName, windowIndex, err := ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("tmux", "display-message", "-p", "-t", target, "#{window_activity}\t#{pane_in_mode}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity of %s: %w", target, err)
+	}
+	activity, err := parseWindowActivity(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity of %s: %w", target, err)
+	}
+
+	// Only clients attached to the session and showing this window can type into it
+	cmd = exec.Command("tmux", "list-clients", "-t", sessionName, "-F", "#{window_index}\t#{client_activity}")
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients of %s: %w", sessionName, err)
+	}
+	activity.ClientActivity = latestClientActivity(string(output), windowIndex)
+
+	return activity, nil
+}
+
+// parseWindowActivity parses "#{window_activity}\t#{pane_in_mode}"
+func parseWindowActivity(output string) (*WindowActivity, error) {
+	fields := strings.Split(strings.TrimSpace(output), "\t")
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected output %q", output)
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window activity %q", fields[0])
+	}
+	return &WindowActivity{
+		WindowActivity: time.Unix(seconds, 0),
+		InMode:         fields[1] == "1",
+	}, nil
+}
+
+// latestClientActivity returns the latest activity of the clients in
+// "#{window_index}\t#{client_activity}" lines that show the window
+func latestClientActivity(output string, windowIndex int) time.Time {
+	var latest time.Time
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 || fields[0] != strconv.Itoa(windowIndex) {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if activity := time.Unix(seconds, 0); activity.After(latest) {
+			latest = activity
+		}
+	}
+	return latest
+}
+
+// HumanBusy reports whether a person is using the window: a client viewing it
+// typed within the grace period and the keys showed up in the window, the pane
+// is scrolled back in copy mode, or there is unsent text in Claude's prompt.
+// The reason describes what was found.
+func (a *WindowActivity) HumanBusy(now time.Time, grace time.Duration) (string, bool) {
+	switch {
+	case a.InMode:
+		return "pane is in copy mode", true
+	case a.Input != "":
+		return "prompt has unsent input", true
+	}
+
+	// Keystrokes update both the client and the window, Claude's output only the window
+	typed := a.ClientActivity
+	if a.WindowActivity.Before(typed) {
+		typed = a.WindowActivity
+	}
+	if !typed.IsZero() && now.Sub(typed) < grace {
+		return fmt.Sprintf("keystrokes %s ago", now.Sub(typed).Round(time.Second)), true
+	}
+	return "", false
+}
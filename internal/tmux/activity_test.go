@@ -0,0 +1,60 @@
+package tmux
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatestClientActivity(t *testing.T) {
+	output := "1\t1700000100\n2\t1700000500\n1\t1700000300\n"
+
+	if got := latestClientActivity(output, 1); !got.Equal(time.Unix(1700000300, 0)) {
+		t.Errorf("latestClientActivity(window 1) = %v, expected the later of its two clients", got)
+	}
+	if got := latestClientActivity(output, 3); !got.IsZero() {
+		t.Errorf("latestClientActivity(window 3) = %v, expected zero for a window nobody views", got)
+	}
+
+	activity, err := parseWindowActivity("1700000200\t1\n")
+	if err != nil {
+		t.Fatalf("parseWindowActivity() error: %v", err)
+	}
+	if !activity.WindowActivity.Equal(time.Unix(1700000200, 0)) || !activity.InMode {
+		t.Errorf("parseWindowActivity() = %+v", activity)
+	}
+	if _, err := parseWindowActivity("no server"); err == nil {
+		t.Error("parseWindowActivity() accepted malformed output")
+	}
+}
+
+func TestWindowActivityHumanBusy(t *testing.T) {
+	now := time.Unix(1700001000, 0)
+	grace := time.Minute
+
+	tests := []struct {
+		name     string
+		activity WindowActivity
+		expected bool
+	}{
+		{"nobody attached", WindowActivity{WindowActivity: now.Add(-5 * time.Second)}, false},
+		{"recent keystrokes", WindowActivity{ClientActivity: now.Add(-10 * time.Second), WindowActivity: now.Add(-9 * time.Second)}, true},
+		{"old keystrokes, Claude still printing", WindowActivity{ClientActivity: now.Add(-10 * time.Minute), WindowActivity: now.Add(-time.Second)}, false},
+		{"typing in another window", WindowActivity{ClientActivity: now.Add(-time.Second), WindowActivity: now.Add(-time.Hour)}, false},
+		{"copy mode", WindowActivity{InMode: true}, true},
+		{"unsent input", WindowActivity{Input: "half a thought"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason, busy := tt.activity.HumanBusy(now, grace); busy != tt.expected {
+				t.Errorf("HumanBusy() = %v (%s), expected %v", busy, reason, tt.expected)
+			}
+		})
+	}
+
+	// The reason reports how long ago the keys reached the window, not the client
+	activity := WindowActivity{ClientActivity: now.Add(-time.Second), WindowActivity: now.Add(-20 * time.Second)}
+	if reason, _ := activity.HumanBusy(now, grace); reason != "keystrokes 20s ago" {
+		t.Errorf("HumanBusy() reason = %q, expected %q", reason, "keystrokes 20s ago")
+	}
+}
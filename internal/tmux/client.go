@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -167,11 +168,14 @@ func (c *Client) ValidateTarget(target string) error {
 	}
 
 	if !c.SessionExists(sessionName) {
-		return fmt.Errorf("session '%s' does not exist", sessionName)
+		if !c.IsRunning() {
+			return ErrTmuxNotRunning
+		}
+		return fmt.Errorf("%w: session '%s' does not exist", ErrTargetNotFound, sessionName)
 	}
 
 	if !c.WindowExists(sessionName, windowIndex) {
-		return fmt.Errorf("window %d does not exist in session '%s'", windowIndex, sessionName)
+		return fmt.Errorf("%w: window %d does not exist in session '%s'", ErrTargetNotFound, windowIndex, sessionName)
 	}
 
 	return nil
@@ -200,18 +204,31 @@ func (c *Client) GetWindowInfo(target string) (*WindowInfo, error) {
 
 // CapturePane captures the content of a tmux pane
 func (c *Client) CapturePane(target string, numLines int) (string, error) {
+	return c.capturePane(target, numLines, false)
+}
+
+// CapturePaneANSI captures the content of a tmux pane with its colors and
+// attributes as ANSI escape sequences
+func (c *Client) CapturePaneANSI(target string, numLines int) (string, error) {
+	return c.capturePane(target, numLines, true)
+}
+
+// capturePane captures a pane, starting numLines into the history (0 for the
+// visible part only)
+func (c *Client) capturePane(target string, numLines int, escapes bool) (string, error) {
 	if err := c.ValidateTarget(target); err != nil {
 		return "", err
 	}
 
-	var cmd *exec.Cmd
+	args := []string{"capture-pane", "-t", target, "-p"}
+	if escapes {
+		args = append(args, "-e")
+	}
 	if numLines > 0 {
-		cmd = exec.Command("tmux", "capture-pane", "-t", target, "-p", "-S", fmt.Sprintf("-%d", numLines))
-	} else {
-		cmd = exec.Command("tmux", "capture-pane", "-t", target, "-p")
+		args = append(args, "-S", fmt.Sprintf("-%d", numLines))
 	}
 
-	output, err := cmd.Output()
+	output, err := exec.Command("tmux", args...).Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to capture pane content: %w", err)
 	}
@@ -233,6 +250,27 @@ func (c *Client) SendKeys(target string, keys string) error {
 	return nil
 }
 
+// PasteText pastes text into a tmux window as a bracketed paste, so that
+// newlines become part of the input instead of submitting it
+func (c *Client) PasteText(target, text string) error {
+	if err := c.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	load := exec.Command("tmux", "load-buffer", "-b", "tcs-message", "-")
+	load.Stdin = strings.NewReader(text)
+	if err := load.Run(); err != nil {
+		return fmt.Errorf("failed to load message into a tmux buffer: %w", err)
+	}
+
+	paste := exec.Command("tmux", "paste-buffer", "-p", "-d", "-b", "tcs-message", "-t", target)
+	if err := paste.Run(); err != nil {
+		return fmt.Errorf("failed to paste message into %s: %w", target, err)
+	}
+
+	return nil
+}
+
 // DiscoverClaudeSessions attempts to discover tmux sessions that might contain Claude
 func (c *Client) DiscoverClaudeSessions() ([]WindowInfo, error) {
 	sessions, err := c.ListSessions()
@@ -241,17 +279,17 @@ func (c *Client) DiscoverClaudeSessions() ([]WindowInfo, error) {
 	}
 
 	var claudeWindows []WindowInfo
+	rules := utils.ConfiguredRuleSet()
 
 	for _, session := range sessions {
 		for _, window := range session.Windows {
-			// Try to capture content and look for Claude indicators
-			content, err := c.CapturePane(window.Target, 50)
+			// Score the window's content and pane command with the detection rules
+			result, err := c.EvaluateDetectionRules(window.Target, rules)
 			if err != nil {
 				continue
 			}
 
-			// Look for Claude indicators in the content
-			if c.isClaudeWindow(content) {
+			if result.IsClaude {
 				claudeWindows = append(claudeWindows, window)
 			}
 		}
@@ -260,13 +298,20 @@ func (c *Client) DiscoverClaudeSessions() ([]WindowInfo, error) {
 	return claudeWindows, nil
 }
 
-// isClaudeWindow checks if window content indicates a Claude session
-func (c *Client) isClaudeWindow(content string) bool {
-	return utils.IsClaudeWindow(content)
+// MonitorOptions controls what MonitorWindowContext captures
+type MonitorOptions struct {
+	Lines int  // lines of history to include; 0 for the visible part only
+	ANSI  bool // keep colors as ANSI escape sequences
 }
 
 // MonitorWindow monitors a window for changes and returns a channel of updates
 func (c *Client) MonitorWindow(target string, interval time.Duration) (<-chan string, error) {
+	return c.MonitorWindowContext(context.Background(), target, interval, MonitorOptions{Lines: 10})
+}
+
+// MonitorWindowContext monitors a window until ctx is done, sending the pane
+// content whenever it changes. The channel is closed when monitoring stops.
+func (c *Client) MonitorWindowContext(ctx context.Context, target string, interval time.Duration, opts MonitorOptions) (<-chan string, error) {
 	if err := c.ValidateTarget(target); err != nil {
 		return nil, err
 	}
@@ -282,16 +327,22 @@ func (c *Client) MonitorWindow(target string, interval time.Duration) (<-chan st
 
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case <-ticker.C:
-				content, err := c.CapturePane(target, 10)
+				content, err := c.capturePane(target, opts.Lines, opts.ANSI)
 				if err != nil {
 					log.Printf("Error monitoring window %s: %v", target, err)
 					continue
 				}
 
 				if content != lastContent {
-					updates <- content
-					lastContent = content
+					select {
+					case updates <- content:
+						lastContent = content
+					case <-ctx.Done():
+						return
+					}
 				}
 			}
 		}
@@ -300,6 +351,31 @@ func (c *Client) MonitorWindow(target string, interval time.Duration) (<-chan st
 	return updates, nil
 }
 
+// SwitchClient makes the tmux client running tcs show a window. It only
+// works when tcs itself runs inside tmux; see InsideTmux and AttachCommand.
+func (c *Client) SwitchClient(target string) error {
+	if err := c.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("tmux", "switch-client", "-t", target)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to switch to %s: %s", target, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// InsideTmux reports whether tcs runs inside a tmux client
+func InsideTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// AttachCommand returns the command that attaches the terminal to a window,
+// for use outside tmux where there is no client to switch
+func AttachCommand(target string) *exec.Cmd {
+	return exec.Command("tmux", "attach-session", "-t", target)
+}
+
 // GetActiveWindow returns the currently active window in a session
 func (c *Client) GetActiveWindow(sessionName string) (*WindowInfo, error) {
 	windows, err := c.ListWindows(sessionName)
@@ -318,9 +394,43 @@ func (c *Client) GetActiveWindow(sessionName string) (*WindowInfo, error) {
 
 // CreateSession creates a new tmux session
 func (c *Client) CreateSession(sessionName string) error {
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName)
+	_, err := c.createSession(sessionName, "", "")
+	return err
+}
+
+// createSession creates a detached session whose first window has the given
+// name and working directory, if set, and returns the index of that window
+func (c *Client) createSession(sessionName, windowName, workingDir string) (int, error) {
+	args := []string{"new-session", "-d", "-s", sessionName}
+	if windowName != "" {
+		args = append(args, "-n", windowName)
+	}
+	if workingDir != "" {
+		args = append(args, "-c", workingDir)
+	}
+	args = append(args, "-P", "-F", "#{window_index}")
+
+	output, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create session '%s': %w", sessionName, err)
+	}
+	return parseWindowIndex(output)
+}
+
+// parseWindowIndex parses the window index printed by new-session or new-window -P
+func parseWindowIndex(output []byte) (int, error) {
+	windowIndex, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse new window index %q: %w", strings.TrimSpace(string(output)), err)
+	}
+	return windowIndex, nil
+}
+
+// DisplayMessage shows a message in the status line of clients attached to the target's session
+func (c *Client) DisplayMessage(target, message string) error {
+	cmd := exec.Command("tmux", "display-message", "-t", target, message)
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to create session '%s': %w", sessionName, err)
+		return fmt.Errorf("failed to display message in %s: %w", target, err)
 	}
 
 	return nil
@@ -363,6 +473,23 @@ func (c *Client) DetectClaudeProcessWithNames(target string, processNames []stri
 		return false, fmt.Errorf("no pane PID found")
 	}
 
+	// Walk the in-memory process tree when /proc is available
+	if ProcessTreeSupported() {
+		tree, err := ReadProcessTree()
+		if err == nil {
+			for _, line := range strings.Split(panePID, "\n") {
+				pid, err := strconv.Atoi(strings.TrimSpace(line))
+				if err != nil {
+					continue
+				}
+				if findClaudeInPane(tree, pid, processNames) != nil {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+
 	// Check for the specified processes
 	for _, processName := range processNames {
 		// Check if the process is running under this pane's process tree
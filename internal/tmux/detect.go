@@ -0,0 +1,120 @@
+package tmux
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/derekxwang/tcs/internal/utils"
+)
+
+// Claude detection methods
+const (
+	DetectionMethodProcess = "process" // process tree only
+	DetectionMethodText    = "text"    // detection rules only
+	DetectionMethodBoth    = "both"    // process tree first, then detection rules
+)
+
+// GetPaneCommand returns the command running in the foreground of a window's active pane
+func (c *Client) GetPaneCommand(target string) (string, error) {
+	cmd := exec.Command("tmux", "display-message", "-p", "-t", target, "#{pane_current_command}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get pane command for %s: %w", target, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// EvaluateDetectionRules scores a window's content and pane command against detection rules
+func (c *Client) EvaluateDetectionRules(target string, rules *utils.RuleSet) (*utils.DetectionResult, error) {
+	content, err := c.CapturePane(target, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	paneCommand, err := c.GetPaneCommand(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.Evaluate(content, paneCommand), nil
+}
+
+// ClaudeDetector detects Claude across many windows. The process table is read
+// once when the detector is created, so one detector should be used per scan.
+type ClaudeDetector struct {
+	client       *Client
+	method       string
+	processNames []string
+	rules        *utils.RuleSet
+	processes    map[string]*ClaudeProcess // nil when the scan failed
+}
+
+// NewClaudeDetector creates a detector, scanning all panes for Claude processes
+// unless the method is text-only
+func (c *Client) NewClaudeDetector(method string, processNames []string, rules *utils.RuleSet) *ClaudeDetector {
+	d := &ClaudeDetector{
+		client:       c,
+		method:       method,
+		processNames: processNames,
+		rules:        rules,
+	}
+
+	if method != DetectionMethodText {
+		processes, err := c.ScanClaudeProcesses(processNames)
+		if err == nil {
+			d.processes = processes
+		}
+	}
+
+	return d
+}
+
+// Detect reports whether Claude runs in a window. The Claude process is returned
+// when it was found through the process tree.
+func (d *ClaudeDetector) Detect(target string) (bool, *ClaudeProcess) {
+	// Process detection is the most reliable signal for Claude Code
+	if d.method != DetectionMethodText {
+		if d.processes != nil {
+			if process, exists := d.processes[target]; exists {
+				return true, process
+			}
+		} else if detected, err := d.client.DetectClaudeProcessWithNames(target, d.processNames); err == nil && detected {
+			return true, nil
+		}
+		if d.method == DetectionMethodProcess {
+			return false, nil
+		}
+	}
+
+	// Rule-based detection
+	result, err := d.client.EvaluateDetectionRules(target, d.rules)
+	if err != nil {
+		return false, nil
+	}
+	return result.IsClaude, nil
+}
+
+// DetectClaude reports whether Claude runs in a window using the given detection method.
+// Unknown methods behave like "both". Use a ClaudeDetector when checking many windows.
+func (c *Client) DetectClaude(target, method string, processNames []string, rules *utils.RuleSet) bool {
+	detected, _ := c.NewClaudeDetector(method, processNames, rules).Detect(target)
+	return detected
+}
+
+// ReadClaudeStatus reads the model, permission mode and version of a Claude
+// session from its process arguments (if known) and the window's status line
+func (c *Client) ReadClaudeStatus(target string, process *ClaudeProcess) utils.ClaudeStatus {
+	var fromArgs utils.ClaudeStatus
+	if process != nil {
+		fromArgs = utils.ParseClaudeArgs(process.Args)
+	}
+
+	var fromScreen utils.ClaudeStatus
+	if content, err := c.CapturePane(target, 50); err == nil {
+		fromScreen = utils.ParseClaudeScreen(content)
+	}
+
+	return utils.MergeClaudeStatus(fromArgs, fromScreen)
+}
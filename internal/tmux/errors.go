@@ -0,0 +1,16 @@
+package tmux
+
+import "errors"
+
+// Errors returned when a message cannot be delivered. They are wrapped with
+// details, so check them with errors.Is.
+var (
+	// ErrTmuxNotRunning means there is no tmux server to talk to
+	ErrTmuxNotRunning = errors.New("tmux server is not running")
+	// ErrTargetNotFound means the session or window of a target does not exist
+	ErrTargetNotFound = errors.New("tmux target not found")
+	// ErrPaneBusy means Claude is still working in the target pane
+	ErrPaneBusy = errors.New("pane is busy")
+	// ErrHumanBusy means someone is typing in or scrolling the target window
+	ErrHumanBusy = errors.New("window is in use")
+)
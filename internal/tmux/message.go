@@ -5,11 +5,14 @@ import (
 	"log"
 	"strings"
 	"time"
+
+	"github.com/derekxwang/tcs/internal/utils"
 )
 
 // MessageSender handles sending messages to tmux windows with proper timing
 type MessageSender struct {
-	client *Client
+	client         *Client
+	humanBusyGrace time.Duration // defer messages while someone is using the window; 0 disables the check
 }
 
 // NewMessageSender creates a new message sender
@@ -19,6 +22,17 @@ func NewMessageSender(client *Client) *MessageSender {
 	}
 }
 
+// SetHumanBusyGrace sets how recent keystrokes in a window must be for
+// SendQueuedMessage to leave it alone. Zero disables the check.
+func (ms *MessageSender) SetHumanBusyGrace(grace time.Duration) {
+	ms.humanBusyGrace = grace
+}
+
+// HumanBusyGrace returns the grace period set with SetHumanBusyGrace
+func (ms *MessageSender) HumanBusyGrace() time.Duration {
+	return ms.humanBusyGrace
+}
+
 // SendResult represents the result of sending a message
 type SendResult struct {
 	Target        string        `json:"target"`
@@ -57,8 +71,13 @@ func (ms *MessageSender) SendMessage(target, message string) (*SendResult, error
 	}
 
 	// CRITICAL: Send message with proper delay (exactly as in send-claude-message.sh)
-	// Step 1: Send the message text
-	if err := ms.client.SendKeys(target, message); err != nil {
+	// Step 1: Send the message text. Multi-line messages are pasted so their
+	// newlines do not submit the first line on its own.
+	send := ms.client.SendKeys
+	if strings.Contains(message, "\n") {
+		send = ms.client.PasteText
+	}
+	if err := send(target, message); err != nil {
 		result.Error = fmt.Sprintf("failed to send message: %v", err)
 		result.Duration = time.Since(start)
 		return result, err
@@ -92,33 +111,6 @@ func (ms *MessageSender) SendMessage(target, message string) (*SendResult, error
 	return result, nil
 }
 
-// SendMessageWithRetry sends a message with retry logic
-func (ms *MessageSender) SendMessageWithRetry(target, message string, maxRetries int) (*SendResult, error) {
-	var lastResult *SendResult
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt*attempt) * time.Second
-			log.Printf("Retrying message send to %s after %v (attempt %d/%d)", target, backoff, attempt+1, maxRetries+1)
-			time.Sleep(backoff)
-		}
-
-		result, err := ms.SendMessage(target, message)
-		lastResult = result
-		lastErr = err
-
-		if err == nil && result.Success {
-			return result, nil
-		}
-
-		log.Printf("Message send attempt %d failed: %v", attempt+1, err)
-	}
-
-	return lastResult, fmt.Errorf("failed after %d attempts: %w", maxRetries+1, lastErr)
-}
-
 // SendClaudeMessage sends a message specifically formatted for Claude
 func (ms *MessageSender) SendClaudeMessage(target, message string) (*SendResult, error) {
 	// Ensure the message is properly formatted for Claude
@@ -237,6 +229,16 @@ func (ms *MessageSender) IsClaudeResponding(target string) (bool, error) {
 	return content1 != content2, nil
 }
 
+// IsClaudeIdle checks the status line of a window for signs that Claude is working
+func (ms *MessageSender) IsClaudeIdle(target string) (bool, error) {
+	content, err := ms.client.CapturePane(target, 10)
+	if err != nil {
+		return false, err
+	}
+
+	return !utils.ParseClaudeScreen(content).Busy, nil
+}
+
 // WaitForClaudeReady waits for Claude to be ready to receive messages
 func (ms *MessageSender) WaitForClaudeReady(target string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
@@ -260,23 +262,58 @@ func (ms *MessageSender) WaitForClaudeReady(target string, timeout time.Duration
 	return fmt.Errorf("Claude not ready after %v", timeout)
 }
 
-// SendQueuedMessage sends a message and handles common error cases
+// CheckHumanBusy reports whether someone is typing in, scrolling or drafting
+// a prompt in a window, along with what was found
+func (ms *MessageSender) CheckHumanBusy(target string) (string, bool, error) {
+	activity, err := ms.client.GetWindowActivity(target)
+	if err != nil {
+		return "", false, err
+	}
+
+	content, err := ms.client.CapturePane(target, 10)
+	if err != nil {
+		return "", false, err
+	}
+	activity.Input = utils.ParseClaudeInput(content)
+
+	reason, busy := activity.HumanBusy(time.Now(), ms.humanBusyGrace)
+	return reason, busy, nil
+}
+
+// SendQueuedMessage sends a queued message once. It fails with ErrHumanBusy
+// when someone is using the window and with ErrPaneBusy when Claude is still
+// working; retrying is up to the caller.
 func (ms *MessageSender) SendQueuedMessage(target, message string, priority int) (*SendResult, error) {
 	// Validate target first
 	if err := ms.client.ValidateTarget(target); err != nil {
 		return nil, fmt.Errorf("invalid target: %w", err)
 	}
 
-	// Wait for Claude to be ready if it's a high priority message
-	if priority >= 8 {
-		if err := ms.WaitForClaudeReady(target, 30*time.Second); err != nil {
-			log.Printf("Warning: proceeding with message send despite Claude not being ready: %v", err)
+	// Never type into the middle of someone's own input, whatever the priority
+	if ms.humanBusyGrace > 0 {
+		reason, busy, err := ms.CheckHumanBusy(target)
+		if err != nil {
+			log.Printf("Warning: could not check whether %s is in use: %v", target, err)
+		} else if busy {
+			return nil, fmt.Errorf("%w: %s in %s", ErrHumanBusy, reason, target)
+		}
+	}
+
+	idle, err := ms.IsClaudeIdle(target)
+	if err != nil {
+		log.Printf("Warning: could not check whether Claude is busy in %s: %v", target, err)
+		idle = true
+	}
+
+	// High priority messages wait a little for Claude instead of going back to the queue
+	if !idle && priority >= 8 {
+		if err := ms.WaitForClaudeReady(target, 30*time.Second); err == nil {
+			idle = true
 		}
 	}
 
-	// Send the message with retry for high priority messages
-	if priority >= 7 {
-		return ms.SendMessageWithRetry(target, message, 2)
+	if !idle {
+		return nil, fmt.Errorf("%w: Claude is working in %s", ErrPaneBusy, target)
 	}
 
 	return ms.SendMessage(target, message)
@@ -0,0 +1,334 @@
+package tmux
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is USER_HZ, the unit of the start time in /proc/<pid>/stat.
+// It assumes 'getconf CLK_TCK' is 100, as on all mainstream Linux platforms;
+// Go can't read sysconf without cgo.
+const clockTicksPerSecond = 100
+
+// ProcessInfo describes a process read from /proc
+type ProcessInfo struct {
+	PID       int       `json:"pid"`
+	PPID      int       `json:"ppid"`
+	Name      string    `json:"name"` // comm
+	Args      []string  `json:"args"` // argv from cmdline
+	StartTime time.Time `json:"start_time"`
+}
+
+// ProcessTree is an in-memory snapshot of the process table
+type ProcessTree struct {
+	processes map[int]*ProcessInfo
+	children  map[int][]int
+}
+
+// ProcessTreeSupported reports whether process trees can be read natively on this platform
+func ProcessTreeSupported() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	_, err := os.Stat("/proc/self/stat")
+	return err == nil
+}
+
+// ReadProcessTree reads /proc once and builds the process tree
+func ReadProcessTree() (*ProcessTree, error) {
+	return readProcessTree("/proc")
+}
+
+// readProcessTree builds a process tree from a proc filesystem root
+func readProcessTree(procRoot string) (*ProcessTree, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procRoot, err)
+	}
+
+	bootTime, err := readBootTime(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &ProcessTree{
+		processes: make(map[int]*ProcessInfo),
+		children:  make(map[int][]int),
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue // Not a process directory
+		}
+
+		stat, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "stat"))
+		if err != nil {
+			continue // Process exited while scanning
+		}
+
+		info, startTicks, err := parseProcStat(string(stat))
+		if err != nil || info.PID != pid {
+			continue
+		}
+		info.StartTime = bootTime.Add(time.Duration(startTicks) * time.Second / clockTicksPerSecond)
+
+		// Kernel threads have an empty cmdline
+		if cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline")); err == nil {
+			info.Args = parseCmdline(cmdline)
+		}
+
+		tree.processes[pid] = info
+		tree.children[info.PPID] = append(tree.children[info.PPID], pid)
+	}
+
+	return tree, nil
+}
+
+// readBootTime reads the system boot time from /proc/stat
+func readBootTime(statPath string) (time.Time, error) {
+	data, err := os.ReadFile(statPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read %s: %w", statPath, err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "btime ") {
+			continue
+		}
+		seconds, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "btime ")), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid btime in %s: %w", statPath, err)
+		}
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("btime not found in %s", statPath)
+}
+
+// parseProcStat parses the contents of /proc/<pid>/stat.
+// The command name is enclosed in parentheses and may itself contain spaces
+// and parentheses, so fields are located relative to the last ')'.
+func parseProcStat(stat string) (*ProcessInfo, uint64, error) {
+	open := strings.IndexByte(stat, '(')
+	closing := strings.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return nil, 0, fmt.Errorf("malformed stat line")
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(stat[:open]))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid pid in stat line: %w", err)
+	}
+
+	// Fields after the command name start at field 3 (state)
+	fields := strings.Fields(stat[closing+1:])
+	if len(fields) < 20 {
+		return nil, 0, fmt.Errorf("stat line has too few fields")
+	}
+
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid ppid in stat line: %w", err)
+	}
+
+	// starttime is field 22, which is index 19 after the command name
+	startTicks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid starttime in stat line: %w", err)
+	}
+
+	return &ProcessInfo{
+		PID:  pid,
+		PPID: ppid,
+		Name: stat[open+1 : closing],
+	}, startTicks, nil
+}
+
+// parseCmdline splits a NUL-separated /proc/<pid>/cmdline into arguments
+func parseCmdline(data []byte) []string {
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) == 0 {
+		return nil
+	}
+
+	parts := bytes.Split(data, []byte{0})
+	args := make([]string, len(parts))
+	for i, part := range parts {
+		args[i] = string(part)
+	}
+	return args
+}
+
+// Get returns the process with the given PID
+func (pt *ProcessTree) Get(pid int) *ProcessInfo {
+	return pt.processes[pid]
+}
+
+// Len returns the number of processes in the tree
+func (pt *ProcessTree) Len() int {
+	return len(pt.processes)
+}
+
+// FindDescendant returns the first process in the subtree rooted at rootPID
+// (including the root itself) that satisfies match, searching breadth first
+func (pt *ProcessTree) FindDescendant(rootPID int, match func(*ProcessInfo) bool) *ProcessInfo {
+	queue := []int{rootPID}
+	visited := make(map[int]bool)
+
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+
+		if visited[pid] {
+			continue
+		}
+		visited[pid] = true
+
+		if process, exists := pt.processes[pid]; exists && match(process) {
+			return process
+		}
+		queue = append(queue, pt.children[pid]...)
+	}
+
+	return nil
+}
+
+// scriptRuntimes run Claude from a script given as their first argument
+var scriptRuntimes = map[string]bool{"node": true, "bun": true}
+
+// matchesProcessName reports whether a process looks like one of the given programs.
+// Besides the command name, the executable is checked, and for node and bun
+// the script path, so that "node .../claude-code/cli.js" matches "claude-code".
+// Other programs' arguments are ignored: "vim ~/src/claude/x.go" is not Claude.
+func matchesProcessName(process *ProcessInfo, names []string) bool {
+	var executable, script string
+	if len(process.Args) > 0 {
+		executable = filepath.Base(process.Args[0])
+		if len(process.Args) > 1 && scriptRuntimes[executable] {
+			script = process.Args[1]
+		}
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if process.Name == name || executable == name {
+			return true
+		}
+		if script != "" && (filepath.Base(script) == name || strings.Contains(script, "/"+name+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// PaneInfo describes a tmux pane
+type PaneInfo struct {
+	Target    string `json:"target"` // "session:window" of the pane's window
+	PaneIndex int    `json:"pane_index"`
+	PID       int    `json:"pid"`
+	Command   string `json:"command"` // #{pane_current_command}
+}
+
+// ClaudeProcess describes a Claude process found in a tmux window
+type ClaudeProcess struct {
+	Target    string    `json:"target"`
+	PID       int       `json:"pid"`        // 0 when the process tree is unavailable
+	StartTime time.Time `json:"start_time"` // zero when the process tree is unavailable
+	Args      []string  `json:"args"`
+}
+
+// ListPanes returns all panes across all sessions
+func (c *Client) ListPanes() ([]PaneInfo, error) {
+	cmd := exec.Command("tmux", "list-panes", "-a", "-F",
+		"#{session_name}:#{window_index}\t#{pane_index}\t#{pane_pid}\t#{pane_current_command}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panes: %w", err)
+	}
+
+	var panes []PaneInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 4 {
+			continue
+		}
+
+		paneIndex, _ := strconv.Atoi(parts[1])
+		pid, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+
+		panes = append(panes, PaneInfo{
+			Target:    parts[0],
+			PaneIndex: paneIndex,
+			PID:       pid,
+			Command:   parts[3],
+		})
+	}
+
+	return panes, nil
+}
+
+// ScanClaudeProcesses finds Claude processes in all tmux panes, keyed by window target.
+// On Linux the process table is read once from /proc; elsewhere each window is
+// checked with pgrep and no PID or arguments are reported.
+func (c *Client) ScanClaudeProcesses(processNames []string) (map[string]*ClaudeProcess, error) {
+	panes, err := c.ListPanes()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*ClaudeProcess)
+
+	if !ProcessTreeSupported() {
+		for _, pane := range panes {
+			if _, exists := found[pane.Target]; exists {
+				continue
+			}
+			detected, err := c.DetectClaudeProcessWithNames(pane.Target, processNames)
+			if err == nil && detected {
+				found[pane.Target] = &ClaudeProcess{Target: pane.Target}
+			}
+		}
+		return found, nil
+	}
+
+	tree, err := ReadProcessTree()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pane := range panes {
+		if _, exists := found[pane.Target]; exists {
+			continue // Another pane of this window already runs Claude
+		}
+		if process := findClaudeInPane(tree, pane.PID, processNames); process != nil {
+			found[pane.Target] = &ClaudeProcess{
+				Target:    pane.Target,
+				PID:       process.PID,
+				StartTime: process.StartTime,
+				Args:      process.Args,
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// findClaudeInPane returns the Claude process running under a pane's shell
+func findClaudeInPane(tree *ProcessTree, panePID int, processNames []string) *ProcessInfo {
+	return tree.FindDescendant(panePID, func(process *ProcessInfo) bool {
+		return matchesProcessName(process, processNames)
+	})
+}
@@ -0,0 +1,124 @@
+package tmux
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	stat := "4242 (my (odd) name) S 100 4242 100 34816 4242 4194304 1 0 0 0 0 0 0 0 20 0 1 0 12345 0 0\n"
+
+	info, startTicks, err := parseProcStat(stat)
+	if err != nil {
+		t.Fatalf("parseProcStat() error = %v", err)
+	}
+	if info.PID != 4242 || info.PPID != 100 {
+		t.Errorf("expected pid 4242 ppid 100, got pid %d ppid %d", info.PID, info.PPID)
+	}
+	if info.Name != "my (odd) name" {
+		t.Errorf("expected name with parentheses, got %q", info.Name)
+	}
+	if startTicks != 12345 {
+		t.Errorf("expected start ticks 12345, got %d", startTicks)
+	}
+
+	if _, _, err := parseProcStat("garbage"); err == nil {
+		t.Error("expected error for malformed stat line")
+	}
+}
+
+func TestParseCmdline(t *testing.T) {
+	args := parseCmdline([]byte("node\x00/usr/lib/claude-code/cli.js\x00--model\x00opus\x00"))
+	expected := []string{"node", "/usr/lib/claude-code/cli.js", "--model", "opus"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("parseCmdline() = %q, expected %q", args, expected)
+	}
+
+	if args := parseCmdline(nil); args != nil {
+		t.Errorf("expected nil args for empty cmdline, got %q", args)
+	}
+}
+
+// writeFakeProcess creates /proc/<pid>/stat and cmdline entries under root
+func writeFakeProcess(t *testing.T, root string, pid, ppid int, name string, startTicks int, args ...string) {
+	t.Helper()
+
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stat := strconv.Itoa(pid) + " (" + name + ") S " + strconv.Itoa(ppid) +
+		" 0 0 0 0 0 0 0 0 0 0 0 0 0 20 0 1 0 " + strconv.Itoa(startTicks) + " 0 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cmdline []byte
+	for _, arg := range args {
+		cmdline = append(cmdline, arg...)
+		cmdline = append(cmdline, 0)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), cmdline, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadProcessTreeFindsClaude(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "stat"), []byte("cpu 1 2 3\nbtime 1700000000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFakeProcess(t, root, 1, 0, "init", 1, "/sbin/init")
+	writeFakeProcess(t, root, 100, 1, "bash", 500, "-bash")
+	writeFakeProcess(t, root, 101, 100, "node", 1500, "node", "/usr/lib/node_modules/@anthropic-ai/claude-code/cli.js", "--resume")
+	writeFakeProcess(t, root, 200, 1, "bash", 600, "-bash")
+	writeFakeProcess(t, root, 201, 200, "vim", 700, "vim", "claude-code/notes.md")
+	writeFakeProcess(t, root, 300, 1, "bash", 800, "-bash")
+	writeFakeProcess(t, root, 301, 300, "vim", 900, "vim", "/home/dev/src/claude/main.go")
+	writeFakeProcess(t, root, 302, 300, "less", 901, "less", "/opt/claude/log")
+	writeFakeProcess(t, root, 400, 1, "bash", 1000, "-bash")
+	writeFakeProcess(t, root, 401, 400, "bun", 1100, "/usr/local/bin/bun", "/opt/claude/cli.js")
+
+	tree, err := readProcessTree(root)
+	if err != nil {
+		t.Fatalf("readProcessTree() error = %v", err)
+	}
+	if tree.Len() != 10 {
+		t.Fatalf("expected 10 processes, got %d", tree.Len())
+	}
+
+	names := []string{"claude-code", "claude"}
+
+	process := findClaudeInPane(tree, 100, names)
+	if process == nil || process.PID != 101 {
+		t.Fatalf("expected Claude process 101 under pane 100, got %+v", process)
+	}
+	if process.Args[2] != "--resume" {
+		t.Errorf("unexpected args: %q", process.Args)
+	}
+	expectedStart := time.Unix(1700000015, 0)
+	if !process.StartTime.Equal(expectedStart) {
+		t.Errorf("expected start time %v, got %v", expectedStart, process.StartTime)
+	}
+
+	// Mentioning Claude in an argument is not enough
+	if process := findClaudeInPane(tree, 200, names); process != nil {
+		t.Errorf("expected no Claude process under pane 200, got %+v", process)
+	}
+
+	// Nor is opening a file in a directory named like Claude
+	if process := findClaudeInPane(tree, 300, names); process != nil {
+		t.Errorf("expected no Claude process under pane 300, got %+v", process)
+	}
+
+	// A script run by bun from such a directory is Claude
+	if process := findClaudeInPane(tree, 400, names); process == nil || process.PID != 401 {
+		t.Errorf("expected Claude process 401 under pane 400, got %+v", process)
+	}
+}
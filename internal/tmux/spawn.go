@@ -0,0 +1,156 @@
+package tmux
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/derekxwang/tcs/internal/utils"
+)
+
+// DefaultClaudeCommand is the command used to start Claude Code in a spawned window
+const DefaultClaudeCommand = "claude"
+
+// SpawnOptions describes a Claude window to be launched
+type SpawnOptions struct {
+	SessionName    string
+	WindowName     string
+	WorkingDir     string
+	Command        string        // Command that starts Claude (defaults to DefaultClaudeCommand)
+	StartupTimeout time.Duration // How long to wait for the Claude prompt
+}
+
+// CreateWindow creates a new window in the given session and returns its info.
+// The session is created if it does not exist yet.
+func (c *Client) CreateWindow(sessionName, windowName, workingDir string) (*WindowInfo, error) {
+	if sessionName == "" {
+		return nil, fmt.Errorf("session name cannot be empty")
+	}
+
+	if !c.SessionExists(sessionName) {
+		windowIndex, err := c.createSession(sessionName, windowName, workingDir)
+		if err != nil {
+			return nil, err
+		}
+		return c.GetWindowInfo(fmt.Sprintf("%s:%d", sessionName, windowIndex))
+	}
+
+	args := []string{"new-window", "-d", "-t", sessionName + ":"}
+	if windowName != "" {
+		args = append(args, "-n", windowName)
+	}
+	if workingDir != "" {
+		args = append(args, "-c", workingDir)
+	}
+	args = append(args, "-P", "-F", "#{window_index}")
+
+	output, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create window in session '%s': %w", sessionName, err)
+	}
+
+	windowIndex, err := parseWindowIndex(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetWindowInfo(fmt.Sprintf("%s:%d", sessionName, windowIndex))
+}
+
+// StartCommand types a shell command into a window and submits it
+func (c *Client) StartCommand(target, command string) error {
+	if err := c.SendKeys(target, command); err != nil {
+		return err
+	}
+	return c.SendKeys(target, "Enter")
+}
+
+// ClearPane clears the visible content and scrollback history of a window
+func (c *Client) ClearPane(target string) error {
+	if err := c.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	if err := exec.Command("tmux", "send-keys", "-t", target, "-R").Run(); err != nil {
+		return fmt.Errorf("failed to reset pane %s: %w", target, err)
+	}
+	if err := exec.Command("tmux", "clear-history", "-t", target).Run(); err != nil {
+		return fmt.Errorf("failed to clear history of %s: %w", target, err)
+	}
+
+	return nil
+}
+
+// WaitForClaudePrompt polls a window until the detection rules classify it as Claude.
+// Lines containing the launch command are ignored so the echoed command line
+// itself does not count as a detection.
+func (c *Client) WaitForClaudePrompt(target, command string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	rules := utils.ConfiguredRuleSet()
+
+	for {
+		content, err := c.CapturePane(target, 50)
+		if err != nil {
+			return fmt.Errorf("failed to check window %s: %w", target, err)
+		}
+
+		paneCommand, _ := c.GetPaneCommand(target)
+		if rules.Evaluate(stripCommandLine(content, command), paneCommand).IsClaude {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Claude prompt not detected in %s after %v", target, timeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// SpawnClaudeWindow creates a window, starts Claude in it and waits for the prompt.
+// The window info is returned even when the prompt was not detected in time so the
+// caller can still register or clean up the window.
+func (c *Client) SpawnClaudeWindow(opts SpawnOptions) (*WindowInfo, error) {
+	command := opts.Command
+	if command == "" {
+		command = DefaultClaudeCommand
+	}
+	timeout := opts.StartupTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	window, err := c.CreateWindow(opts.SessionName, opts.WindowName, opts.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.StartCommand(window.Target, command); err != nil {
+		return window, fmt.Errorf("failed to start '%s' in %s: %w", command, window.Target, err)
+	}
+
+	if err := c.WaitForClaudePrompt(window.Target, command, timeout); err != nil {
+		return window, err
+	}
+
+	return window, nil
+}
+
+// stripCommandLine removes the shell prompt line that echoed the command, the
+// last line ending with it, so typing the command doesn't look like Claude.
+// Claude's own output mentioning the command, such as a path, is kept.
+func stripCommandLine(content, command string) string {
+	if command == "" {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimRight(lines[i], " ")
+		prompt, ok := strings.CutSuffix(line, command)
+		if ok && (prompt == "" || strings.HasSuffix(prompt, " ")) {
+			return strings.Join(append(lines[:i:i], lines[i+1:]...), "\n")
+		}
+	}
+	return content
+}
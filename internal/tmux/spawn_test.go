@@ -0,0 +1,35 @@
+package tmux
+
+import "testing"
+
+func TestStripCommandLine(t *testing.T) {
+	content := "me@host:~/src/claude-tools$ claude\n" +
+		"╭───────────────────────────────────────╮\n" +
+		"│ ✻ Welcome to Claude Code!             │\n" +
+		"│   cwd: /home/me/src/claude            │\n" +
+		"╰───────────────────────────────────────╯\n" +
+		"Read ~/.claude/settings.json\n" +
+		"  ? for shortcuts"
+
+	expected := "╭───────────────────────────────────────╮\n" +
+		"│ ✻ Welcome to Claude Code!             │\n" +
+		"│   cwd: /home/me/src/claude            │\n" +
+		"╰───────────────────────────────────────╯\n" +
+		"Read ~/.claude/settings.json\n" +
+		"  ? for shortcuts"
+	if got := stripCommandLine(content, "claude"); got != expected {
+		t.Errorf("stripCommandLine() = %q, expected only the prompt line removed", got)
+	}
+
+	// The last echo is the one that started Claude
+	restarted := "$ claude --continue\nbye\n$ claude --continue   \n? for shortcuts"
+	if got := stripCommandLine(restarted, "claude --continue"); got != "$ claude --continue\nbye\n? for shortcuts" {
+		t.Errorf("stripCommandLine() = %q, expected the last prompt line removed", got)
+	}
+
+	// A path ending in the command is not the prompt
+	path := "cwd: /home/me/claude\n? for shortcuts"
+	if got := stripCommandLine(path, "claude"); got != path {
+		t.Errorf("stripCommandLine() = %q, expected the content unchanged", got)
+	}
+}
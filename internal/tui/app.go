@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -17,11 +18,16 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/daemon"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/discovery"
+	"github.com/derekxwang/tcs/internal/events"
 	"github.com/derekxwang/tcs/internal/monitor"
 	"github.com/derekxwang/tcs/internal/scheduler"
 	"github.com/derekxwang/tcs/internal/tmux"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/tui/views"
 )
 
@@ -34,6 +40,12 @@ type App struct {
 	windowDiscovery *discovery.WindowDiscovery
 	scheduler       *scheduler.Scheduler
 
+	// Running daemon the TUI is attached to, which gets the actions only a
+	// scheduler or window discovery can run
+	remote     *daemon.Client
+	remoteGone bool   // the attached daemon stopped its heartbeat
+	status     string // shown at the end of the header
+
 	// TUI state
 	currentView ViewType
 	width       int
@@ -44,6 +56,14 @@ type App struct {
 	windows       *views.Windows
 	messages      *views.Messages
 	schedulerView *views.Scheduler
+	eventsView    *views.Events
+
+	// Command palette, shown over the current view
+	palette     *components.Palette
+	showPalette bool
+
+	// Key binding help, shown over the current view
+	showHelp bool
 
 	// Key bindings
 	keyMap KeyMap
@@ -61,61 +81,30 @@ const (
 	WindowsView
 	MessagesView
 	SchedulerView
+	EventsView
 	HelpView
 )
 
 // KeyMap defines key bindings for the TUI
 type KeyMap struct {
-	Up        key.Binding
-	Down      key.Binding
-	Left      key.Binding
-	Right     key.Binding
-	Tab       key.Binding
-	Enter     key.Binding
-	Escape    key.Binding
 	Help      key.Binding
 	Quit      key.Binding
 	Dashboard key.Binding
 	Windows   key.Binding
 	Messages  key.Binding
 	Scheduler key.Binding
+	Events    key.Binding
+	Palette   key.Binding
+	Theme     key.Binding
 	Refresh   key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "move up"),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "move down"),
-		),
-		Left: key.NewBinding(
-			key.WithKeys("left", "h"),
-			key.WithHelp("←/h", "move left"),
-		),
-		Right: key.NewBinding(
-			key.WithKeys("right", "l"),
-			key.WithHelp("→/l", "move right"),
-		),
-		Tab: key.NewBinding(
-			key.WithKeys("tab"),
-			key.WithHelp("tab", "next section"),
-		),
-		Enter: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
-		),
-		Escape: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "back"),
-		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
-			key.WithHelp("?", "toggle help"),
+			key.WithHelp("?", "help"),
 		),
 		Quit: key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
@@ -137,6 +126,18 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("4"),
 			key.WithHelp("4", "scheduler"),
 		),
+		Events: key.NewBinding(
+			key.WithKeys("5"),
+			key.WithHelp("5", "events"),
+		),
+		Palette: key.NewBinding(
+			key.WithKeys(":", "ctrl+p"),
+			key.WithHelp(":/ctrl+p", "palette"),
+		),
+		Theme: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "cycle theme"),
+		),
 		Refresh: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
@@ -144,6 +145,22 @@ func DefaultKeyMap() KeyMap {
 	}
 }
 
+// Actions returns the bindings by their names in the tui.keys config
+func (k *KeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "help", Binding: &k.Help},
+		{Name: "quit", Binding: &k.Quit},
+		{Name: "dashboard", Binding: &k.Dashboard},
+		{Name: "windows", Binding: &k.Windows},
+		{Name: "messages", Binding: &k.Messages},
+		{Name: "scheduler", Binding: &k.Scheduler},
+		{Name: "events", Binding: &k.Events},
+		{Name: "palette", Binding: &k.Palette},
+		{Name: "theme", Binding: &k.Theme},
+		{Name: "refresh", Binding: &k.Refresh},
+	}
+}
+
 // TickMsg represents a tick message for periodic updates
 type TickMsg time.Time
 
@@ -153,6 +170,9 @@ type RefreshMsg struct{}
 // ViewChangeMsg represents a view change request
 type ViewChangeMsg ViewType
 
+// daemonStatusMsg reports whether the attached daemon is still running
+type daemonStatusMsg bool
+
 // NewApp creates a new TUI application
 func NewApp(db *gorm.DB, tmuxClient *tmux.Client, usageMonitor *monitor.UsageMonitor,
 	windowDiscovery *discovery.WindowDiscovery, schedulerInstance *scheduler.Scheduler) *App {
@@ -176,10 +196,33 @@ func NewApp(db *gorm.DB, tmuxClient *tmux.Client, usageMonitor *monitor.UsageMon
 	app.windows = views.NewWindows(db, windowDiscovery, tmuxClient)
 	app.messages = views.NewMessages(db, schedulerInstance, tmuxClient)
 	app.schedulerView = views.NewScheduler(db, schedulerInstance, windowDiscovery)
+	app.eventsView = views.NewEvents(db)
+	app.palette = components.NewPalette()
 
 	return app
 }
 
+// Attach forwards processing and window scans to a running daemon. The
+// scheduler passed to NewApp should not be started then; it only queues
+// messages in the shared database.
+func (a *App) Attach(client *daemon.Client) {
+	a.remote = client
+	a.remoteGone = false
+	a.forwardTo(client)
+}
+
+// forwardTo sets the daemon the views forward their actions to, nil to stop
+func (a *App) forwardTo(client *daemon.Client) {
+	a.windows.SetRemote(client)
+	a.messages.SetRemote(client)
+	a.schedulerView.SetRemote(client)
+}
+
+// SetStatus sets the note shown at the end of the header
+func (a *App) SetStatus(status string) {
+	a.status = status
+}
+
 // Init initializes the TUI application
 func (a *App) Init() tea.Cmd {
 	var cmds []tea.Cmd
@@ -189,6 +232,7 @@ func (a *App) Init() tea.Cmd {
 	cmds = append(cmds, a.windows.Init())
 	cmds = append(cmds, a.messages.Init())
 	cmds = append(cmds, a.schedulerView.Init())
+	cmds = append(cmds, a.eventsView.Init())
 
 	// Start refresh ticker
 	if os.Getenv("TCS_DISABLE_TICKER") != "1" {
@@ -222,41 +266,67 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.windows.SetSize(msg.Width, msg.Height-4)
 		a.messages.SetSize(msg.Width, msg.Height-4)
 		a.schedulerView.SetSize(msg.Width, msg.Height-4)
+		a.eventsView.SetSize(msg.Width, msg.Height-4)
+		a.palette.SetSize(msg.Width, msg.Height-4)
 
 	case tea.KeyMsg:
-		// Check if any view has an active form - if so, don't process navigation keys
-		hasActiveForm := false
-		switch a.currentView {
-		case MessagesView:
-			hasActiveForm = a.messages.IsFormActive()
-		case SchedulerView:
-			hasActiveForm = a.schedulerView.IsFormActive()
-		case DashboardView:
-			hasActiveForm = a.dashboard.IsFormActive()
-		case WindowsView:
-			hasActiveForm = a.windows.IsFormActive()
+		// The open palette takes all keys; only ctrl+c still quits
+		if a.showPalette {
+			if msg.Type == tea.KeyCtrlC {
+				a.cleanup()
+				return a, tea.Quit
+			}
+			var cmd tea.Cmd
+			a.palette, cmd = a.palette.Update(msg)
+			return a, cmd
 		}
 
+		// The help closes with its own key or esc; only ctrl+c still quits
+		if a.showHelp {
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				a.cleanup()
+				return a, tea.Quit
+			case key.Matches(msg, a.keyMap.Help), msg.Type == tea.KeyEsc:
+				a.showHelp = false
+			}
+			return a, nil
+		}
+
+		// Check if any view has an active form - if so, don't process navigation keys
+		hasActiveForm := a.hasActiveForm()
+
 		switch {
-		case key.Matches(msg, a.keyMap.Quit):
+		// While typing in a form only ctrl+c quits, and it quits however quit is bound
+		case msg.Type == tea.KeyCtrlC || (key.Matches(msg, a.keyMap.Quit) && !hasActiveForm):
 			a.cleanup()
 			return a, tea.Quit
 
+		case key.Matches(msg, a.keyMap.Help) && !hasActiveForm:
+			a.showHelp = true
+			return a, nil
+
+		case key.Matches(msg, a.keyMap.Palette) && !hasActiveForm:
+			return a, a.openPalette()
+
+		case key.Matches(msg, a.keyMap.Theme) && !hasActiveForm:
+			a.applyTheme(theme.Next().Name)
+			return a, nil
+
 		case key.Matches(msg, a.keyMap.Dashboard) && !hasActiveForm:
-			a.currentView = DashboardView
-			cmds = append(cmds, a.dashboard.Refresh())
+			cmds = append(cmds, a.switchView(DashboardView))
 
 		case key.Matches(msg, a.keyMap.Windows) && !hasActiveForm:
-			a.currentView = WindowsView
-			cmds = append(cmds, a.windows.Refresh())
+			cmds = append(cmds, a.switchView(WindowsView))
 
 		case key.Matches(msg, a.keyMap.Messages) && !hasActiveForm:
-			a.currentView = MessagesView
-			cmds = append(cmds, a.messages.Refresh())
+			cmds = append(cmds, a.switchView(MessagesView))
 
 		case key.Matches(msg, a.keyMap.Scheduler) && !hasActiveForm:
-			a.currentView = SchedulerView
-			cmds = append(cmds, a.schedulerView.Refresh())
+			cmds = append(cmds, a.switchView(SchedulerView))
+
+		case key.Matches(msg, a.keyMap.Events) && !hasActiveForm:
+			cmds = append(cmds, a.switchView(EventsView))
 
 		case key.Matches(msg, a.keyMap.Refresh) && !hasActiveForm:
 			cmds = append(cmds, func() tea.Msg {
@@ -264,6 +334,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 		}
 
+	case tea.MouseMsg:
+		return a, a.handleMouse(msg)
+
 	case TickMsg:
 		// Periodic refresh
 		if os.Getenv("TCS_DISABLE_TICKER") != "1" {
@@ -278,6 +351,24 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return TickMsg(t)
 			}))
 		}
+		if a.remote != nil {
+			cmds = append(cmds, a.checkDaemon())
+		}
+
+	case daemonStatusMsg:
+		// Requests left for a stopped daemon would never run, so forwarding
+		// stops until its heartbeat is back
+		if gone := !bool(msg); gone != a.remoteGone {
+			a.remoteGone = gone
+			if gone {
+				log.Printf("Warning: daemon %s stopped; actions are no longer forwarded, restart the TUI to run standalone", a.remote.Owner())
+				a.forwardTo(nil)
+			} else {
+				log.Printf("Daemon %s is back, forwarding actions again", a.remote.Owner())
+				a.forwardTo(a.remote)
+			}
+		}
+		return a, nil
 
 	case RefreshMsg:
 		// Only refresh the current view to reduce load
@@ -290,15 +381,48 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, a.messages.Refresh())
 		case SchedulerView:
 			cmds = append(cmds, a.schedulerView.Refresh())
+		case EventsView:
+			cmds = append(cmds, a.eventsView.Refresh())
 		}
 
 	case ViewChangeMsg:
-		a.currentView = ViewType(msg)
+		return a, a.switchView(ViewType(msg))
+
+	case ThemeMsg:
+		if err := theme.Set(msg.Theme); err != nil {
+			log.Printf("Warning: %v", err)
+			return a, nil
+		}
+		a.applyTheme(msg.Theme)
 		return a, nil
+
+	case paletteItemsMsg:
+		a.palette.SetItems(msg)
+		return a, nil
+
+	case components.PalettePickMsg:
+		a.showPalette = false
+		return a, a.runPaletteItem(msg.Item)
+
+	case components.PaletteCloseMsg:
+		a.showPalette = false
+		return a, nil
+
+	case views.WindowPreviewMsg:
+		// The preview keeps streaming while another view is showing
+		var cmd tea.Cmd
+		a.windows, cmd = a.windows.Update(msg)
+		return a, cmd
 	}
 
-	// Update current view
+	// Cursor blinking in the palette
 	var cmd tea.Cmd
+	if a.showPalette {
+		a.palette, cmd = a.palette.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Update current view
 	switch a.currentView {
 	case DashboardView:
 		a.dashboard, cmd = a.dashboard.Update(msg)
@@ -312,6 +436,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case SchedulerView:
 		a.schedulerView, cmd = a.schedulerView.Update(msg)
 		cmds = append(cmds, cmd)
+	case EventsView:
+		a.eventsView, cmd = a.eventsView.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return a, tea.Batch(cmds...)
@@ -328,6 +455,8 @@ func (a *App) View() string {
 		a.windows.SetSize(a.width, a.height-4)
 		a.messages.SetSize(a.width, a.height-4)
 		a.schedulerView.SetSize(a.width, a.height-4)
+		a.eventsView.SetSize(a.width, a.height-4)
+		a.palette.SetSize(a.width, a.height-4)
 	}
 
 	// Header
@@ -344,9 +473,16 @@ func (a *App) View() string {
 		content = a.messages.View()
 	case SchedulerView:
 		content = a.schedulerView.View()
+	case EventsView:
+		content = a.eventsView.View()
 	default:
 		content = "Unknown view"
 	}
+	if a.showPalette {
+		content = lipgloss.Place(a.width, a.height-4, lipgloss.Center, lipgloss.Top, a.palette.View())
+	} else if a.showHelp {
+		content = lipgloss.Place(a.width, a.height-4, lipgloss.Center, lipgloss.Top, a.renderHelp())
+	}
 
 	// Footer
 	footer := a.renderFooter()
@@ -354,42 +490,175 @@ func (a *App) View() string {
 	return fmt.Sprintf("%s\n%s\n%s", header, content, footer)
 }
 
+// hasActiveForm reports whether the current view is taking typed text
+func (a *App) hasActiveForm() bool {
+	switch a.currentView {
+	case MessagesView:
+		return a.messages.IsFormActive()
+	case SchedulerView:
+		return a.schedulerView.IsFormActive()
+	case DashboardView:
+		return a.dashboard.IsFormActive()
+	case WindowsView:
+		return a.windows.IsFormActive()
+	case EventsView:
+		return a.eventsView.IsFormActive()
+	}
+	return false
+}
+
+// switchView shows a view and refreshes it. The windows preview only captures
+// while the Windows view is shown; its refresh restarts the capture.
+func (a *App) switchView(view ViewType) tea.Cmd {
+	if a.currentView == WindowsView && view != WindowsView {
+		a.windows.StopPreview()
+	}
+	a.currentView = view
+	switch view {
+	case DashboardView:
+		return a.dashboard.Refresh()
+	case WindowsView:
+		return a.windows.Refresh()
+	case MessagesView:
+		return a.messages.Refresh()
+	case SchedulerView:
+		return a.schedulerView.Refresh()
+	case EventsView:
+		return a.eventsView.Refresh()
+	}
+	return nil
+}
+
+// handleMouse switches views on a click on their header tab, and passes other
+// mouse events to the current view relative to its top
+func (a *App) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	// The palette and help don't take the mouse
+	if a.showPalette || a.showHelp {
+		return nil
+	}
+
+	headerHeight := lipgloss.Height(a.renderHeader())
+	if msg.Y < headerHeight {
+		if view, ok := a.tabAt(msg.X); ok && components.IsLeftClick(msg) && !a.hasActiveForm() {
+			return a.switchView(view)
+		}
+		return nil
+	}
+	msg.Y -= headerHeight
+
+	var cmd tea.Cmd
+	switch a.currentView {
+	case WindowsView:
+		a.windows, cmd = a.windows.Update(msg)
+	case MessagesView:
+		a.messages, cmd = a.messages.Update(msg)
+	case SchedulerView:
+		a.schedulerView, cmd = a.schedulerView.Update(msg)
+	case EventsView:
+		a.eventsView, cmd = a.eventsView.Update(msg)
+	}
+	return cmd
+}
+
+// applyTheme restyles every view with the named theme
+func (a *App) applyTheme(name string) {
+	a.dashboard.SetTheme(name)
+	a.windows.SetTheme(name)
+	a.messages.SetTheme(name)
+	a.schedulerView.SetTheme(name)
+	a.eventsView.SetTheme(name)
+	a.palette.SetTheme(name)
+}
+
 // renderHeader renders the application header
 func (a *App) renderHeader() string {
+	t := theme.Current()
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("57")).
+		Foreground(t.SelectedText).
+		Background(t.Selected).
 		Padding(0, 1).
 		Width(a.width)
 
-	var viewName string
-	switch a.currentView {
-	case DashboardView:
-		viewName = "Dashboard"
-	case WindowsView:
-		viewName = "Windows"
-	case MessagesView:
-		viewName = "Messages"
-	case SchedulerView:
-		viewName = "Scheduler"
-	default:
-		viewName = "Unknown"
+	// The current view's tab is inverted; the header background is reset after
+	// each styled tab, so the other tabs set it again
+	tabStyle := lipgloss.NewStyle().Foreground(t.SelectedText).Background(t.Selected).Bold(true)
+	currentStyle := lipgloss.NewStyle().Foreground(t.Selected).Background(t.SelectedText).Bold(true)
+
+	title := headerTitle
+	for _, tab := range viewTabs {
+		if tab.view == a.currentView {
+			title += currentStyle.Render(" " + tab.name + " ")
+		} else {
+			title += tabStyle.Render(" " + tab.name + " ")
+		}
 	}
 
-	title := fmt.Sprintf("TCS (Tmux Claude Scheduler) - %s", viewName)
+	// The status is left out when it would wrap the header
+	if status := a.headerStatus(); status != "" && lipgloss.Width(title)+lipgloss.Width(status)+4 <= a.width {
+		title += tabStyle.Render("  " + status)
+	}
 	return headerStyle.Render(title)
 }
 
+// headerStatus describes how the TUI runs next to the daemon
+func (a *App) headerStatus() string {
+	switch {
+	case a.remote != nil && a.remoteGone:
+		return "daemon " + a.remote.Owner() + " stopped, not forwarding"
+	case a.remote != nil:
+		return "attached to " + a.remote.Owner()
+	}
+	return a.status
+}
+
+// checkDaemon looks for the heartbeat of the attached daemon
+func (a *App) checkDaemon() tea.Cmd {
+	remote := a.remote
+	return func() tea.Msg {
+		return daemonStatusMsg(remote.Alive())
+	}
+}
+
+// headerTitle comes before the view tabs in the header
+const headerTitle = "TCS (Tmux Claude Scheduler)  "
+
+// viewTabs are the views listed in the header, in the order of their keys
+var viewTabs = []struct {
+	view ViewType
+	name string
+}{
+	{DashboardView, "Dashboard"},
+	{WindowsView, "Windows"},
+	{MessagesView, "Messages"},
+	{SchedulerView, "Scheduler"},
+	{EventsView, "Events"},
+}
+
+// tabAt returns the view whose header tab is at column x
+func (a *App) tabAt(x int) (ViewType, bool) {
+	start := 1 + lipgloss.Width(headerTitle) // after the header padding
+	for _, tab := range viewTabs {
+		end := start + lipgloss.Width(tab.name) + 2
+		if x >= start && x < end {
+			return tab.view, true
+		}
+		start = end
+	}
+	return 0, false
+}
+
 // renderFooter renders the application footer with key bindings
 func (a *App) renderFooter() string {
+	t := theme.Current()
 	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
-		Background(lipgloss.Color("0")).
+		Foreground(t.Muted).
+		Background(t.Background).
 		Padding(0, 1).
 		Width(a.width)
 
-	help := "1: Dashboard  2: Windows  3: Messages  4: Scheduler  r: Refresh  ?: Help  q: Quit"
+	k := a.keyMap
+	help := keys.Hint(k.Dashboard, k.Windows, k.Messages, k.Scheduler, k.Events, k.Palette, k.Refresh, k.Help, k.Quit)
 	return footerStyle.Render(help)
 }
 
@@ -398,6 +667,7 @@ func (a *App) cleanup() {
 	if a.cancel != nil {
 		a.cancel()
 	}
+	a.windows.StopPreview()
 	// Note: Scheduler cleanup is handled by defer in Run() function
 	// to ensure it's stopped regardless of how the TUI exits
 }
@@ -477,8 +747,10 @@ func cleanupTerminal() {
 	_ = cmd.Run() // Ignore any errors from stty
 }
 
-// Run starts the TUI application
-func Run() error {
+// Run starts the TUI application. With attach it follows a running daemon
+// instead of starting its own scheduler and window discovery, and runs
+// standalone when no daemon is found.
+func Run(attach bool) error {
 	// Ensure config is loaded (safety check for nil pointer dereference)
 	if config.Get() == nil {
 		_, err := config.Load("")
@@ -515,6 +787,16 @@ func Run() error {
 		}
 	}()
 
+	// Load custom themes before picking the configured one
+	if names, err := theme.LoadDir(theme.Dir()); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if len(names) > 0 {
+		log.Printf("Loaded themes: %s", strings.Join(names, ", "))
+	}
+	if err := theme.Set(config.GetTUIConfig().Theme); err != nil {
+		log.Printf("Warning: %v; using the default theme", err)
+	}
+
 	// Initialize database
 	if err := database.Initialize(nil); err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
@@ -528,9 +810,30 @@ func Run() error {
 		return fmt.Errorf("failed to initialize usage monitor: %w", err)
 	}
 
-	// Create window discovery service
+	// Look for a running daemon, so two schedulers don't compete for the queue
+	var remote *daemon.Client
+	var status string
+	running, err := daemon.Find(database.GetDB())
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	switch {
+	case attach && running != nil:
+		remote = daemon.NewClient(database.GetDB(), running)
+		log.Printf("Attached to daemon %s", running.Owner)
+	case attach:
+		status = "standalone: no daemon found"
+		log.Printf("No running daemon found, running standalone")
+	case running != nil:
+		status = "daemon " + running.Owner + " is running, use --attach"
+		log.Printf("Warning: daemon %s is running too; use 'tcs tui --attach' to follow it", running.Owner)
+	}
+
+	// Create window discovery service; an attached daemon runs its own
 	var windowDiscovery *discovery.WindowDiscovery
-	if os.Getenv("TCS_DISABLE_WINDOW_DISCOVERY") != "1" {
+	if remote != nil {
+		log.Printf("Window discovery service: DAEMON")
+	} else if os.Getenv("TCS_DISABLE_WINDOW_DISCOVERY") != "1" {
 		windowDiscovery = discovery.NewWindowDiscovery(database.GetDB(), tmuxClient, nil)
 		if err := windowDiscovery.Start(); err != nil {
 			return fmt.Errorf("failed to start window discovery: %w", err)
@@ -551,12 +854,30 @@ func Run() error {
 		usageMonitor,
 		nil, // No callback needed for TUI
 	)
-	if err := schedulerInstance.Initialize(); err != nil {
-		return fmt.Errorf("failed to initialize scheduler: %w", err)
+	if remote == nil {
+		if err := schedulerInstance.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize scheduler: %w", err)
+		}
+	}
+
+	// Resume held messages as soon as Claude is back in a window
+	if windowDiscovery != nil {
+		windowDiscovery.OnClaudeRestored(func(window *database.TmuxWindow) {
+			schedulerInstance.ResumeWindow(window.ID)
+		})
 	}
 
-	// Start the scheduler to process messages
-	if os.Getenv("TCS_DISABLE_SCHEDULER") != "1" {
+	// Record scheduler, discovery and usage events for the events view
+	events.NewRecorder(database.GetDB()).Attach(schedulerInstance, windowDiscovery, usageMonitor)
+	if remote == nil {
+		usageMonitor.StartMonitoring(time.Minute)
+	}
+
+	// Start the scheduler to process messages; an attached daemon processes
+	// them and the local scheduler only queues new ones
+	if remote != nil {
+		log.Printf("Scheduler service: DAEMON")
+	} else if os.Getenv("TCS_DISABLE_SCHEDULER") != "1" {
 		if err := schedulerInstance.Start(); err != nil {
 			return fmt.Errorf("failed to start scheduler: %w", err)
 		}
@@ -572,6 +893,13 @@ func Run() error {
 
 	// Create and run TUI app
 	app := NewApp(database.GetDB(), tmuxClient, usageMonitor, windowDiscovery, schedulerInstance)
+	if err := app.ApplyKeys(config.GetTUIConfig().Keys); err != nil {
+		return fmt.Errorf("invalid tui.keys config: %w", err)
+	}
+	if remote != nil {
+		app.Attach(remote)
+	}
+	app.SetStatus(status)
 
 	// Try to get terminal size manually and set it
 	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
@@ -582,10 +910,13 @@ func Run() error {
 		app.windows.SetSize(width, height-4)
 		app.messages.SetSize(width, height-4)
 		app.schedulerView.SetSize(width, height-4)
+		app.eventsView.SetSize(width, height-4)
+		app.palette.SetSize(width, height-4)
 	}
 
 	p := tea.NewProgram(app,
 		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
 		tea.WithInput(os.Stdin),
 		tea.WithOutput(os.Stderr),
 	)
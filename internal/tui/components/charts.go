@@ -0,0 +1,188 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/derekxwang/tcs/internal/tui/theme"
+)
+
+// Characters used to draw charts, from empty to full
+var (
+	sparkBlocks = []rune(" ▁▂▃▄▅▆▇█")
+	sparkASCII  = []rune(" .:-=+*#@")
+)
+
+// ChartBar is a labeled value in a bar chart
+type ChartBar struct {
+	Label string
+	Value int
+}
+
+// Sparkline renders one character per value, scaled to the largest value.
+// When there are more values than width, only the most recent are shown.
+// ASCII characters are used instead of block characters when ascii is set.
+func Sparkline(values []int, width int, ascii bool) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	levels := sparkBlocks
+	if ascii {
+		levels = sparkASCII
+	}
+
+	peak := 0
+	for _, value := range values {
+		peak = max(peak, value)
+	}
+
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if peak > 0 && value > 0 {
+			// Any usage gets at least the lowest level so it stands out from none
+			level = max(1, value*(len(levels)-1)/peak)
+		}
+		line.WriteRune(levels[level])
+	}
+	return line.String()
+}
+
+// BarChart renders one horizontal bar per value, scaled to the largest value,
+// with the label on the left and the value on the right. Lines are at most
+// width characters wide.
+func BarChart(bars []ChartBar, width int, ascii bool) string {
+	if len(bars) == 0 {
+		return ""
+	}
+
+	labelWidth, valueWidth, peak := 0, 0, 0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, lipgloss.Width(bar.Label))
+		valueWidth = max(valueWidth, len(ShortNumber(bar.Value)))
+		peak = max(peak, bar.Value)
+	}
+	barWidth := max(1, width-labelWidth-valueWidth-2)
+
+	fill, empty := "█", "░"
+	if ascii {
+		fill, empty = "#", "."
+	}
+
+	lines := make([]string, 0, len(bars))
+	for _, bar := range bars {
+		filled := 0
+		if peak > 0 {
+			filled = bar.Value * barWidth / peak
+			if bar.Value > 0 {
+				filled = max(1, filled)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s%s %*s",
+			labelWidth, bar.Label,
+			strings.Repeat(fill, filled), strings.Repeat(empty, barWidth-filled),
+			valueWidth, ShortNumber(bar.Value)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BurnRate is the token burn rate of a session against its limit
+type BurnRate struct {
+	Used      int           // tokens used so far
+	Limit     int           // token limit of the session (P90 of past sessions)
+	Elapsed   time.Duration // time since the session started
+	Remaining time.Duration // time until the session resets
+}
+
+// PerMinute returns the tokens used per minute so far
+func (b BurnRate) PerMinute() float64 {
+	if b.Elapsed < time.Minute {
+		return 0
+	}
+	return float64(b.Used) / b.Elapsed.Minutes()
+}
+
+// Projected returns the tokens the session will have used when it resets at the current rate
+func (b BurnRate) Projected() int {
+	return b.Used + int(b.PerMinute()*b.Remaining.Minutes())
+}
+
+// BurnRateLine renders the burn rate with a gauge of the used and projected
+// tokens and a marker at the limit, e.g.
+// "Burn 420/min [████▒▒▒│  ] 95k of 120k P90". Projections over the limit are
+// drawn in the theme's danger color, or marked with "!" in ASCII themes.
+func BurnRateLine(b BurnRate, width int, t theme.Theme) string {
+	ascii := t.ASCII
+	projected := b.Projected()
+	prefix := fmt.Sprintf("Burn %s/min ", ShortNumber(int(b.PerMinute())))
+	suffix := fmt.Sprintf(" %s of %s P90", ShortNumber(projected), ShortNumber(b.Limit))
+	if ascii && b.Limit > 0 && projected > b.Limit {
+		suffix += " !"
+	}
+
+	gaugeWidth := width - lipgloss.Width(prefix) - lipgloss.Width(suffix) - 2
+	if gaugeWidth < 5 {
+		return prefix + strings.TrimSpace(suffix)
+	}
+
+	used, projection, marker, empty := "█", "▒", "│", " "
+	if ascii {
+		used, projection, marker, empty = "#", "+", "|", "."
+	}
+
+	// Scale so both the projection and the limit fit on the gauge
+	scale := max(b.Limit, projected, 1)
+	usedCells := min(gaugeWidth, b.Used*gaugeWidth/scale)
+	projectedCells := min(gaugeWidth, projected*gaugeWidth/scale)
+	limitCell := -1
+	if b.Limit > 0 {
+		limitCell = min(gaugeWidth-1, b.Limit*gaugeWidth/scale)
+	}
+
+	var gauge strings.Builder
+	for cell := 0; cell < gaugeWidth; cell++ {
+		switch {
+		case cell == limitCell:
+			gauge.WriteString(marker)
+		case cell < usedCells:
+			gauge.WriteString(used)
+		case cell < projectedCells:
+			gauge.WriteString(projection)
+		default:
+			gauge.WriteString(empty)
+		}
+	}
+
+	line := prefix + "[" + gauge.String() + "]" + suffix
+	if ascii {
+		return line
+	}
+
+	color := t.Success
+	if b.Limit > 0 && projected > b.Limit {
+		color = t.Danger
+	} else if b.Limit > 0 && float64(projected) > 0.8*float64(b.Limit) {
+		color = t.Warning
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(line)
+}
+
+// ShortNumber formats a number compactly, e.g. 950, 1.5k, 12k or 1.2M
+func ShortNumber(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 10000:
+		return fmt.Sprintf("%.0fk", float64(n)/1000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,548 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+	"github.com/derekxwang/tcs/internal/utils"
+)
+
+// Composer fields, in tab order
+const (
+	composerFieldWindow = iota
+	composerFieldContent
+	composerFieldPriority
+	composerFieldWhen
+	composerFieldCount
+)
+
+// Composer is a full-screen form for writing a multi-line message and
+// picking its window, priority and send time
+type Composer struct {
+	windows  []database.TmuxWindow
+	window   int // index of the picked window
+	content  textarea.Model
+	when     textinput.Model
+	priority int
+
+	// Template picker
+	templates []config.MessageTemplate
+	template  int // highlighted template
+	picking   bool
+
+	focus  int
+	err    string
+	width  int
+	height int
+
+	// Key bindings
+	keyMap ComposerKeyMap
+
+	// Styles
+	titleStyle        lipgloss.Style
+	labelStyle        lipgloss.Style
+	focusedLabelStyle lipgloss.Style
+	valueStyle        lipgloss.Style
+	selectedStyle     lipgloss.Style
+	hintStyle         lipgloss.Style
+	errorStyle        lipgloss.Style
+	boxStyle          lipgloss.Style
+}
+
+// ComposerKeyMap defines key bindings for the composer
+type ComposerKeyMap struct {
+	NextField key.Binding
+	PrevField key.Binding
+	Decrease  key.Binding
+	Increase  key.Binding
+	Submit    key.Binding
+	Cancel    key.Binding
+	Templates key.Binding
+	Editor    key.Binding
+}
+
+// DefaultComposerKeyMap returns the default composer key bindings
+func DefaultComposerKeyMap() ComposerKeyMap {
+	return ComposerKeyMap{
+		NextField: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
+		PrevField: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("shift+tab", "previous field"),
+		),
+		Decrease: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "previous"),
+		),
+		Increase: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "next"),
+		),
+		Submit: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "schedule"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		Templates: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "insert template"),
+		),
+		Editor: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("ctrl+e", "open in $EDITOR"),
+		),
+	}
+}
+
+// Actions returns the bindings by their names in the tui.keys config
+func (k *ComposerKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "submit", Binding: &k.Submit},
+		{Name: "cancel", Binding: &k.Cancel},
+		{Name: "next_field", Binding: &k.NextField},
+		{Name: "prev_field", Binding: &k.PrevField},
+		{Name: "decrease", Binding: &k.Decrease},
+		{Name: "increase", Binding: &k.Increase},
+		{Name: "templates", Binding: &k.Templates},
+		{Name: "editor", Binding: &k.Editor},
+	}
+}
+
+// ComposedMessage is a message ready to be scheduled
+type ComposedMessage struct {
+	Target        string
+	Content       string
+	Priority      int
+	ScheduledTime time.Time
+}
+
+// ComposerSubmitMsg is sent when the composed message is submitted
+type ComposerSubmitMsg struct {
+	Message ComposedMessage
+}
+
+// ComposerCancelMsg is sent when the composer is closed without submitting
+type ComposerCancelMsg struct{}
+
+// ComposerEditedMsg carries the content written in $EDITOR back to the composer
+type ComposerEditedMsg struct {
+	Content string
+	Err     error
+}
+
+// NewComposer creates a new message composer
+func NewComposer(templates []config.MessageTemplate) *Composer {
+	content := textarea.New()
+	content.Placeholder = "Message for Claude..."
+	content.ShowLineNumbers = false
+
+	when := textinput.New()
+	when.Placeholder = "now, +30m, 14:30, 2025-01-02 09:00"
+	when.CharLimit = 32
+	when.Width = 30
+
+	if len(templates) == 0 {
+		templates = config.DefaultMessageTemplates()
+	}
+
+	c := &Composer{
+		content:   content,
+		when:      when,
+		priority:  5,
+		templates: templates,
+		keyMap:    DefaultComposerKeyMap(),
+	}
+	c.initStyles(theme.Current())
+	return c
+}
+
+// KeySection returns the composer's key bindings for remapping and the help
+// view. The composer takes all keys while it is open.
+func (c *Composer) KeySection() keys.Section {
+	return keys.Section{Name: "composer", Title: "Composer", Actions: c.keyMap.Actions(), Modal: true}
+}
+
+// SetTheme restyles the composer with the named theme
+func (c *Composer) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	c.initStyles(t)
+}
+
+// initStyles initializes the composer styles
+func (c *Composer) initStyles(t theme.Theme) {
+	c.titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Text).
+		MarginBottom(1)
+
+	c.labelStyle = lipgloss.NewStyle().
+		Foreground(t.Muted).
+		Width(10)
+
+	c.focusedLabelStyle = c.labelStyle.
+		Foreground(t.Accent).
+		Bold(true)
+
+	c.valueStyle = lipgloss.NewStyle().
+		Foreground(t.Text)
+
+	c.selectedStyle = lipgloss.NewStyle().
+		Foreground(t.SelectedText).
+		Background(t.Selected).
+		Bold(true)
+
+	c.hintStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	c.errorStyle = lipgloss.NewStyle().
+		Foreground(t.Danger)
+
+	c.boxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Muted)
+}
+
+// Open resets the composer for a new message and focuses the message field
+func (c *Composer) Open() tea.Cmd {
+	c.content.Reset()
+	c.when.SetValue("")
+	c.priority = 5
+	c.picking = false
+	c.err = ""
+	return c.setFocus(composerFieldContent)
+}
+
+// SetWindows sets the windows to pick from, keeping the picked window if it is still there
+func (c *Composer) SetWindows(windows []database.TmuxWindow) {
+	target := ""
+	if c.window < len(c.windows) {
+		target = c.windows[c.window].Target
+	}
+
+	c.windows = windows
+	c.window = 0
+	for i, window := range windows {
+		if window.Target == target {
+			c.window = i
+		}
+	}
+}
+
+// SetSize sets the composer size
+func (c *Composer) SetSize(width, height int) {
+	c.width = width
+	c.height = height
+	c.content.SetWidth(max(20, width-6))
+	c.content.SetHeight(max(3, height-16))
+}
+
+// Update handles messages for the composer
+func (c *Composer) Update(msg tea.Msg) (*Composer, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ComposerEditedMsg:
+		if msg.Err != nil {
+			c.err = fmt.Sprintf("Editor failed: %v", msg.Err)
+		} else {
+			c.content.SetValue(msg.Content)
+			c.err = ""
+		}
+		return c, c.setFocus(composerFieldContent)
+
+	case tea.KeyMsg:
+		if c.picking {
+			return c, c.handlePickerKeys(msg)
+		}
+		return c.handleKeys(msg)
+	}
+
+	// Cursor blinking
+	var cmd tea.Cmd
+	switch c.focus {
+	case composerFieldContent:
+		c.content, cmd = c.content.Update(msg)
+	case composerFieldWhen:
+		c.when, cmd = c.when.Update(msg)
+	}
+	return c, cmd
+}
+
+// handleKeys handles key presses outside the template picker
+func (c *Composer) handleKeys(msg tea.KeyMsg) (*Composer, tea.Cmd) {
+	switch {
+	case key.Matches(msg, c.keyMap.Cancel):
+		return c, func() tea.Msg { return ComposerCancelMsg{} }
+
+	case key.Matches(msg, c.keyMap.Submit):
+		message, err := c.Value(time.Now())
+		if err != nil {
+			c.err = err.Error()
+			return c, nil
+		}
+		return c, func() tea.Msg { return ComposerSubmitMsg{Message: message} }
+
+	case key.Matches(msg, c.keyMap.NextField):
+		return c, c.setFocus((c.focus + 1) % composerFieldCount)
+
+	case key.Matches(msg, c.keyMap.PrevField):
+		return c, c.setFocus((c.focus + composerFieldCount - 1) % composerFieldCount)
+
+	case key.Matches(msg, c.keyMap.Templates):
+		c.picking = len(c.templates) > 0
+		return c, nil
+
+	case key.Matches(msg, c.keyMap.Editor):
+		return c, c.openEditor()
+	}
+
+	var cmd tea.Cmd
+	switch c.focus {
+	case composerFieldWindow:
+		if len(c.windows) > 0 {
+			switch {
+			case key.Matches(msg, c.keyMap.Decrease):
+				c.window = (c.window + len(c.windows) - 1) % len(c.windows)
+			case key.Matches(msg, c.keyMap.Increase):
+				c.window = (c.window + 1) % len(c.windows)
+			}
+		}
+
+	case composerFieldContent:
+		c.content, cmd = c.content.Update(msg)
+
+	case composerFieldPriority:
+		switch {
+		case key.Matches(msg, c.keyMap.Decrease):
+			c.priority = max(1, c.priority-1)
+		case key.Matches(msg, c.keyMap.Increase):
+			c.priority = min(10, c.priority+1)
+		case len(msg.Runes) == 1 && msg.Runes[0] >= '0' && msg.Runes[0] <= '9':
+			// 1-9 pick a priority directly, 0 picks 10
+			c.priority = int(msg.Runes[0] - '0')
+			if c.priority == 0 {
+				c.priority = 10
+			}
+		}
+
+	case composerFieldWhen:
+		c.when, cmd = c.when.Update(msg)
+	}
+
+	c.err = ""
+	return c, cmd
+}
+
+// handlePickerKeys handles key presses in the template picker
+func (c *Composer) handlePickerKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+t":
+		c.picking = false
+	case "up", "k":
+		c.template = (c.template + len(c.templates) - 1) % len(c.templates)
+	case "down", "j":
+		c.template = (c.template + 1) % len(c.templates)
+	case "enter":
+		c.picking = false
+		cmd := c.setFocus(composerFieldContent)
+		c.content.InsertString(c.templates[c.template].Content)
+		return cmd
+	}
+	return nil
+}
+
+// setFocus moves the focus to a field
+func (c *Composer) setFocus(field int) tea.Cmd {
+	c.focus = field
+	c.content.Blur()
+	c.when.Blur()
+
+	switch field {
+	case composerFieldContent:
+		return c.content.Focus()
+	case composerFieldWhen:
+		return c.when.Focus()
+	}
+	return nil
+}
+
+// openEditor suspends the TUI to edit the message in $VISUAL or $EDITOR
+func (c *Composer) openEditor() tea.Cmd {
+	file, err := os.CreateTemp("", "tcs-message-*.md")
+	if err != nil {
+		c.err = fmt.Sprintf("Could not create a file for the editor: %v", err)
+		return nil
+	}
+	path := file.Name()
+	_, err = file.WriteString(c.content.Value())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		c.err = fmt.Sprintf("Could not write the message for the editor: %v", err)
+		return nil
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may come with arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return ComposerEditedMsg{Err: err}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ComposerEditedMsg{Err: err}
+		}
+		return ComposerEditedMsg{Content: strings.TrimRight(string(data), "\n")}
+	})
+}
+
+// Value validates the form and returns the message to schedule
+func (c *Composer) Value(now time.Time) (ComposedMessage, error) {
+	if len(c.windows) == 0 {
+		return ComposedMessage{}, fmt.Errorf("no active windows to send to")
+	}
+
+	content := strings.TrimSpace(c.content.Value())
+	if content == "" {
+		return ComposedMessage{}, fmt.Errorf("message cannot be empty")
+	}
+
+	scheduledTime, err := c.scheduledTime(now)
+	if err != nil {
+		return ComposedMessage{}, err
+	}
+
+	return ComposedMessage{
+		Target:        c.windows[c.window].Target,
+		Content:       content,
+		Priority:      c.priority,
+		ScheduledTime: scheduledTime,
+	}, nil
+}
+
+// scheduledTime resolves the when field, which defaults to now
+func (c *Composer) scheduledTime(now time.Time) (time.Time, error) {
+	when := strings.TrimSpace(c.when.Value())
+	if when == "" {
+		when = "now"
+	}
+	return utils.ParseScheduleTime(when, now)
+}
+
+// View renders the composer
+func (c *Composer) View() string {
+	sections := []string{c.titleStyle.Render("Compose Message")}
+
+	sections = append(sections, c.label(composerFieldWindow, "Window")+c.renderWindowPicker())
+
+	sections = append(sections, "", c.label(composerFieldContent, "Message"))
+	if c.picking {
+		sections = append(sections, c.renderTemplatePicker())
+	} else {
+		sections = append(sections, c.boxStyle.Render(c.content.View()))
+	}
+
+	sections = append(sections, c.label(composerFieldPriority, "Priority")+c.renderPrioritySlider())
+	sections = append(sections, c.label(composerFieldWhen, "When")+c.when.View())
+	sections = append(sections, c.label(-1, "")+c.renderWhenPreview())
+
+	if c.err != "" {
+		sections = append(sections, "", c.errorStyle.Render("✗ "+c.err))
+	}
+
+	k := c.keyMap
+	sections = append(sections, "", c.hintStyle.Render(
+		keys.Hint(k.Submit, k.Cancel, k.NextField, k.Decrease, k.Increase, k.Templates, k.Editor)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// label renders a field label, highlighted when the field has focus
+func (c *Composer) label(field int, text string) string {
+	if field == c.focus {
+		return c.focusedLabelStyle.Render("▸ " + text)
+	}
+	return c.labelStyle.Render("  " + text)
+}
+
+// renderWindowPicker renders the picked window with arrows to change it
+func (c *Composer) renderWindowPicker() string {
+	if len(c.windows) == 0 {
+		return c.errorStyle.Render("no active windows")
+	}
+
+	window := c.windows[c.window]
+	name := window.Target
+	if window.WindowName != "" {
+		name += " (" + window.WindowName + ")"
+	}
+	return fmt.Sprintf("◀ %s ▶ %s", c.valueStyle.Render(name),
+		c.hintStyle.Render(fmt.Sprintf("%d/%d", c.window+1, len(c.windows))))
+}
+
+// renderPrioritySlider renders the priority as a slider from 1 to 10
+func (c *Composer) renderPrioritySlider() string {
+	slider := strings.Repeat("■", c.priority) + strings.Repeat("□", 10-c.priority)
+	return fmt.Sprintf("◀ %s ▶ %s", c.valueStyle.Render(slider), c.valueStyle.Render(fmt.Sprintf("%d", c.priority)))
+}
+
+// renderWhenPreview shows the time the when field resolves to
+func (c *Composer) renderWhenPreview() string {
+	now := time.Now()
+	scheduledTime, err := c.scheduledTime(now)
+	if err != nil {
+		return c.errorStyle.Render("✗ " + err.Error())
+	}
+
+	preview := "→ " + scheduledTime.Format("Mon Jan 2 15:04")
+	if wait := scheduledTime.Sub(now); wait >= time.Minute {
+		preview += " (in " + strings.TrimSuffix(wait.Round(time.Minute).String(), "0s") + ")"
+	} else {
+		preview += " (right away)"
+	}
+	return c.hintStyle.Render(preview)
+}
+
+// renderTemplatePicker renders the list of templates to insert
+func (c *Composer) renderTemplatePicker() string {
+	lines := []string{c.hintStyle.Render("Insert template (↑/↓, enter to insert, esc to close)")}
+	for i, template := range c.templates {
+		line := fmt.Sprintf("%-10s %s", template.Name, strings.ReplaceAll(template.Content, "\n", " "))
+		if width := c.width - 8; width > 10 {
+			line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+		}
+		if i == c.template {
+			line = c.selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return c.boxStyle.Render(strings.Join(lines, "\n"))
+}
@@ -8,10 +8,15 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/types"
+	"github.com/derekxwang/tcs/internal/utils"
 )
 
 // MessageTable represents a table component for displaying messages
@@ -23,13 +28,37 @@ type MessageTable struct {
 	showHeader bool
 	showFooter bool
 
+	// Selection for bulk actions
+	selected  map[uint]bool
+	rangeFrom uint // message where an open range starts, 0 when there is none
+
+	// Input for a bulk action or the select filter
+	prompt     textinput.Model
+	promptKind string // promptFilter or a bulk action kind, empty when closed
+	promptErr  string
+
+	// Key bindings
+	keyMap MessageTableKeyMap
+
 	// Styles
+	colors        theme.Theme // for colors that depend on the data
 	headerStyle   lipgloss.Style
 	footerStyle   lipgloss.Style
 	selectedStyle lipgloss.Style
 	pendingStyle  lipgloss.Style
 	sentStyle     lipgloss.Style
 	failedStyle   lipgloss.Style
+	hintStyle     lipgloss.Style
+	errorStyle    lipgloss.Style
+}
+
+// promptFilter is the prompt kind for selecting the messages matching a text
+const promptFilter = "filter"
+
+// MessageBulkMsg is sent when a bulk action is chosen for the selected messages
+type MessageBulkMsg struct {
+	IDs    []uint
+	Action database.BulkMessageAction
 }
 
 // MessageTableOptions holds configuration options for the message table
@@ -42,55 +71,82 @@ type MessageTableOptions struct {
 	Theme      string
 }
 
-// MessageTableKeyMap defines key bindings for the message table
+// MessageTableKeyMap defines the selection and bulk action key bindings of the
+// message table. The views using the table act on its rows with their own bindings.
 type MessageTableKeyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Select  key.Binding
-	Delete  key.Binding
-	SendNow key.Binding
-	Cancel  key.Binding
-	Refresh key.Binding
+	ToggleSelect   key.Binding
+	SelectRange    key.Binding
+	SelectMatching key.Binding
+	ClearSelection key.Binding
+	SetPriority    key.Binding
+	Reschedule     key.Binding
+	Retarget       key.Binding
+	SubmitPrompt   key.Binding
+	CancelPrompt   key.Binding
 }
 
 // DefaultMessageTableKeyMap returns the default message table key bindings
 func DefaultMessageTableKeyMap() MessageTableKeyMap {
 	return MessageTableKeyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "move up"),
+		ToggleSelect: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "select"),
 		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "move down"),
+		SelectRange: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "select range"),
 		),
-		Select: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
+		SelectMatching: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "select matching"),
+		),
+		ClearSelection: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear selection"),
 		),
-		Delete: key.NewBinding(
-			key.WithKeys("d", "delete"),
-			key.WithHelp("d", "delete"),
+		SetPriority: key.NewBinding(
+			key.WithKeys("="),
+			key.WithHelp("=", "priority"),
 		),
-		SendNow: key.NewBinding(
-			key.WithKeys("s"),
-			key.WithHelp("s", "send now"),
+		Reschedule: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "reschedule"),
 		),
-		Cancel: key.NewBinding(
-			key.WithKeys("c"),
-			key.WithHelp("c", "cancel"),
+		Retarget: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "retarget"),
+		),
+		SubmitPrompt: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "apply"),
 		),
-		Refresh: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
+		CancelPrompt: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
 		),
 	}
 }
 
+// Actions returns the bindings by their names in the tui.keys config
+func (k *MessageTableKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "toggle_select", Binding: &k.ToggleSelect},
+		{Name: "select_range", Binding: &k.SelectRange},
+		{Name: "select_matching", Binding: &k.SelectMatching},
+		{Name: "clear_selection", Binding: &k.ClearSelection},
+		{Name: "set_priority", Binding: &k.SetPriority},
+		{Name: "reschedule", Binding: &k.Reschedule},
+		{Name: "retarget", Binding: &k.Retarget},
+		{Name: "submit_prompt", Binding: &k.SubmitPrompt, Mode: "prompt"},
+		{Name: "cancel_prompt", Binding: &k.CancelPrompt, Mode: "prompt"},
+	}
+}
+
 // NewMessageTable creates a new message table component
 func NewMessageTable(opts MessageTableOptions) *MessageTable {
 	// Define table columns
 	columns := []table.Column{
+		{Title: "", Width: 1}, // selection mark
 		{Title: "ID", Width: 4},
 		{Title: "Session", Width: 12},
 		{Title: "Content", Width: 30},
@@ -107,116 +163,355 @@ func NewMessageTable(opts MessageTableOptions) *MessageTable {
 		table.WithHeight(opts.Height),
 	)
 
+	prompt := textinput.New()
+	prompt.CharLimit = 100
+
 	mt := &MessageTable{
 		table:      t,
 		width:      opts.Width,
 		height:     opts.Height,
 		showHeader: opts.ShowHeader,
 		showFooter: opts.ShowFooter,
+		selected:   make(map[uint]bool),
+		prompt:     prompt,
+		keyMap:     DefaultMessageTableKeyMap(),
 	}
 
 	mt.initStyles(opts.Theme)
 	return mt
 }
 
-// initStyles initializes the component styles
-func (mt *MessageTable) initStyles(theme string) {
-	switch theme {
-	case "dark":
-		mt.initDarkTheme()
-	case "light":
-		mt.initLightTheme()
-	default:
-		mt.initDefaultTheme()
+// initStyles initializes the component styles with the named theme, or the
+// current theme when no name is given
+func (mt *MessageTable) initStyles(name string) {
+	t := theme.Current()
+	if name != "" {
+		t, _ = theme.Get(name)
 	}
-}
+	mt.colors = t
 
-// initDefaultTheme initializes the default theme
-func (mt *MessageTable) initDefaultTheme() {
 	mt.headerStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("57")).
+		Foreground(t.SelectedText).
+		Background(t.Selected).
 		Padding(0, 1)
 
 	mt.footerStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(t.Muted).
 		Padding(0, 1)
 
 	mt.selectedStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("57"))
+		Foreground(t.SelectedText).
+		Background(t.Selected)
 
 	mt.pendingStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("11")) // Yellow
+		Foreground(t.Warning)
 
 	mt.sentStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")) // Green
+		Foreground(t.Success)
 
 	mt.failedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("9")) // Red
+		Foreground(t.Danger)
+
+	mt.hintStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	mt.errorStyle = lipgloss.NewStyle().
+		Foreground(t.Danger)
+
+	mt.table.SetStyles(t.TableStyles())
 }
 
-// initDarkTheme initializes the dark theme
-func (mt *MessageTable) initDarkTheme() {
-	mt.headerStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("255")).
-		Background(lipgloss.Color("240")).
-		Padding(0, 1)
+// Update handles messages for the message table
+func (mt *MessageTable) Update(msg tea.Msg) (*MessageTable, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if mt.promptKind != "" {
+			return mt, mt.handlePromptKeys(msg)
+		}
+		if cmd, handled := mt.handleSelectionKeys(msg); handled {
+			return mt, cmd
+		}
+	}
 
-	mt.footerStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245")).
-		Padding(0, 1)
+	var cmd tea.Cmd
+	mt.table, cmd = mt.table.Update(msg)
+	if mt.rangeFrom != 0 {
+		// The open range follows the cursor
+		mt.refreshRows()
+	}
+	return mt, cmd
+}
 
-	mt.selectedStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("255")).
-		Background(lipgloss.Color("240"))
+// handleSelectionKeys handles the selection keys and opens the prompts of the
+// bulk actions, reporting whether msg was one of them
+func (mt *MessageTable) handleSelectionKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, mt.keyMap.ToggleSelect):
+		if message := mt.GetSelectedMessage(); message != nil {
+			if mt.selected[message.ID] {
+				delete(mt.selected, message.ID)
+			} else {
+				mt.selected[message.ID] = true
+			}
+			mt.refreshRows()
+		}
 
-	mt.pendingStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("214")) // Orange
+	case key.Matches(msg, mt.keyMap.SelectRange):
+		// The first press starts a range at the cursor, the second selects it
+		if mt.rangeFrom != 0 {
+			for _, id := range mt.rangeIDs() {
+				mt.selected[id] = true
+			}
+			mt.rangeFrom = 0
+		} else if message := mt.GetSelectedMessage(); message != nil {
+			mt.rangeFrom = message.ID
+		}
+		mt.refreshRows()
 
-	mt.sentStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("34")) // Dark green
+	case key.Matches(msg, mt.keyMap.ClearSelection):
+		if len(mt.selected) == 0 && mt.rangeFrom == 0 {
+			return nil, false
+		}
+		mt.ClearSelection()
 
-	mt.failedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")) // Bright red
-}
+	case key.Matches(msg, mt.keyMap.SelectMatching):
+		return mt.openPrompt(promptFilter, "Select matching: ", "session, text or status"), true
 
-// initLightTheme initializes the light theme
-func (mt *MessageTable) initLightTheme() {
-	mt.headerStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("0")).
-		Background(lipgloss.Color("252")).
-		Padding(0, 1)
+	case key.Matches(msg, mt.keyMap.SetPriority):
+		return mt.openBulkPrompt(database.BulkPriority, "Priority for %s: ", "1-10"), true
 
-	mt.footerStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Padding(0, 1)
+	case key.Matches(msg, mt.keyMap.Reschedule):
+		return mt.openBulkPrompt(database.BulkReschedule, "Reschedule %s: ", "+30m or -1h to shift, or a time like 15:04"), true
 
-	mt.selectedStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("255")).
-		Background(lipgloss.Color("240"))
+	case key.Matches(msg, mt.keyMap.Retarget):
+		return mt.openBulkPrompt(database.BulkRetarget, "Move %s to: ", "session:window"), true
 
-	mt.pendingStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("130")) // Dark orange
+	default:
+		return nil, false
+	}
+	return nil, true
+}
 
-	mt.sentStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("28")) // Dark green
+// openBulkPrompt opens the prompt of a bulk action, if there are messages to act on
+func (mt *MessageTable) openBulkPrompt(kind, label, placeholder string) tea.Cmd {
+	ids := mt.TargetIDs()
+	if len(ids) == 0 {
+		return nil
+	}
+	return mt.openPrompt(kind, fmt.Sprintf(label, countMessages(len(ids))), placeholder)
+}
 
-	mt.failedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("124")) // Dark red
+// openPrompt shows the prompt under the table
+func (mt *MessageTable) openPrompt(kind, label, placeholder string) tea.Cmd {
+	mt.promptKind = kind
+	mt.promptErr = ""
+	mt.prompt.Prompt = label
+	mt.prompt.Placeholder = placeholder
+	mt.prompt.SetValue("")
+	return mt.prompt.Focus()
 }
 
-// Update handles messages for the message table
-func (mt *MessageTable) Update(msg tea.Msg) (*MessageTable, tea.Cmd) {
+// closePrompt hides the prompt
+func (mt *MessageTable) closePrompt() {
+	mt.promptKind = ""
+	mt.promptErr = ""
+	mt.prompt.Blur()
+}
+
+// handlePromptKeys handles key presses while the prompt is open
+func (mt *MessageTable) handlePromptKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, mt.keyMap.CancelPrompt):
+		mt.closePrompt()
+		return nil
+
+	case key.Matches(msg, mt.keyMap.SubmitPrompt):
+		return mt.submitPrompt()
+	}
+
 	var cmd tea.Cmd
-	mt.table, cmd = mt.table.Update(msg)
-	return mt, cmd
+	mt.prompt, cmd = mt.prompt.Update(msg)
+	return cmd
+}
+
+// submitPrompt selects the matching messages, or sends the bulk action for the
+// selected ones and clears the selection
+func (mt *MessageTable) submitPrompt() tea.Cmd {
+	value := strings.TrimSpace(mt.prompt.Value())
+	if mt.promptKind == promptFilter {
+		mt.SelectMatching(value)
+		mt.closePrompt()
+		return nil
+	}
+
+	action, err := parseBulkAction(mt.promptKind, value)
+	if err != nil {
+		mt.promptErr = err.Error()
+		return nil
+	}
+
+	ids := mt.TargetIDs()
+	mt.closePrompt()
+	mt.ClearSelection()
+	return func() tea.Msg {
+		return MessageBulkMsg{IDs: ids, Action: action}
+	}
+}
+
+// parseBulkAction reads the value typed for a bulk action
+func parseBulkAction(kind, value string) (database.BulkMessageAction, error) {
+	action := database.BulkMessageAction{Kind: kind}
+	switch kind {
+	case database.BulkPriority:
+		priority, err := strconv.Atoi(value)
+		if err != nil || priority < 1 || priority > 10 {
+			return action, fmt.Errorf("priority must be a number from 1 to 10")
+		}
+		action.Priority = priority
+
+	case database.BulkReschedule:
+		// A signed duration shifts the times, anything else replaces them
+		if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+			shift, err := time.ParseDuration(value)
+			if err != nil {
+				return action, fmt.Errorf("invalid shift %q: use formats like +30m or -1h", value)
+			}
+			action.Shift = shift
+			break
+		}
+		at, err := utils.ParseScheduleTime(value, time.Now())
+		if err != nil {
+			return action, err
+		}
+		action.ScheduleAt = &at
+
+	case database.BulkRetarget:
+		if value == "" {
+			return action, fmt.Errorf("target cannot be empty")
+		}
+		action.Target = value
+	}
+	return action, nil
+}
+
+// countMessages names a number of messages
+func countMessages(n int) string {
+	if n == 1 {
+		return "1 message"
+	}
+	return fmt.Sprintf("%d messages", n)
+}
+
+// IsPromptActive returns true while the prompt takes typed text
+func (mt *MessageTable) IsPromptActive() bool {
+	return mt.promptKind != ""
+}
+
+// SelectedIDs returns the IDs of the selected messages, including an open
+// range, in table order
+func (mt *MessageTable) SelectedIDs() []uint {
+	inRange := make(map[uint]bool)
+	for _, id := range mt.rangeIDs() {
+		inRange[id] = true
+	}
+
+	var ids []uint
+	for _, message := range mt.messages {
+		if mt.selected[message.ID] || inRange[message.ID] {
+			ids = append(ids, message.ID)
+		}
+	}
+	return ids
+}
+
+// TargetIDs returns the IDs of the selected messages, or of the one under the
+// cursor when none are selected
+func (mt *MessageTable) TargetIDs() []uint {
+	if ids := mt.SelectedIDs(); len(ids) > 0 {
+		return ids
+	}
+	if message := mt.GetSelectedMessage(); message != nil {
+		return []uint{message.ID}
+	}
+	return nil
+}
+
+// SelectMatching adds the messages whose session, content or status contains
+// text to the selection
+func (mt *MessageTable) SelectMatching(text string) {
+	text = strings.ToLower(text)
+	if text == "" {
+		return
+	}
+	for _, message := range mt.messages {
+		if strings.Contains(strings.ToLower(message.SessionName), text) ||
+			strings.Contains(strings.ToLower(message.Content), text) ||
+			strings.Contains(strings.ToLower(message.Status), text) {
+			mt.selected[message.ID] = true
+		}
+	}
+	mt.refreshRows()
+}
+
+// ClearSelection unselects every message and closes an open range
+func (mt *MessageTable) ClearSelection() {
+	mt.selected = make(map[uint]bool)
+	mt.rangeFrom = 0
+	mt.refreshRows()
+}
+
+// rangeIDs returns the IDs of the messages between the start of an open range
+// and the cursor
+func (mt *MessageTable) rangeIDs() []uint {
+	if mt.rangeFrom == 0 {
+		return nil
+	}
+	from := -1
+	for i, message := range mt.messages {
+		if message.ID == mt.rangeFrom {
+			from = i
+		}
+	}
+	to := mt.table.Cursor()
+	if from < 0 || to < 0 || to >= len(mt.messages) {
+		return nil
+	}
+
+	var ids []uint
+	for i := min(from, to); i <= max(from, to); i++ {
+		ids = append(ids, mt.messages[i].ID)
+	}
+	return ids
+}
+
+// KeyActions returns the selection and bulk action bindings for remapping
+func (mt *MessageTable) KeyActions() []keys.Action {
+	return mt.keyMap.Actions()
+}
+
+// HandleMouse scrolls the table with the wheel and moves the cursor to a
+// clicked row, where y is the line of the table's view
+func (mt *MessageTable) HandleMouse(msg tea.MouseMsg, y int) {
+	if ScrollTable(&mt.table, msg) || !IsLeftClick(msg) {
+		return
+	}
+	if row, ok := TableRowAt(mt.table, y); ok {
+		mt.table.SetCursor(row)
+	}
+	if mt.rangeFrom != 0 {
+		mt.refreshRows()
+	}
+}
+
+// Hint renders the selection and bulk action keys, or the prompt keys while
+// the prompt is open
+func (mt *MessageTable) Hint() string {
+	k := mt.keyMap
+	if mt.promptKind != "" {
+		return keys.Hint(k.SubmitPrompt, k.CancelPrompt)
+	}
+	return keys.Hint(k.ToggleSelect, k.SelectRange, k.SelectMatching, k.SetPriority, k.Reschedule, k.Retarget)
 }
 
 // View renders the message table
@@ -229,8 +524,17 @@ func (mt *MessageTable) View() string {
 		sections = append(sections, mt.headerStyle.Width(mt.width).Render(header))
 	}
 
-	// Table
+	// Table, then the prompt or the size of the selection
 	sections = append(sections, mt.table.View())
+	if mt.promptKind != "" {
+		line := mt.prompt.View()
+		if mt.promptErr != "" {
+			line += "  " + mt.errorStyle.Render(mt.promptErr)
+		}
+		sections = append(sections, line)
+	} else if ids := mt.SelectedIDs(); len(ids) > 0 {
+		sections = append(sections, mt.hintStyle.Render(fmt.Sprintf("%s selected", countMessages(len(ids)))))
+	}
 
 	// Footer with summary
 	if mt.showFooter {
@@ -293,14 +597,43 @@ func (mt *MessageTable) renderFooter() string {
 	return mt.footerStyle.Width(mt.width).Render(footerText)
 }
 
-// SetMessages updates the messages displayed in the table
+// SetMessages updates the messages displayed in the table, keeping the
+// selection of those still listed
 func (mt *MessageTable) SetMessages(messages []types.MessageDisplayInfo) {
 	mt.messages = messages
 
+	listed := make(map[uint]bool, len(messages))
+	for _, message := range messages {
+		listed[message.ID] = true
+	}
+	for id := range mt.selected {
+		if !listed[id] {
+			delete(mt.selected, id)
+		}
+	}
+	if !listed[mt.rangeFrom] {
+		mt.rangeFrom = 0
+	}
+
+	mt.refreshRows()
+
+	// The table leaves the cursor past the end when rows go away
+	if cursor := mt.table.Cursor(); cursor >= len(messages) {
+		mt.table.SetCursor(cursor)
+	}
+}
+
+// refreshRows rebuilds the table rows
+func (mt *MessageTable) refreshRows() {
+	inRange := make(map[uint]bool)
+	for _, id := range mt.rangeIDs() {
+		inRange[id] = true
+	}
+
 	// Convert messages to table rows
 	var rows []table.Row
 
-	for _, msg := range messages {
+	for _, msg := range mt.messages {
 		// Truncate content for display
 		content := msg.Content
 		if len(content) > 27 {
@@ -318,8 +651,14 @@ func (mt *MessageTable) SetMessages(messages []types.MessageDisplayInfo) {
 			}
 		}
 
+		mark := " "
+		if mt.selected[msg.ID] || inRange[msg.ID] {
+			mark = "✓"
+		}
+
 		// Create row with appropriate styling
 		row := table.Row{
+			mark,
 			strconv.Itoa(int(msg.ID)),
 			msg.SessionName,
 			content,
@@ -344,6 +683,10 @@ func (mt *MessageTable) formatStatus(status string) string {
 		return mt.sentStyle.Render("sent")
 	case types.MessageStatusFailed:
 		return mt.failedStyle.Render("failed")
+	case types.MessageStatusSending:
+		return mt.pendingStyle.Render("sending")
+	case types.MessageStatusExpired:
+		return mt.failedStyle.Render("expired")
 	default:
 		return status
 	}
@@ -368,6 +711,14 @@ func (mt *MessageTable) GetSelectedIndex() int {
 	return mt.table.Cursor()
 }
 
+// SetSelectedIndex moves the cursor to the message at index
+func (mt *MessageTable) SetSelectedIndex(index int) {
+	mt.table.SetCursor(index)
+	if mt.rangeFrom != 0 {
+		mt.refreshRows()
+	}
+}
+
 // SetSize updates the table size
 func (mt *MessageTable) SetSize(width, height int) {
 	mt.width = width
@@ -381,13 +732,13 @@ func (mt *MessageTable) SetSize(width, height int) {
 	columns := mt.table.Columns()
 	if len(columns) > 0 && width > 50 {
 		// Calculate available width for content column
-		fixedWidth := 4 + 12 + 8 + 8 + 16 + 12 + 7 // Other columns + padding
+		fixedWidth := 1 + 4 + 12 + 8 + 8 + 16 + 12 + 8 // Other columns + padding
 		contentWidth := width - fixedWidth
 		if contentWidth < 15 {
 			contentWidth = 15
 		}
 
-		columns[2].Width = contentWidth // Content column
+		columns[3].Width = contentWidth // Content column
 		mt.table.SetColumns(columns)
 	}
 }
@@ -498,8 +849,9 @@ func (mt *MessageTable) GetStats() map[string]int {
 
 // CompactMessageList creates a compact list view of messages
 func CompactMessageList(messages []types.MessageDisplayInfo, width int, maxItems int) string {
+	t := theme.Current()
 	if len(messages) == 0 {
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("No messages")
+		return lipgloss.NewStyle().Foreground(t.Muted).Render("No messages")
 	}
 
 	var lines []string
@@ -521,13 +873,13 @@ func CompactMessageList(messages []types.MessageDisplayInfo, width int, maxItems
 		var style lipgloss.Style
 		switch msg.Status {
 		case types.MessageStatusPending:
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+			style = lipgloss.NewStyle().Foreground(t.Warning)
 		case types.MessageStatusSent:
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+			style = lipgloss.NewStyle().Foreground(t.Success)
 		case types.MessageStatusFailed:
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			style = lipgloss.NewStyle().Foreground(t.Danger)
 		default:
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("15"))
+			style = lipgloss.NewStyle().Foreground(t.Text)
 		}
 
 		line := fmt.Sprintf("• %s [%s] %s", msg.SessionName, msg.Status, content)
@@ -537,7 +889,7 @@ func CompactMessageList(messages []types.MessageDisplayInfo, width int, maxItems
 	// Add "and X more" if truncated
 	if len(messages) > displayCount {
 		more := fmt.Sprintf("  ... and %d more", len(messages)-displayCount)
-		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(more))
+		lines = append(lines, lipgloss.NewStyle().Foreground(t.Muted).Render(more))
 	}
 
 	return strings.Join(lines, "\n")
@@ -545,6 +897,7 @@ func CompactMessageList(messages []types.MessageDisplayInfo, width int, maxItems
 
 // MessageSummaryLine creates a one-line summary of messages
 func MessageSummaryLine(messages []types.MessageDisplayInfo) string {
+	t := theme.Current()
 	if len(messages) == 0 {
 		return "No messages"
 	}
@@ -569,19 +922,19 @@ func MessageSummaryLine(messages []types.MessageDisplayInfo) string {
 	var parts []string
 
 	if stats["pending"] > 0 {
-		part := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+		part := lipgloss.NewStyle().Foreground(t.Warning).Render(
 			fmt.Sprintf("%d pending", stats["pending"]))
 		parts = append(parts, part)
 	}
 
 	if stats["sent"] > 0 {
-		part := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render(
+		part := lipgloss.NewStyle().Foreground(t.Success).Render(
 			fmt.Sprintf("%d sent", stats["sent"]))
 		parts = append(parts, part)
 	}
 
 	if stats["failed"] > 0 {
-		part := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(
+		part := lipgloss.NewStyle().Foreground(t.Danger).Render(
 			fmt.Sprintf("%d failed", stats["failed"]))
 		parts = append(parts, part)
 	}
@@ -0,0 +1,72 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/derekxwang/tcs/internal/tui/theme"
+)
+
+// wheelRows is how many rows a scroll wheel step moves the cursor
+const wheelRows = 3
+
+// cursorMarker marks the cursor row when locating it in a table's view
+const cursorMarker = "\uE000"
+
+// IsLeftClick reports whether msg is a press of the left mouse button
+func IsLeftClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+// ScrollTable moves the table's cursor for a scroll wheel event and reports
+// whether msg was one
+func ScrollTable(t *table.Model, msg tea.MouseMsg) bool {
+	if msg.Action != tea.MouseActionPress {
+		return false
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		t.MoveUp(wheelRows)
+		return true
+	case tea.MouseButtonWheelDown:
+		t.MoveDown(wheelRows)
+		return true
+	}
+	return false
+}
+
+// TableRowAt returns the row shown on line y of the table's view, where line 0
+// is the top of the header. The table doesn't expose which rows it scrolled
+// to, so the cursor row is found by rendering a copy with it marked.
+func TableRowAt(t table.Model, y int) (int, bool) {
+	rows := len(t.Rows())
+	if rows == 0 || y < 0 {
+		return 0, false
+	}
+
+	styles := theme.Current().TableStyles()
+	styles.Selected = lipgloss.NewStyle().Transform(func(s string) string {
+		return cursorMarker + s
+	})
+	marked := t
+	marked.SetStyles(styles)
+
+	// Height is the rows shown below the header, which may differ between
+	// the styles of the table and the copy
+	header := lipgloss.Height(t.View()) - t.Height()
+	if y < header || y >= header+t.Height() {
+		return 0, false
+	}
+	lines := strings.Split(marked.View(), "\n")
+	markedHeader := len(lines) - t.Height()
+	for i, line := range lines {
+		if strings.Contains(line, cursorMarker) {
+			row := t.Cursor() + (y - header) - (i - markedHeader)
+			return row, row >= 0 && row < rows
+		}
+	}
+	return 0, false
+}
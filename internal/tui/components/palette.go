@@ -0,0 +1,317 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+	"github.com/derekxwang/tcs/internal/utils"
+)
+
+// Kinds of palette items
+const (
+	PaletteAction  = "action"
+	PaletteWindow  = "window"
+	PaletteMessage = "message"
+)
+
+// paletteResults is the most results the palette lists
+const paletteResults = 12
+
+// PaletteItem is something the command palette can jump to or run
+type PaletteItem struct {
+	Kind   string // PaletteAction, PaletteWindow or PaletteMessage
+	Title  string // matched and shown first
+	Detail string // matched with a lower weight and shown dimmed
+	Value  string // action name or window target
+	ID     uint   // message ID
+	Failed bool   // the message is in the failed tab
+}
+
+// PalettePickMsg is sent when an item is picked in the palette
+type PalettePickMsg struct {
+	Item PaletteItem
+}
+
+// PaletteCloseMsg is sent when the palette is closed without picking an item
+type PaletteCloseMsg struct{}
+
+// PaletteKeyMap defines key bindings for the command palette
+type PaletteKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Pick   key.Binding
+	Cancel key.Binding
+}
+
+// DefaultPaletteKeyMap returns the default palette key bindings
+func DefaultPaletteKeyMap() PaletteKeyMap {
+	return PaletteKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "ctrl+k", "shift+tab"),
+			key.WithHelp("↑", "previous"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "ctrl+j", "tab"),
+			key.WithHelp("↓", "next"),
+		),
+		Pick: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "go"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "close"),
+		),
+	}
+}
+
+// Actions returns the bindings by their names in the tui.keys config
+func (k *PaletteKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "up", Binding: &k.Up},
+		{Name: "down", Binding: &k.Down},
+		{Name: "pick", Binding: &k.Pick},
+		{Name: "cancel", Binding: &k.Cancel},
+	}
+}
+
+// Palette is a command palette that fuzzy matches actions, windows and messages
+type Palette struct {
+	input   textinput.Model
+	items   []PaletteItem
+	matches []PaletteItem
+	cursor  int
+	width   int
+
+	// Key bindings
+	keyMap PaletteKeyMap
+
+	// Styles
+	boxStyle      lipgloss.Style
+	kindStyle     lipgloss.Style
+	detailStyle   lipgloss.Style
+	selectedStyle lipgloss.Style
+	hintStyle     lipgloss.Style
+}
+
+// NewPalette creates a new command palette
+func NewPalette() *Palette {
+	input := textinput.New()
+	input.Placeholder = "action, window or message"
+	input.Prompt = ": "
+	input.CharLimit = 100
+
+	p := &Palette{
+		input:  input,
+		keyMap: DefaultPaletteKeyMap(),
+	}
+	p.initStyles(theme.Current())
+	return p
+}
+
+// KeySection returns the palette's key bindings for remapping and the help
+// view. The palette takes all keys while it is open.
+func (p *Palette) KeySection() keys.Section {
+	return keys.Section{Name: "palette", Title: "Command Palette", Actions: p.keyMap.Actions(), Modal: true}
+}
+
+// SetTheme restyles the palette with the named theme
+func (p *Palette) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	p.initStyles(t)
+}
+
+// initStyles initializes the palette styles
+func (p *Palette) initStyles(t theme.Theme) {
+	p.boxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Accent).
+		Padding(0, 1)
+
+	p.kindStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Width(8)
+
+	p.detailStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	p.selectedStyle = lipgloss.NewStyle().
+		Foreground(t.SelectedText).
+		Background(t.Selected).
+		Bold(true)
+
+	p.hintStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+}
+
+// Open clears the query and focuses the input
+func (p *Palette) Open() tea.Cmd {
+	p.input.SetValue("")
+	p.cursor = 0
+	p.filter()
+	return p.input.Focus()
+}
+
+// SetItems sets the items to search, keeping the query
+func (p *Palette) SetItems(items []PaletteItem) {
+	p.items = items
+	p.filter()
+}
+
+// SetSize sets the palette width
+func (p *Palette) SetSize(width, height int) {
+	p.width = min(100, max(30, width-4))
+	p.input.Width = p.width - 8
+}
+
+// Matches returns the items matching the query, best first
+func (p *Palette) Matches() []PaletteItem {
+	return p.matches
+}
+
+// Update handles messages for the palette
+func (p *Palette) Update(msg tea.Msg) (*Palette, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		// Cursor blinking
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return p, cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, p.keyMap.Cancel):
+		p.input.Blur()
+		return p, func() tea.Msg { return PaletteCloseMsg{} }
+
+	case key.Matches(keyMsg, p.keyMap.Pick):
+		if p.cursor >= len(p.matches) {
+			return p, nil
+		}
+		item := p.matches[p.cursor]
+		p.input.Blur()
+		return p, func() tea.Msg { return PalettePickMsg{Item: item} }
+
+	case key.Matches(keyMsg, p.keyMap.Up):
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return p, nil
+
+	case key.Matches(keyMsg, p.keyMap.Down):
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+		return p, nil
+	}
+
+	query := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != query {
+		p.cursor = 0
+		p.filter()
+	}
+	return p, cmd
+}
+
+// filter ranks the items matching the query. Without a query the items keep
+// their order, so actions come first.
+func (p *Palette) filter() {
+	query := strings.TrimSpace(p.input.Value())
+
+	type scored struct {
+		item  PaletteItem
+		score int
+	}
+	var results []scored
+	for _, item := range p.items {
+		score, ok := utils.FuzzyMatch(query, item.Title)
+		if detailScore, detailOK := utils.FuzzyMatch(query, item.Detail); detailOK && (!ok || detailScore-10 > score) {
+			// Matching only the detail ranks below matching the title
+			score, ok = detailScore-10, true
+		}
+		if ok {
+			results = append(results, scored{item: item, score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	p.matches = p.matches[:0]
+	for _, result := range results {
+		p.matches = append(p.matches, result.item)
+	}
+	p.cursor = min(p.cursor, max(0, len(p.matches)-1))
+}
+
+// View renders the palette
+func (p *Palette) View() string {
+	width := max(30, p.width)
+	lines := []string{p.input.View(), ""}
+
+	// Keep the cursor in the listed window of results
+	start := max(0, p.cursor-paletteResults+1)
+	end := min(len(p.matches), start+paletteResults)
+	for i := start; i < end; i++ {
+		lines = append(lines, p.renderItem(p.matches[i], i == p.cursor, width-4))
+	}
+	if len(p.matches) == 0 {
+		lines = append(lines, p.hintStyle.Render("No matches"))
+	}
+
+	k := p.keyMap
+	lines = append(lines, "", p.hintStyle.Render(fmt.Sprintf(
+		"%d matches • %s", len(p.matches), keys.Hint(k.Up, k.Down, k.Pick, k.Cancel))))
+
+	return p.boxStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// renderItem renders an item as one line of at most width characters
+func (p *Palette) renderItem(item PaletteItem, selected bool, width int) string {
+	title := strings.ReplaceAll(item.Title, "\n", " ")
+	detail := strings.ReplaceAll(item.Detail, "\n", " ")
+
+	room := max(10, width-10)
+	title = truncate(title, room)
+	if detail != "" && lipgloss.Width(title)+3 < room {
+		detail = truncate(detail, room-lipgloss.Width(title)-2)
+	} else {
+		detail = ""
+	}
+
+	if selected {
+		line := fmt.Sprintf("%-8s%s", item.Kind, title)
+		if detail != "" {
+			line += "  " + detail
+		}
+		return p.selectedStyle.Width(width).Render(line)
+	}
+
+	line := p.kindStyle.Render(item.Kind) + title
+	if detail != "" {
+		line += "  " + p.detailStyle.Render(detail)
+	}
+	return line
+}
+
+// truncate shortens text to at most width characters
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/types"
 )
 
@@ -18,6 +19,7 @@ type UsageBar struct {
 	showDetails bool
 
 	// Styles
+	colors       theme.Theme // for colors that depend on the data
 	barStyle     lipgloss.Style
 	filledStyle  lipgloss.Style
 	emptyStyle   lipgloss.Style
@@ -56,97 +58,38 @@ func NewUsageBar(opts UsageBarOptions) *UsageBar {
 	return ub
 }
 
-// initStyles initializes the component styles
-func (ub *UsageBar) initStyles(theme string) {
-	switch theme {
-	case "dark":
-		ub.initDarkTheme()
-	case "light":
-		ub.initLightTheme()
-	default:
-		ub.initDefaultTheme()
+// initStyles initializes the component styles with the named theme, or the
+// current theme when no name is given
+func (ub *UsageBar) initStyles(name string) {
+	t := theme.Current()
+	if name != "" {
+		t, _ = theme.Get(name)
 	}
-}
-
-// initDefaultTheme initializes the default theme
-func (ub *UsageBar) initDefaultTheme() {
-	ub.barStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1)
-
-	ub.filledStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("10")) // Green
-
-	ub.emptyStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("0")) // Black
-
-	ub.labelStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("15")).
-		Bold(true)
-
-	ub.detailStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8"))
-
-	ub.warningStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("11")) // Yellow
-
-	ub.dangerStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("9")) // Red
-}
-
-// initDarkTheme initializes the dark theme
-func (ub *UsageBar) initDarkTheme() {
-	ub.barStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("241")).
-		Padding(0, 1)
-
-	ub.filledStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("34")) // Dark green
-
-	ub.emptyStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("235")) // Dark gray
-
-	ub.labelStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("255")).
-		Bold(true)
-
-	ub.detailStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
-
-	ub.warningStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("214")) // Orange
-
-	ub.dangerStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("196")) // Bright red
-}
+	ub.colors = t
 
-// initLightTheme initializes the light theme
-func (ub *UsageBar) initLightTheme() {
 	ub.barStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(t.Muted).
 		Padding(0, 1)
 
 	ub.filledStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("28")) // Light green
+		Background(t.Success)
 
 	ub.emptyStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("251")) // Light gray
+		Background(t.Background)
 
 	ub.labelStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("0")).
+		Foreground(t.Text).
 		Bold(true)
 
 	ub.detailStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))
+		Foreground(t.Muted)
 
 	ub.warningStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("220")) // Light yellow
+		Background(t.Warning)
 
 	ub.dangerStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("203")) // Light red
+		Background(t.Danger)
 }
 
 // Render renders the usage bar with the given usage statistics
@@ -261,20 +204,20 @@ func (ub *UsageBar) renderDetails(stats types.UsageStats) string {
 
 	// Status information
 	var statusIcon, statusText string
-	statusColor := lipgloss.Color("10") // Green
+	statusColor := ub.colors.Success
 
 	if !stats.CanSendMessage {
 		statusIcon = "✗"
 		statusText = "Cannot send messages"
-		statusColor = lipgloss.Color("9") // Red
+		statusColor = ub.colors.Danger
 	} else if stats.UsagePercentage > 0.9 {
 		statusIcon = "⚠"
 		statusText = "Critical usage level"
-		statusColor = lipgloss.Color("9") // Red
+		statusColor = ub.colors.Danger
 	} else if stats.UsagePercentage > 0.7 {
 		statusIcon = "⚠"
 		statusText = "High usage level"
-		statusColor = lipgloss.Color("11") // Yellow
+		statusColor = ub.colors.Warning
 	} else {
 		statusIcon = "✓"
 		statusText = "Normal usage level"
@@ -316,6 +259,7 @@ func (ub *UsageBar) SetShowDetails(show bool) {
 
 // CompactUsageBar creates a compact single-line usage bar
 func CompactUsageBar(stats types.UsageStats, width int) string {
+	t := theme.Current()
 	if width < 10 {
 		return "Usage: N/A"
 	}
@@ -340,18 +284,18 @@ func CompactUsageBar(stats types.UsageStats, width int) string {
 
 	if percentage > 0.9 {
 		fillChar = "█"
-		fillColor = lipgloss.Color("9") // Red
+		fillColor = t.Danger
 	} else if percentage > 0.7 {
 		fillChar = "█"
-		fillColor = lipgloss.Color("11") // Yellow
+		fillColor = t.Warning
 	} else {
 		fillChar = "█"
-		fillColor = lipgloss.Color("10") // Green
+		fillColor = t.Success
 	}
 	emptyChar = "░"
 
 	filledPart := lipgloss.NewStyle().Foreground(fillColor).Render(strings.Repeat(fillChar, filledWidth))
-	emptyPart := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(strings.Repeat(emptyChar, emptyWidth))
+	emptyPart := lipgloss.NewStyle().Foreground(t.Muted).Render(strings.Repeat(emptyChar, emptyWidth))
 
 	// Add percentage and status
 	percentageText := fmt.Sprintf("%.0f%%", percentage*100)
@@ -367,6 +311,7 @@ func CompactUsageBar(stats types.UsageStats, width int) string {
 
 // MiniUsageIndicator creates a minimal usage indicator
 func MiniUsageIndicator(stats types.UsageStats) string {
+	t := theme.Current()
 	percentage := stats.UsagePercentage
 	if percentage > 1.0 {
 		percentage = 1.0
@@ -377,19 +322,19 @@ func MiniUsageIndicator(stats types.UsageStats) string {
 
 	if !stats.CanSendMessage {
 		indicator = "●"
-		color = lipgloss.Color("9") // Red
+		color = t.Danger
 	} else if percentage > 0.9 {
 		indicator = "●"
-		color = lipgloss.Color("9") // Red
+		color = t.Danger
 	} else if percentage > 0.7 {
 		indicator = "●"
-		color = lipgloss.Color("11") // Yellow
+		color = t.Warning
 	} else if percentage > 0.4 {
 		indicator = "●"
-		color = lipgloss.Color("10") // Green
+		color = t.Success
 	} else {
 		indicator = "○"
-		color = lipgloss.Color("8") // Gray
+		color = t.Muted
 	}
 
 	return lipgloss.NewStyle().Foreground(color).Render(indicator)
@@ -422,6 +367,7 @@ func UsageBarWithHistory(currentStats types.UsageStats, history []float64, width
 
 // createSparkline creates a simple text-based sparkline
 func createSparkline(data []float64, width int) string {
+	t := theme.Current()
 	if len(data) == 0 || width == 0 {
 		return ""
 	}
@@ -459,11 +405,11 @@ func createSparkline(data []float64, width int) string {
 		// Color based on value
 		var color lipgloss.Color
 		if normalized > 0.8 {
-			color = lipgloss.Color("9") // Red
+			color = t.Danger
 		} else if normalized > 0.6 {
-			color = lipgloss.Color("11") // Yellow
+			color = t.Warning
 		} else {
-			color = lipgloss.Color("10") // Green
+			color = t.Success
 		}
 
 		char := lipgloss.NewStyle().Foreground(color).Render(chars[charIndex])
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+)
+
+// keySections returns the key bindings of the app, every view and the palette
+func (a *App) keySections() []keys.Section {
+	sections := []keys.Section{{Name: keys.Global, Title: "Global", Actions: a.keyMap.Actions()}}
+	sections = append(sections, a.windows.KeySections()...)
+	sections = append(sections, a.messages.KeySections()...)
+	sections = append(sections, a.schedulerView.KeySections()...)
+	sections = append(sections, a.eventsView.KeySections()...)
+	sections = append(sections, a.palette.KeySection())
+	return sections
+}
+
+// ApplyKeys remaps key bindings from the tui.keys config, a map of view names
+// to action names to keys, and checks that no keys conflict within a view
+func (a *App) ApplyKeys(overrides map[string]map[string][]string) error {
+	sections := a.keySections()
+	if err := keys.Apply(sections, overrides); err != nil {
+		return err
+	}
+	return keys.Validate(sections)
+}
+
+// viewKeySections returns the key bindings of the current view
+func (a *App) viewKeySections() []keys.Section {
+	switch a.currentView {
+	case WindowsView:
+		return a.windows.KeySections()
+	case MessagesView:
+		return a.messages.KeySections()
+	case SchedulerView:
+		return a.schedulerView.KeySections()
+	case EventsView:
+		return a.eventsView.KeySections()
+	}
+	return nil
+}
+
+// renderHelp renders the active key bindings of the current view next to the
+// global ones, with the names used to remap them in the tui.keys config
+func (a *App) renderHelp() string {
+	t := theme.Current()
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+	keyStyle := lipgloss.NewStyle().Foreground(t.Text).Width(12)
+	nameStyle := lipgloss.NewStyle().Foreground(t.Muted)
+
+	renderSection := func(section keys.Section) string {
+		descWidth := 0
+		for _, action := range section.Actions {
+			descWidth = max(descWidth, lipgloss.Width(action.Binding.Help().Desc)+2)
+		}
+		descStyle := lipgloss.NewStyle().Width(descWidth)
+
+		lines := []string{titleStyle.Render(section.Title) + nameStyle.Render("  tui.keys."+section.Name)}
+		for _, action := range section.Actions {
+			help := action.Binding.Help()
+			lines = append(lines, keyStyle.Render(help.Key)+descStyle.Render(help.Desc)+nameStyle.Render(action.Name))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	global := renderSection(keys.Section{Name: keys.Global, Title: "Global", Actions: a.keyMap.Actions()})
+	var columns []string
+	for _, section := range a.viewKeySections() {
+		columns = append(columns, renderSection(section))
+	}
+	view := strings.Join(columns, "\n\n")
+	if view == "" {
+		view = nameStyle.Render("No keys for this view")
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, global, "    ", view)
+	hint := nameStyle.Render(fmt.Sprintf("%s or esc: close • remap keys in the tui.keys config section",
+		a.keyMap.Help.Help().Key))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Accent).
+		Padding(0, 1).
+		Render(body + "\n\n" + hint)
+}
@@ -0,0 +1,163 @@
+// Package keys applies the key bindings configured in the tui.keys section to
+// the key maps of the TUI views and checks them for conflicts. Each view lists
+// its bindings as named actions; the config maps view and action names to the
+// keys that trigger them.
+package keys
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Action is a named key binding of a view
+type Action struct {
+	Name    string
+	Binding *key.Binding
+	Mode    string // actions in different modes, like a form and a table, may share keys
+}
+
+// Section is the set of actions of a view or component, named as in the config
+type Section struct {
+	Name    string
+	Title   string // shown in the help view
+	Actions []Action
+	Modal   bool // takes all keys while open, so the global keys don't apply
+}
+
+// Global is the name of the section with the bindings available in every view
+const Global = "global"
+
+// Apply replaces the keys of the actions named in overrides, a map of section
+// names to action names to keys. The help of an overridden action shows its
+// new keys. Unknown sections and actions and empty key lists are errors.
+func Apply(sections []Section, overrides map[string]map[string][]string) error {
+	var problems []string
+	for _, name := range sortedKeys(overrides) {
+		section := find(sections, name)
+		if section == nil {
+			problems = append(problems, fmt.Sprintf("unknown view %q", name))
+			continue
+		}
+
+		for _, actionName := range sortedKeys(overrides[name]) {
+			action := section.find(actionName)
+			if action == nil {
+				problems = append(problems, fmt.Sprintf("%s: unknown action %q", name, actionName))
+				continue
+			}
+
+			keys := overrides[name][actionName]
+			if len(keys) == 0 || slices.Contains(keys, "") {
+				problems = append(problems, fmt.Sprintf("%s.%s: needs at least one key and no empty keys", name, actionName))
+				continue
+			}
+			bound := make([]string, len(keys))
+			help := make([]string, len(keys))
+			for i, k := range keys {
+				if k == "space" {
+					k = " "
+				}
+				bound[i] = k
+				help[i] = displayKey(k)
+			}
+			action.Binding.SetKeys(bound...)
+			action.Binding.SetHelp(strings.Join(help, "/"), action.Binding.Help().Desc)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid key bindings: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Validate reports keys bound to more than one action in the same mode of a
+// section. The global keys are handled before the keys of a view, so they
+// conflict with the main mode of every section that isn't modal.
+func Validate(sections []Section) error {
+	global := find(sections, Global)
+
+	var problems []string
+	for _, section := range sections {
+		owners := make(map[string]string) // mode and key to the action using it
+		claim := func(owner, mode, k string) {
+			id := mode + "\x00" + k
+			if other, ok := owners[id]; ok && other != owner {
+				problems = append(problems, fmt.Sprintf("%s: %q is bound to both %s and %s", section.Name, displayKey(k), other, owner))
+				return
+			}
+			owners[id] = owner
+		}
+
+		if global != nil && section.Name != Global && !section.Modal {
+			for _, action := range global.Actions {
+				for _, k := range action.Binding.Keys() {
+					claim(Global+"."+action.Name, "", k)
+				}
+			}
+		}
+		for _, action := range section.Actions {
+			for _, k := range action.Binding.Keys() {
+				claim(action.Name, action.Mode, k)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("conflicting key bindings: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Hint renders bindings as a line of "key: description" pairs for the bottom
+// of a view
+func Hint(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		if help := binding.Help(); binding.Enabled() && help.Key != "" {
+			parts = append(parts, help.Key+": "+help.Desc)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// find returns the named section, or nil if there is none
+func find(sections []Section, name string) *Section {
+	for i := range sections {
+		if sections[i].Name == name {
+			return &sections[i]
+		}
+	}
+	return nil
+}
+
+// find returns the named action, or nil if there is none
+func (s *Section) find(name string) *Action {
+	for i := range s.Actions {
+		if s.Actions[i].Name == name {
+			return &s.Actions[i]
+		}
+	}
+	return nil
+}
+
+// displayKey names the space key, which the config may also call "space"
+func displayKey(k string) string {
+	if k == " " {
+		return "space"
+	}
+	return k
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 )
 
 // TUIModel represents the base interface for all TUI models
@@ -80,7 +81,7 @@ type TmuxWindowInfo struct {
 
 // SchedulerStats represents scheduler statistics for display
 type SchedulerStats struct {
-	SmartSchedulerEnabled bool          `json:"smart_scheduler_enabled"`
+	Policy                string        `json:"policy"`
 	CronSchedulerEnabled  bool          `json:"cron_scheduler_enabled"`
 	PendingMessages       int           `json:"pending_messages"`
 	ProcessingMessages    int           `json:"processing_messages"`
@@ -216,7 +217,7 @@ type HelpMsg struct {
 
 // ThemeMsg represents theme change messages
 type ThemeMsg struct {
-	Theme string `json:"theme"` // "default", "dark", "light", "high_contrast"
+	Theme string `json:"theme"` // a built-in theme or a custom theme from ~/.tcs/themes
 }
 
 // ExportMsg represents data export messages
@@ -282,8 +283,8 @@ const (
 	ExportFormatYAML = "yaml"
 
 	// Theme types
-	ThemeDefault      = "default"
-	ThemeDark         = "dark"
-	ThemeLight        = "light"
-	ThemeHighContrast = "high_contrast"
+	ThemeDefault      = theme.Default
+	ThemeDark         = theme.Dark
+	ThemeLight        = theme.Light
+	ThemeHighContrast = theme.HighContrast
 )
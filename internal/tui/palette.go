@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// paletteMessageLimit is the number of most recent messages searched by the palette
+const paletteMessageLimit = 500
+
+// paletteItemsMsg carries the items loaded for the command palette
+type paletteItemsMsg []components.PaletteItem
+
+// paletteActions are the actions the command palette can run, listed first
+var paletteActions = []components.PaletteItem{
+	{Kind: components.PaletteAction, Title: "Go to Dashboard", Detail: "1", Value: "dashboard"},
+	{Kind: components.PaletteAction, Title: "Go to Windows", Detail: "2", Value: "windows"},
+	{Kind: components.PaletteAction, Title: "Go to Messages", Detail: "3", Value: "messages"},
+	{Kind: components.PaletteAction, Title: "Go to Scheduler", Detail: "4", Value: "scheduler"},
+	{Kind: components.PaletteAction, Title: "Go to Events", Detail: "5", Value: "events"},
+	{Kind: components.PaletteAction, Title: "New message", Detail: "open the composer", Value: "new-message"},
+	{Kind: components.PaletteAction, Title: "Pause sending", Detail: "hold all scheduled messages", Value: "pause"},
+	{Kind: components.PaletteAction, Title: "Resume sending", Detail: "lift a pause", Value: "resume"},
+	{Kind: components.PaletteAction, Title: "Rescan windows", Detail: "force a full tmux scan", Value: "rescan"},
+	{Kind: components.PaletteAction, Title: "Refresh", Detail: "r", Value: "refresh"},
+	{Kind: components.PaletteAction, Title: "Quit", Detail: "q", Value: "quit"},
+}
+
+// themeActions returns an action for each registered theme
+func themeActions() []components.PaletteItem {
+	var items []components.PaletteItem
+	for _, name := range theme.Names() {
+		items = append(items, components.PaletteItem{
+			Kind:   components.PaletteAction,
+			Title:  "Theme: " + name,
+			Detail: "T cycles themes",
+			Value:  "theme:" + name,
+		})
+	}
+	return items
+}
+
+// openPalette shows the command palette and loads its items
+func (a *App) openPalette() tea.Cmd {
+	a.showPalette = true
+	return tea.Batch(a.palette.Open(), a.loadPaletteItems())
+}
+
+// loadPaletteItems loads the actions, active windows and recent messages for the palette
+func (a *App) loadPaletteItems() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		items := append([]components.PaletteItem(nil), paletteActions...)
+		items = append(items, themeActions()...)
+		if a.db == nil {
+			return paletteItemsMsg(items)
+		}
+
+		windows, err := database.GetAllActiveTmuxWindows(a.db)
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to load palette", Message: err.Error()}
+		}
+		for _, window := range windows {
+			detail := window.WindowName
+			if window.HasClaude {
+				detail += " • Claude"
+			}
+			items = append(items, components.PaletteItem{
+				Kind:   components.PaletteWindow,
+				Title:  window.Target,
+				Detail: detail,
+				Value:  window.Target,
+			})
+		}
+
+		var messages []database.Message
+		err = a.db.Preload("Window").
+			Order("scheduled_time desc").
+			Limit(paletteMessageLimit).
+			Find(&messages).Error
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to load palette", Message: err.Error()}
+		}
+		for _, message := range messages {
+			failed := message.Status == database.MessageStatusFailed
+			// The messages table only lists messages with a window
+			if message.Window.Target == "" && !failed {
+				continue
+			}
+
+			target := message.Window.Target
+			if message.Route != "" {
+				target = message.Route
+			}
+
+			// Long messages are matched on their beginning only
+			content := strings.Join(strings.Fields(message.Content), " ")
+			if runes := []rune(content); len(runes) > 200 {
+				content = string(runes[:200])
+			}
+
+			items = append(items, components.PaletteItem{
+				Kind:   components.PaletteMessage,
+				Title:  content,
+				Detail: fmt.Sprintf("#%d %s %s", message.ID, target, message.Status),
+				ID:     message.ID,
+				Failed: failed,
+			})
+		}
+
+		return paletteItemsMsg(items)
+	})
+}
+
+// runPaletteItem jumps to the window or message picked in the palette, or runs the action
+func (a *App) runPaletteItem(item components.PaletteItem) tea.Cmd {
+	switch item.Kind {
+	case components.PaletteWindow:
+		return tea.Batch(a.switchView(WindowsView), a.windows.SelectWindow(item.Value))
+
+	case components.PaletteMessage:
+		return tea.Batch(a.switchView(MessagesView), a.messages.SelectMessage(item.ID, item.Failed))
+	}
+
+	if name, ok := strings.CutPrefix(item.Value, "theme:"); ok {
+		return func() tea.Msg {
+			return ThemeMsg{Theme: name}
+		}
+	}
+
+	switch item.Value {
+	case "dashboard":
+		return a.switchView(DashboardView)
+	case "windows":
+		return a.switchView(WindowsView)
+	case "messages":
+		return a.switchView(MessagesView)
+	case "scheduler":
+		return a.switchView(SchedulerView)
+	case "events":
+		return a.switchView(EventsView)
+	case "new-message":
+		return tea.Batch(a.switchView(SchedulerView), a.schedulerView.OpenComposer())
+	case "pause":
+		return tea.Batch(a.switchView(SchedulerView), a.schedulerView.PauseSending())
+	case "resume":
+		return tea.Batch(a.switchView(SchedulerView), a.schedulerView.ResumeSending())
+	case "rescan":
+		return tea.Batch(a.switchView(WindowsView), a.windows.ForceRescan())
+	case "refresh":
+		return func() tea.Msg {
+			return RefreshMsg{}
+		}
+	case "quit":
+		a.cleanup()
+		return tea.Quit
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// Package theme holds the color themes shared by every TUI view and component.
+// Built-in themes are always available; custom themes are loaded from YAML
+// files and may start from a built-in theme and override some of its colors.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/viper"
+)
+
+// Names of the built-in themes
+const (
+	Default      = "default"
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high_contrast"
+)
+
+// Theme is a named set of colors. Colors are ANSI numbers ("12"), 256-color
+// numbers ("214") or hex values ("#7aa2f7").
+type Theme struct {
+	Name string `mapstructure:"name"`
+	Base string `mapstructure:"base"` // theme that custom themes start from, default if empty
+
+	Text         lipgloss.Color `mapstructure:"text"`          // titles and values
+	Muted        lipgloss.Color `mapstructure:"muted"`         // help, hints and borders
+	Accent       lipgloss.Color `mapstructure:"accent"`        // section titles, targets and focused fields
+	Selected     lipgloss.Color `mapstructure:"selected"`      // background of selected rows and the header
+	SelectedText lipgloss.Color `mapstructure:"selected_text"` // text on the selected background
+	Background   lipgloss.Color `mapstructure:"background"`    // footer and empty bar background
+	Success      lipgloss.Color `mapstructure:"success"`
+	Warning      lipgloss.Color `mapstructure:"warning"`
+	Danger       lipgloss.Color `mapstructure:"danger"`
+	Info         lipgloss.Color `mapstructure:"info"`
+
+	ASCII bool `mapstructure:"ascii"` // draw charts with ASCII instead of block characters
+}
+
+// builtins are the themes that are always registered, in cycling order
+var builtins = []Theme{
+	{
+		Name: Default, Text: "15", Muted: "8", Accent: "12", Selected: "57", SelectedText: "15",
+		Background: "0", Success: "10", Warning: "11", Danger: "9", Info: "14",
+	},
+	{
+		Name: Dark, Text: "255", Muted: "245", Accent: "75", Selected: "240", SelectedText: "255",
+		Background: "235", Success: "34", Warning: "214", Danger: "196", Info: "44",
+	},
+	{
+		Name: Light, Text: "232", Muted: "240", Accent: "25", Selected: "252", SelectedText: "232",
+		Background: "254", Success: "28", Warning: "130", Danger: "124", Info: "30",
+	},
+	{
+		Name: HighContrast, Text: "15", Muted: "7", Accent: "14", Selected: "15", SelectedText: "0",
+		Background: "0", Success: "10", Warning: "11", Danger: "9", Info: "14", ASCII: true,
+	},
+}
+
+var (
+	mu      sync.RWMutex
+	themes  = make(map[string]Theme)
+	order   []string // registration order, used for cycling
+	current = Default
+)
+
+func init() {
+	for _, t := range builtins {
+		Register(t)
+	}
+}
+
+// Register adds a theme, replacing a registered theme with the same name
+func Register(t Theme) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := themes[t.Name]; !ok {
+		order = append(order, t.Name)
+	}
+	themes[t.Name] = t
+}
+
+// Get returns the named theme, or the default theme and false if there is none
+func Get(name string) (Theme, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if t, ok := themes[name]; ok {
+		return t, true
+	}
+	return themes[Default], false
+}
+
+// Names returns the registered theme names in cycling order
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return append([]string(nil), order...)
+}
+
+// Current returns the theme in use
+func Current() Theme {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return themes[current]
+}
+
+// Set makes the named theme the one in use
+func Set(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := themes[name]; !ok {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(order, ", "))
+	}
+	current = name
+	return nil
+}
+
+// Next makes the theme after the current one the one in use and returns it
+func Next() Theme {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, name := range order {
+		if name == current {
+			current = order[(i+1)%len(order)]
+			break
+		}
+	}
+	return themes[current]
+}
+
+// Dir returns the directory custom themes are loaded from
+func Dir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".tcs", "themes")
+}
+
+// LoadDir registers the themes defined in the *.yaml and *.yml files of dir
+// and returns their names. A missing directory is not an error; files that
+// fail to load are skipped and reported together.
+func LoadDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read themes directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	var names, failures []string
+	for _, file := range files {
+		t, err := LoadFile(file)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		Register(t)
+		names = append(names, t.Name)
+	}
+
+	if len(failures) > 0 {
+		return names, fmt.Errorf("failed to load %d themes: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return names, nil
+}
+
+// LoadFile reads a custom theme. The name defaults to the file name, and
+// colors that aren't set are taken from the base theme.
+func LoadFile(path string) (Theme, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	var custom Theme
+	if err := v.Unmarshal(&custom); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if custom.Name == "" {
+		custom.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	baseName := custom.Base
+	if baseName == "" {
+		baseName = Default
+	}
+	base, ok := Get(baseName)
+	if !ok {
+		return Theme{}, fmt.Errorf("%s: unknown base theme %q", filepath.Base(path), baseName)
+	}
+
+	t := merge(base, custom)
+	if !v.IsSet("ascii") {
+		t.ASCII = base.ASCII
+	}
+	return t, nil
+}
+
+// merge returns base with the colors set in custom replaced
+func merge(base, custom Theme) Theme {
+	merged := base
+	merged.Name = custom.Name
+	merged.Base = custom.Base
+	merged.ASCII = custom.ASCII
+
+	mergedValue := reflect.ValueOf(&merged).Elem()
+	customValue := reflect.ValueOf(custom)
+	for i := 0; i < customValue.NumField(); i++ {
+		field := customValue.Field(i)
+		if field.Type() == reflect.TypeOf(lipgloss.Color("")) && field.String() != "" {
+			mergedValue.Field(i).Set(field)
+		}
+	}
+	return merged
+}
+
+// TableStyles returns table styles in the theme colors
+func (t Theme) TableStyles() table.Styles {
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(t.Muted).
+		BorderBottom(true).
+		Foreground(t.Accent).
+		Bold(true)
+	styles.Selected = styles.Selected.
+		Foreground(t.SelectedText).
+		Background(t.Selected).
+		Bold(true)
+	// Cells keep the terminal's text color; a cell color would override the selected row's
+	return styles
+}
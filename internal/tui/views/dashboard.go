@@ -10,11 +10,15 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/claude"
 	"github.com/derekxwang/tcs/internal/config"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/discovery"
 	"github.com/derekxwang/tcs/internal/monitor"
+	"github.com/derekxwang/tcs/internal/scheduler"
 	"github.com/derekxwang/tcs/internal/tmux"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/types"
 )
 
@@ -24,6 +28,7 @@ type Dashboard struct {
 	usageMonitor    *monitor.UsageMonitor
 	windowDiscovery *discovery.WindowDiscovery
 	tmuxClient      *tmux.Client
+	quietHours      []scheduler.QuietPeriod
 
 	// UI components
 	usageProgress progress.Model
@@ -32,9 +37,14 @@ type Dashboard struct {
 	width      int
 	height     int
 	state      *types.ApplicationState
+	history    *claude.UsageHistory
 	lastUpdate time.Time
 
+	// Draw charts with ASCII characters (high contrast theme)
+	asciiCharts bool
+
 	// Styles
+	colors       theme.Theme // for colors that depend on the data
 	titleStyle   lipgloss.Style
 	sectionStyle lipgloss.Style
 	valueStyle   lipgloss.Style
@@ -55,42 +65,57 @@ func NewDashboard(db *gorm.DB, usageMonitor *monitor.UsageMonitor,
 		usageMonitor:    usageMonitor,
 		windowDiscovery: windowDiscovery,
 		tmuxClient:      tmuxClient,
+		quietHours:      scheduler.DefaultConfig().QuietHours,
 		usageProgress:   usageProgress,
 		state:           &types.ApplicationState{},
 		lastUpdate:      time.Now(),
 	}
 
-	d.initStyles()
+	d.initStyles(theme.Current())
 	return d
 }
 
+// SetTheme restyles the dashboard with the named theme; themes like high
+// contrast draw charts with ASCII characters
+func (d *Dashboard) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	d.initStyles(t)
+}
+
 // initStyles initializes the dashboard styles
-func (d *Dashboard) initStyles() {
+func (d *Dashboard) initStyles(t theme.Theme) {
+	d.colors = t
+	d.asciiCharts = t.ASCII
+
+	width := d.usageProgress.Width
+	d.usageProgress = progress.New(progress.WithSolidFill(string(t.Accent)))
+	d.usageProgress.Width = width
+
 	d.titleStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
+		Foreground(t.Text).
 		MarginBottom(1)
 
 	d.sectionStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("12")).
+		Foreground(t.Accent).
 		MarginTop(1).
 		MarginBottom(1)
 
 	d.valueStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10"))
+		Foreground(t.Success)
 
 	d.errorStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("9"))
+		Foreground(t.Danger)
 
 	d.successStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("10"))
+		Foreground(t.Success)
 
 	d.cardStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
+		BorderForeground(t.Muted).
 		Padding(0, 1). // Reduced vertical padding
 		MarginRight(2).
 		MarginBottom(1)
@@ -152,6 +177,11 @@ func (d *Dashboard) View() string {
 	// Usage overview section
 	sections = append(sections, d.renderUsageOverview())
 
+	// Usage history charts, once Claude data has been read
+	if d.history != nil {
+		sections = append(sections, d.renderUsageHistory())
+	}
+
 	// System status section (now includes quick stats)
 	sections = append(sections, d.renderSystemStatus())
 
@@ -181,6 +211,7 @@ func (d *Dashboard) refreshData() tea.Cmd {
 		windowStats := d.collectWindowStats()
 		systemStats := d.collectSystemStats()
 		schedulerStats := d.collectSchedulerStats()
+		usageHistory := d.collectUsageHistory()
 
 		// Create dashboard data to pass to main thread (using individual components)
 		dashboardData := map[string]interface{}{
@@ -188,6 +219,7 @@ func (d *Dashboard) refreshData() tea.Cmd {
 			"windows":   windowStats,
 			"system":    systemStats,
 			"scheduler": schedulerStats,
+			"history":   usageHistory,
 		}
 
 		return types.RefreshDataMsg{
@@ -236,6 +268,19 @@ func (d *Dashboard) collectUsageStats() types.UsageStats {
 	}
 }
 
+// collectUsageHistory collects the Claude token history for the charts (thread-safe data collection)
+func (d *Dashboard) collectUsageHistory() *claude.UsageHistory {
+	if d.usageMonitor == nil {
+		return nil
+	}
+
+	history, err := d.usageMonitor.GetUsageHistory(7)
+	if err != nil {
+		return nil
+	}
+	return history
+}
+
 // collectWindowStats collects window statistics (thread-safe data collection)
 func (d *Dashboard) collectWindowStats() map[string]interface{} {
 	// Get window statistics
@@ -369,13 +414,21 @@ func (d *Dashboard) collectSchedulerStats() map[string]interface{} {
 		d.db.Model(&database.Message{}).Where("status = ?", "failed").Count(&failed)
 	}
 
+	hold := ""
+	if d.db != nil {
+		if current, err := scheduler.CurrentHold(d.db, d.quietHours, time.Now()); err == nil && current != nil {
+			hold = current.String()
+		}
+	}
+
 	return map[string]interface{}{
 		"schedulerStats": types.SchedulerStats{
-			SmartSchedulerEnabled: cfg.Scheduler.SmartEnabled,
-			CronSchedulerEnabled:  cfg.Scheduler.CronEnabled,
-			PendingMessages:       int(pending),
-			SentMessages:          int(sent),
-			FailedMessages:        int(failed),
+			Policy:               cfg.Scheduler.Policy,
+			CronSchedulerEnabled: cfg.Scheduler.CronEnabled,
+			Hold:                 hold,
+			PendingMessages:      int(pending),
+			SentMessages:         int(sent),
+			FailedMessages:       int(failed),
 		},
 		"databaseStats": types.DatabaseStats{
 			PendingMessages: int(pending),
@@ -424,6 +477,11 @@ func (d *Dashboard) updateStateWithData(dashboardData map[string]interface{}) {
 		}
 	}
 
+	// Update usage history; keep the last charts when Claude data can't be read
+	if history, ok := dashboardData["history"].(*claude.UsageHistory); ok && history != nil {
+		d.history = history
+	}
+
 	// Update last refresh time
 	d.lastUpdate = time.Now()
 }
@@ -458,13 +516,13 @@ func (d *Dashboard) renderUsageOverview() string {
 	}
 
 	// Helper function for color coding and icons
-	getUsageColor := func(percentage float64) (string, string) {
+	getUsageColor := func(percentage float64) (string, lipgloss.Color) {
 		if percentage >= 0.75 {
-			return "🔴", "9" // Red
+			return "🔴", d.colors.Danger
 		} else if percentage >= 0.5 {
-			return "🟡", "11" // Yellow
+			return "🟡", d.colors.Warning
 		}
-		return "🟢", "10" // Green
+		return "🟢", d.colors.Success
 	}
 
 	// Helper function for clamping float64 values to 1.0
@@ -480,14 +538,14 @@ func (d *Dashboard) renderUsageOverview() string {
 
 	// Cost usage line (most important)
 	costIcon, costColor := getUsageColor(costPercentage)
-	costStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(costColor))
+	costStyle := lipgloss.NewStyle().Foreground(costColor)
 	costBar := d.usageProgress.ViewAs(clampToOne(costPercentage))
 	costLine := fmt.Sprintf("💰 Cost:      %s [%s] %.1f%%  $%.2f/$%.2f",
 		costIcon, costBar, costPercentage*100, usage.CostUsed, usage.CostLimit)
 
 	// Token usage line
 	tokenIcon, tokenColor := getUsageColor(tokenPercentage)
-	tokenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(tokenColor))
+	tokenStyle := lipgloss.NewStyle().Foreground(tokenColor)
 	tokenBar := d.usageProgress.ViewAs(clampToOne(tokenPercentage))
 	tokenLine := fmt.Sprintf("📊 Tokens:    %s [%s] %.1f%%  %s/%s",
 		tokenIcon, tokenBar, tokenPercentage*100,
@@ -495,7 +553,7 @@ func (d *Dashboard) renderUsageOverview() string {
 
 	// Message usage line
 	messageIcon, messageColor := getUsageColor(messagePercentage)
-	messageStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(messageColor))
+	messageStyle := lipgloss.NewStyle().Foreground(messageColor)
 	messageBar := d.usageProgress.ViewAs(clampToOne(messagePercentage))
 	messageLine := fmt.Sprintf("📨 Messages:  %s [%s] %.1f%%  %d/%d",
 		messageIcon, messageBar, messagePercentage*100, usage.MessagesUsed, usage.MessagesLimit)
@@ -503,7 +561,7 @@ func (d *Dashboard) renderUsageOverview() string {
 	// Time remaining - more compact format
 	timeUsed := 1.0 - (float64(usage.TimeRemaining.Seconds()) / (5 * time.Hour).Seconds())
 	timeIcon, timeColor := getUsageColor(timeUsed)
-	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(timeColor))
+	timeStyle := lipgloss.NewStyle().Foreground(timeColor)
 	timeBar := d.usageProgress.ViewAs(clampToOne(timeUsed))
 	timeLine := fmt.Sprintf("⏱️  Reset in:  %s [%s] %s",
 		timeIcon, timeBar, usage.TimeRemaining.Round(time.Minute).String())
@@ -536,6 +594,69 @@ func (d *Dashboard) renderUsageOverview() string {
 	return d.cardStyle.Width(d.width - 4).Render(content)
 }
 
+// renderUsageHistory renders tokens per hour of the current session next to
+// the session totals of the last 7 days, with the burn rate below
+func (d *Dashboard) renderUsageHistory() string {
+	history := d.history
+	usage := d.state.Usage
+	innerWidth := d.width - 10 // card border, padding and margin
+	columnWidth := (innerWidth - 2) / 2
+
+	title := "📉 Usage History"
+	if d.asciiCharts {
+		title = "Usage History"
+	}
+
+	// Left column: tokens per hour of the current session
+	bars := make([]components.ChartBar, 0, len(history.HourlyTokens))
+	for hour, tokens := range history.HourlyTokens {
+		bars = append(bars, components.ChartBar{
+			Label: history.WindowStart.Add(time.Duration(hour) * time.Hour).Local().Format("15:04"),
+			Value: tokens,
+		})
+	}
+	leftColumn := "Tokens per hour\n" + components.BarChart(bars, columnWidth, d.asciiCharts)
+
+	// Right column: totals of the sessions in the last 7 days
+	totals := make([]int, 0, len(history.Sessions))
+	peak, sum := 0, 0
+	for _, session := range history.Sessions {
+		totals = append(totals, session.TotalTokens)
+		peak = max(peak, session.TotalTokens)
+		sum += session.TotalTokens
+	}
+	rightColumn := "Sessions, last 7 days\nNo sessions"
+	if len(totals) > 0 {
+		separator := " • "
+		if d.asciiCharts {
+			separator = " | "
+		}
+		rightColumn = fmt.Sprintf("Sessions, last 7 days\n%s\n%d sessions%speak %s%savg %s",
+			components.Sparkline(totals, columnWidth, d.asciiCharts),
+			len(totals), separator, components.ShortNumber(peak), separator, components.ShortNumber(sum/len(totals)))
+	}
+
+	columnStyle := lipgloss.NewStyle().Width(columnWidth).MarginRight(2)
+	content := lipgloss.JoinHorizontal(lipgloss.Top,
+		columnStyle.Render(leftColumn),
+		lipgloss.NewStyle().Width(columnWidth).Render(rightColumn),
+	)
+
+	// Burn rate against the P90 token limit
+	if !usage.WindowStartTime.IsZero() {
+		burnRate := components.BurnRate{
+			Used:      usage.TokensUsed,
+			Limit:     usage.TokensLimit,
+			Elapsed:   time.Since(usage.WindowStartTime),
+			Remaining: usage.TimeRemaining,
+		}
+		content += "\n" + components.BurnRateLine(burnRate, innerWidth, d.colors)
+	}
+
+	return d.cardStyle.Width(d.width - 4).Render(
+		lipgloss.NewStyle().Bold(true).Render(title) + "\n" + content)
+}
+
 // formatNumber formats large numbers with commas for readability
 func (d *Dashboard) formatNumber(n int) string {
 	if n >= 1000 {
@@ -580,23 +701,29 @@ func (d *Dashboard) renderSystemStatus() string {
 
 	// Left column: System Status
 	tmuxStatus := "✗"
-	tmuxColor := lipgloss.Color("9")
+	tmuxColor := d.colors.Danger
 	if system.TmuxRunning {
 		tmuxStatus = fmt.Sprintf("✓ (%d)", len(system.TmuxSessions))
-		tmuxColor = lipgloss.Color("10")
+		tmuxColor = d.colors.Success
 	}
 
 	dbStatus := "✗"
-	dbColor := lipgloss.Color("9")
+	dbColor := d.colors.Danger
 	if system.DatabaseConnected {
 		dbStatus = "✓"
-		dbColor = lipgloss.Color("10")
+		dbColor = d.colors.Success
+	}
+
+	sending := lipgloss.NewStyle().Foreground(d.colors.Success).Render("✓")
+	if hold := d.state.Scheduler.Hold; hold != "" {
+		sending = lipgloss.NewStyle().Foreground(d.colors.Warning).Render("⏸ " + hold)
 	}
 
 	leftColumn := fmt.Sprintf(
-		"🔧 System Status\nTmux: %s  DB: %s\nRefresh: %s",
+		"🔧 System Status\nTmux: %s  DB: %s\nSending: %s\nRefresh: %s",
 		lipgloss.NewStyle().Foreground(tmuxColor).Render(tmuxStatus),
 		lipgloss.NewStyle().Foreground(dbColor).Render(dbStatus),
+		sending,
 		d.valueStyle.Render(d.lastUpdate.Format("15:04:05")),
 	)
 
@@ -0,0 +1,447 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// maxEvents is the number of events kept in the events view
+const maxEvents = 1000
+
+// eventKinds are the kind filters cycled through in the events view; empty shows all
+var eventKinds = []string{"", database.EventKindMessage, database.EventKindWindow, database.EventKindUsage, database.EventKindError}
+
+// newEvents carries events loaded after the last one shown
+type newEvents struct {
+	events []database.Event
+}
+
+// Events represents the event log view
+type Events struct {
+	db *gorm.DB
+
+	// UI components
+	filterInput textinput.Model
+
+	// State
+	width     int
+	height    int
+	events    []database.Event // oldest first
+	lastID    uint             // ID of the newest loaded event
+	kind      int              // index into eventKinds
+	filtering bool             // the filter input has focus
+	offset    int              // lines scrolled back from the newest event; 0 follows new events
+
+	// Key bindings
+	keyMap EventsKeyMap
+
+	// Styles
+	titleStyle  lipgloss.Style
+	timeStyle   lipgloss.Style
+	targetStyle lipgloss.Style
+	helpStyle   lipgloss.Style
+	kindStyles  map[string]lipgloss.Style
+}
+
+// EventsKeyMap defines key bindings for the events view
+type EventsKeyMap struct {
+	Up          key.Binding
+	Down        key.Binding
+	PageUp      key.Binding
+	PageDown    key.Binding
+	Oldest      key.Binding
+	Newest      key.Binding
+	Filter      key.Binding
+	ClearFilter key.Binding
+	CycleKind   key.Binding
+}
+
+// DefaultEventsKeyMap returns the default events key bindings
+func DefaultEventsKeyMap() EventsKeyMap {
+	return EventsKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "scroll up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "scroll down"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup", "ctrl+u"),
+			key.WithHelp("pgup", "page up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown", "ctrl+d"),
+			key.WithHelp("pgdown", "page down"),
+		),
+		Oldest: key.NewBinding(
+			key.WithKeys("home", "g"),
+			key.WithHelp("g", "oldest"),
+		),
+		Newest: key.NewBinding(
+			key.WithKeys("end", "G"),
+			key.WithHelp("G", "follow newest"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		ClearFilter: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear filter"),
+		),
+		CycleKind: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "cycle kind"),
+		),
+	}
+}
+
+// Actions returns the bindings by their names in the tui.keys config
+func (k *EventsKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "up", Binding: &k.Up},
+		{Name: "down", Binding: &k.Down},
+		{Name: "page_up", Binding: &k.PageUp},
+		{Name: "page_down", Binding: &k.PageDown},
+		{Name: "oldest", Binding: &k.Oldest},
+		{Name: "newest", Binding: &k.Newest},
+		{Name: "filter", Binding: &k.Filter},
+		{Name: "clear_filter", Binding: &k.ClearFilter},
+		{Name: "cycle_kind", Binding: &k.CycleKind},
+	}
+}
+
+// NewEvents creates a new events view
+func NewEvents(db *gorm.DB) *Events {
+	filterInput := textinput.New()
+	filterInput.Placeholder = "type, target or text"
+	filterInput.Prompt = "/"
+	filterInput.CharLimit = 100
+
+	e := &Events{
+		db:          db,
+		filterInput: filterInput,
+		keyMap:      DefaultEventsKeyMap(),
+	}
+
+	e.initStyles(theme.Current())
+	return e
+}
+
+// KeySections returns the view's key bindings for remapping and the help view
+func (e *Events) KeySections() []keys.Section {
+	return []keys.Section{{Name: "events", Title: "Events", Actions: e.keyMap.Actions()}}
+}
+
+// SetTheme restyles the view with the named theme
+func (e *Events) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	e.initStyles(t)
+}
+
+// initStyles initializes the events view styles
+func (e *Events) initStyles(t theme.Theme) {
+	e.titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Text)
+
+	e.timeStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	e.targetStyle = lipgloss.NewStyle().
+		Foreground(t.Accent)
+
+	e.helpStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	e.kindStyles = map[string]lipgloss.Style{
+		database.EventKindMessage: lipgloss.NewStyle().Foreground(t.Success),
+		database.EventKindWindow:  lipgloss.NewStyle().Foreground(t.Info),
+		database.EventKindUsage:   lipgloss.NewStyle().Foreground(t.Warning),
+		database.EventKindError:   lipgloss.NewStyle().Foreground(t.Danger),
+	}
+}
+
+// Init initializes the events view
+func (e *Events) Init() tea.Cmd {
+	return e.refreshData()
+}
+
+// Update handles messages for the events view
+func (e *Events) Update(msg tea.Msg) (*Events, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.SetSize(msg.Width, msg.Height)
+
+	case tea.KeyMsg:
+		if e.filtering {
+			return e.handleFilterKeys(msg)
+		}
+		return e.handleKeys(msg)
+
+	case tea.MouseMsg:
+		// The wheel scrolls like the arrow keys, three lines at a time
+		if msg.Action == tea.MouseActionPress {
+			switch msg.Button {
+			case tea.MouseButtonWheelUp:
+				e.scroll(3)
+			case tea.MouseButtonWheelDown:
+				e.scroll(-3)
+			}
+		}
+
+	case types.RefreshDataMsg:
+		if msg.Type == "all" || msg.Type == "events" {
+			if loaded, ok := msg.Data.(newEvents); ok {
+				e.addEvents(loaded.events)
+			} else if msg.Data == nil {
+				return e, e.refreshData()
+			}
+		}
+	}
+
+	return e, nil
+}
+
+// handleKeys handles key presses while browsing events
+func (e *Events) handleKeys(msg tea.KeyMsg) (*Events, tea.Cmd) {
+	page := max(1, e.listHeight()-1)
+
+	switch {
+	case key.Matches(msg, e.keyMap.Filter):
+		e.filtering = true
+		return e, e.filterInput.Focus()
+
+	case key.Matches(msg, e.keyMap.ClearFilter):
+		e.filterInput.SetValue("")
+		e.offset = 0
+
+	case key.Matches(msg, e.keyMap.CycleKind):
+		e.kind = (e.kind + 1) % len(eventKinds)
+		e.offset = 0
+
+	case key.Matches(msg, e.keyMap.Up):
+		e.scroll(1)
+
+	case key.Matches(msg, e.keyMap.Down):
+		e.scroll(-1)
+
+	case key.Matches(msg, e.keyMap.PageUp):
+		e.scroll(page)
+
+	case key.Matches(msg, e.keyMap.PageDown):
+		e.scroll(-page)
+
+	case key.Matches(msg, e.keyMap.Oldest):
+		e.scroll(len(e.events))
+
+	case key.Matches(msg, e.keyMap.Newest):
+		e.offset = 0
+	}
+
+	return e, nil
+}
+
+// handleFilterKeys handles key presses while typing a filter
+func (e *Events) handleFilterKeys(msg tea.KeyMsg) (*Events, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		e.filtering = false
+		e.filterInput.Blur()
+		return e, nil
+	case tea.KeyEsc:
+		e.filtering = false
+		e.filterInput.Blur()
+		e.filterInput.SetValue("")
+		e.offset = 0
+		return e, nil
+	}
+
+	var cmd tea.Cmd
+	e.filterInput, cmd = e.filterInput.Update(msg)
+	e.offset = 0
+	return e, cmd
+}
+
+// scroll moves the view back (positive) or forward (negative) by lines
+func (e *Events) scroll(lines int) {
+	maxOffset := max(0, len(e.visibleEvents())-e.listHeight())
+	e.offset = max(0, min(e.offset+lines, maxOffset))
+}
+
+// addEvents appends newly loaded events, keeping the position when scrolled back
+func (e *Events) addEvents(loaded []database.Event) {
+	for _, event := range loaded {
+		// Overlapping refreshes can load the same events twice
+		if event.ID <= e.lastID {
+			continue
+		}
+		if e.offset > 0 && e.matches(event) {
+			e.offset++
+		}
+		e.events = append(e.events, event)
+		e.lastID = event.ID
+	}
+
+	if len(e.events) > maxEvents {
+		e.events = append([]database.Event(nil), e.events[len(e.events)-maxEvents:]...)
+		e.scroll(0)
+	}
+}
+
+// matches checks if an event passes the kind and text filters
+func (e *Events) matches(event database.Event) bool {
+	if kind := eventKinds[e.kind]; kind != "" && event.Kind != kind {
+		return false
+	}
+	filter := strings.ToLower(strings.TrimSpace(e.filterInput.Value()))
+	if filter == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(event.Type), filter) ||
+		strings.Contains(strings.ToLower(event.Target), filter) ||
+		strings.Contains(strings.ToLower(event.Summary), filter)
+}
+
+// visibleEvents returns the events that pass the filters, oldest first
+func (e *Events) visibleEvents() []database.Event {
+	var visible []database.Event
+	for _, event := range e.events {
+		if e.matches(event) {
+			visible = append(visible, event)
+		}
+	}
+	return visible
+}
+
+// listHeight returns the number of event lines that fit
+func (e *Events) listHeight() int {
+	return max(1, e.height-4) // title, filter line, help and spacing
+}
+
+// IsFormActive returns true while a filter is being typed
+func (e *Events) IsFormActive() bool {
+	return e.filtering
+}
+
+// View renders the events view
+func (e *Events) View() string {
+	if e.width == 0 {
+		return "Loading events..."
+	}
+
+	visible := e.visibleEvents()
+
+	// Title with the active filters and the scroll state
+	kind := eventKinds[e.kind]
+	if kind == "" {
+		kind = "all"
+	}
+	status := "following"
+	if e.offset > 0 {
+		status = fmt.Sprintf("%d newer", e.offset)
+	}
+	title := e.titleStyle.Render(fmt.Sprintf("📜 Events (%s) • %d shown • %s", kind, len(visible), status))
+
+	filterLine := e.filterInput.View()
+	if !e.filtering && e.filterInput.Value() == "" {
+		filterLine = e.helpStyle.Render(e.keyMap.Filter.Help().Key + " to filter")
+	}
+
+	// The window of events ending offset lines before the newest
+	end := len(visible) - e.offset
+	start := max(0, end-e.listHeight())
+	var lines []string
+	for _, event := range visible[start:end] {
+		lines = append(lines, e.renderEvent(event))
+	}
+	if len(visible) == 0 {
+		lines = append(lines, e.helpStyle.Render("No events"))
+	}
+
+	k := e.keyMap
+	help := e.helpStyle.Render(keys.Hint(k.Up, k.Down, k.PageUp, k.PageDown, k.Oldest, k.Newest,
+		k.CycleKind, k.Filter, k.ClearFilter))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		filterLine,
+		strings.Join(lines, "\n"),
+		help,
+	)
+}
+
+// renderEvent renders an event as one line that fits the view width
+func (e *Events) renderEvent(event database.Event) string {
+	kindStyle, ok := e.kindStyles[event.Kind]
+	if !ok {
+		kindStyle = lipgloss.NewStyle()
+	}
+
+	timestamp := event.CreatedAt.Local().Format("01-02 15:04:05") + " "
+	kind := fmt.Sprintf("%-7s %-15s ", event.Kind, event.Type)
+	target := fmt.Sprintf("%-12s ", truncateText(event.Target, 12))
+	width := lipgloss.Width(timestamp) + lipgloss.Width(kind) + lipgloss.Width(target)
+	summary := truncateText(strings.ReplaceAll(event.Summary, "\n", " "), max(10, e.width-width-1))
+
+	return e.timeStyle.Render(timestamp) +
+		kindStyle.Render(kind) +
+		e.targetStyle.Render(target) +
+		summary
+}
+
+// truncateText shortens text to at most width characters
+func truncateText(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// SetSize sets the events view size
+func (e *Events) SetSize(width, height int) {
+	e.width = width
+	e.height = height
+	e.filterInput.Width = max(10, width-4)
+	e.scroll(0)
+}
+
+// Refresh loads the events recorded since the last refresh
+func (e *Events) Refresh() tea.Cmd {
+	return e.refreshData()
+}
+
+// refreshData loads new events
+func (e *Events) refreshData() tea.Cmd {
+	afterID := e.lastID
+	return func() tea.Msg {
+		var loaded []database.Event
+		var err error
+		if afterID == 0 {
+			loaded, err = database.GetRecentEvents(e.db, "", maxEvents)
+		} else {
+			loaded, err = database.GetEventsAfter(e.db, afterID, "", maxEvents)
+		}
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to load events", Message: err.Error()}
+		}
+		return types.RefreshDataMsg{Type: "events", Data: newEvents{events: loaded}}
+	}
+}
@@ -2,7 +2,10 @@ package views
 
 import (
 	"fmt"
+	"log"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -12,20 +15,27 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/daemon"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/scheduler"
 	"github.com/derekxwang/tcs/internal/tmux"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/types"
+	"github.com/derekxwang/tcs/internal/utils"
 )
 
 // Messages represents the message management view with editing capabilities
 type Messages struct {
 	db         *gorm.DB
 	scheduler  *scheduler.Scheduler
+	remote     *daemon.Client // set when attached to a running daemon
 	tmuxClient *tmux.Client
 
 	// UI components
-	messagesTable table.Model
+	messagesTable *components.MessageTable
+	failedTable   table.Model // dead-lettered messages
 
 	// Form inputs for new/edit message
 	targetInput   textinput.Model
@@ -41,6 +51,14 @@ type Messages struct {
 	editingID     uint
 	messages      []database.Message
 	sessionGroups map[string][]database.Message
+	pinnedID      uint // message loaded even when it isn't among the latest
+	selectID      uint // message to select once it is loaded
+
+	// Failed messages tab
+	showFailed bool
+	failed     []database.Message
+	attempts   map[uint][]database.MessageAttempt // attempt history by message ID
+	marked     map[uint]bool                      // failed messages selected for bulk actions
 
 	// Key bindings
 	keyMap MessagesKeyMap
@@ -55,14 +73,16 @@ type Messages struct {
 
 // MessagesKeyMap defines key bindings for the messages view
 type MessagesKeyMap struct {
-	NewMessage      key.Binding
-	EditMessage     key.Binding
-	DeleteMessage   key.Binding
-	RefreshMessages key.Binding
-	ShowForm        key.Binding
-	CancelForm      key.Binding
-	SubmitForm      key.Binding
-	NextInput       key.Binding
+	NewMessage    key.Binding
+	EditMessage   key.Binding
+	DeleteMessage key.Binding
+	CancelForm    key.Binding
+	SubmitForm    key.Binding
+	NextInput     key.Binding
+	SwitchTab     key.Binding
+	ToggleMark    key.Binding
+	MarkAll       key.Binding
+	Requeue       key.Binding
 }
 
 // DefaultMessagesKeyMap returns the default messages key bindings
@@ -80,14 +100,6 @@ func DefaultMessagesKeyMap() MessagesKeyMap {
 			key.WithKeys("d", "delete"),
 			key.WithHelp("d", "delete message"),
 		),
-		RefreshMessages: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
-		),
-		ShowForm: key.NewBinding(
-			key.WithKeys("f"),
-			key.WithHelp("f", "show form"),
-		),
 		CancelForm: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "cancel"),
@@ -100,27 +112,70 @@ func DefaultMessagesKeyMap() MessagesKeyMap {
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "next input"),
 		),
+		SwitchTab: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "scheduled/failed"),
+		),
+		ToggleMark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "select"),
+		),
+		MarkAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "select all"),
+		),
+		Requeue: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "requeue"),
+		),
+	}
+}
+
+// Actions returns the bindings by their names in the tui.keys config. The form
+// keys are only used while the form is open, the marking and requeue keys only
+// in the failed tab.
+func (k *MessagesKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "new", Binding: &k.NewMessage},
+		{Name: "edit", Binding: &k.EditMessage},
+		{Name: "delete", Binding: &k.DeleteMessage},
+		{Name: "switch_tab", Binding: &k.SwitchTab},
+		{Name: "toggle_mark", Binding: &k.ToggleMark, Mode: "failed"},
+		{Name: "mark_all", Binding: &k.MarkAll, Mode: "failed"},
+		{Name: "requeue", Binding: &k.Requeue, Mode: "failed"},
+		{Name: "cancel_form", Binding: &k.CancelForm, Mode: "form"},
+		{Name: "submit_form", Binding: &k.SubmitForm, Mode: "form"},
+		{Name: "next_input", Binding: &k.NextInput, Mode: "form"},
 	}
 }
 
+// failedMessagesData carries the failed messages and their attempt history to the main thread
+type failedMessagesData struct {
+	messages []database.Message
+	attempts map[uint][]database.MessageAttempt
+}
+
 // NewMessages creates a new messages view
 func NewMessages(db *gorm.DB, schedulerInstance *scheduler.Scheduler, tmuxClient *tmux.Client) *Messages {
-	// Create messages table - grouped by session as requested
-	messagesColumns := []table.Column{
-		{Title: "ID", Width: 6},
-		{Title: "Session", Width: 12},
-		{Title: "Target", Width: 12},
-		{Title: "Priority", Width: 8},
-		{Title: "Content", Width: 30},
-		{Title: "Scheduled", Width: 16},
-		{Title: "Status", Width: 10},
-		{Title: "Retries", Width: 7},
-	}
-
-	messagesTable := table.New(
-		table.WithColumns(messagesColumns),
+	// Scheduled messages share the table, selection and bulk actions of the scheduler view
+	messagesTable := components.NewMessageTable(components.MessageTableOptions{
+		Height:  15,
+		Focused: true,
+	})
+
+	failedTable := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "", Width: 1},
+			{Title: "ID", Width: 6},
+			{Title: "Target", Width: 12},
+			{Title: "Content", Width: 30},
+			{Title: "Failed", Width: 11},
+			{Title: "Attempts", Width: 8},
+			{Title: "Class", Width: 16},
+			{Title: "Error", Width: 30},
+		}),
 		table.WithFocused(true),
-		table.WithHeight(15),
+		table.WithHeight(10),
 	)
 
 	// Create form inputs for message editing
@@ -149,42 +204,60 @@ func NewMessages(db *gorm.DB, schedulerInstance *scheduler.Scheduler, tmuxClient
 		scheduler:     schedulerInstance,
 		tmuxClient:    tmuxClient,
 		messagesTable: messagesTable,
+		failedTable:   failedTable,
 		targetInput:   targetInput,
 		contentInput:  contentInput,
 		priorityInput: priorityInput,
 		whenInput:     whenInput,
 		sessionGroups: make(map[string][]database.Message),
+		attempts:      make(map[uint][]database.MessageAttempt),
+		marked:        make(map[uint]bool),
 		keyMap:        DefaultMessagesKeyMap(),
 	}
 
-	m.initStyles()
+	m.initStyles(theme.Current())
 	return m
 }
 
+// SetTheme restyles the view with the named theme
+func (m *Messages) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	m.initStyles(t)
+}
+
+// KeySections returns the view's key bindings for remapping and the help view
+func (m *Messages) KeySections() []keys.Section {
+	actions := append(m.keyMap.Actions(), m.messagesTable.KeyActions()...)
+	return []keys.Section{{Name: "messages", Title: "Messages", Actions: actions}}
+}
+
 // initStyles initializes the messages view styles
-func (m *Messages) initStyles() {
+func (m *Messages) initStyles(t theme.Theme) {
 	m.titleStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
+		Foreground(t.Text).
 		MarginBottom(1)
 
 	m.selectedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("57")).
+		Foreground(t.SelectedText).
+		Background(t.Selected).
 		Bold(true)
 
 	m.inactiveStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8"))
+		Foreground(t.Muted)
 
 	m.formStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("12")).
+		BorderForeground(t.Accent).
 		Padding(1).
 		Width(70)
 
 	m.errorStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("9")).
+		Foreground(t.Danger).
 		Bold(true)
+
+	m.messagesTable.SetTheme(t.Name)
+	m.failedTable.SetStyles(t.TableStyles())
 }
 
 // Init initializes the messages view
@@ -206,15 +279,33 @@ func (m *Messages) Update(msg tea.Msg) (*Messages, tea.Cmd) {
 		if m.showForm {
 			return m.handleFormKeys(msg)
 		}
+		if !m.showFailed && m.messagesTable.IsPromptActive() {
+			var cmd tea.Cmd
+			m.messagesTable, cmd = m.messagesTable.Update(msg)
+			return m, cmd
+		}
 		return m.handleTableKeys(msg)
 
+	case tea.MouseMsg:
+		if m.showForm {
+			return m, nil
+		}
+		return m.handleMouse(msg)
+
+	case components.MessageBulkMsg:
+		return m, applyBulk(msg)
+
 	case types.RefreshDataMsg:
 		if msg.Type == "all" || msg.Type == "messages" {
 			if msg.Data != nil {
 				// Handle message data refresh in main thread
-				if messages, ok := msg.Data.([]database.Message); ok {
-					m.refreshMessagesWithData(messages)
+				switch data := msg.Data.(type) {
+				case []database.Message:
+					m.refreshMessagesWithData(data)
+				case failedMessagesData:
+					m.refreshFailedWithData(data)
 				}
+				m.applySelection()
 			} else {
 				// Trigger new data fetch
 				cmds = append(cmds, m.refreshData())
@@ -223,20 +314,25 @@ func (m *Messages) Update(msg tea.Msg) (*Messages, tea.Cmd) {
 
 	case types.SuccessMsg:
 		// After successful operations, trigger a refresh
-		if msg.Title == "Message Created" || msg.Title == "Message Updated" || msg.Title == "Message Deleted" {
+		switch msg.Title {
+		case "Message Created", "Message Updated", "Message Deleted", "Messages Requeued", "Messages Deleted", "Messages Updated":
 			cmds = append(cmds, m.refreshData())
 		}
 	}
 
-	// Update table
-	m.messagesTable, _ = m.messagesTable.Update(msg)
+	// Update the table of the current tab
+	if m.showFailed {
+		m.failedTable, _ = m.failedTable.Update(msg)
+	} else {
+		m.messagesTable, _ = m.messagesTable.Update(msg)
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
-// IsFormActive returns true if the form is currently active
+// IsFormActive returns true if the form or the bulk action prompt is active
 func (m *Messages) IsFormActive() bool {
-	return m.showForm
+	return m.showForm || m.messagesTable.IsPromptActive()
 }
 
 // handleFormKeys handles key presses when form is shown
@@ -288,6 +384,17 @@ func (m *Messages) handleFormKeys(msg tea.KeyMsg) (*Messages, tea.Cmd) {
 func (m *Messages) handleTableKeys(msg tea.KeyMsg) (*Messages, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	// Moving on before a pending selection arrives cancels it
+	m.selectID = 0
+
+	if key.Matches(msg, m.keyMap.SwitchTab) {
+		m.showFailed = !m.showFailed
+		return m, m.refreshData()
+	}
+	if m.showFailed {
+		return m.handleFailedKeys(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keyMap.NewMessage):
 		m.showForm = true
@@ -298,28 +405,95 @@ func (m *Messages) handleTableKeys(msg tea.KeyMsg) (*Messages, tea.Cmd) {
 		return m, nil
 
 	case key.Matches(msg, m.keyMap.EditMessage):
-		if len(m.messages) > 0 {
-			selected := m.messagesTable.Cursor()
-			if selected < len(m.messages) {
-				cmds = append(cmds, m.startEditMessage(m.messages[selected]))
-			}
+		if selected := m.SelectedMessage(); selected != nil {
+			cmds = append(cmds, m.startEditMessage(*selected))
 		}
 
 	case key.Matches(msg, m.keyMap.DeleteMessage):
-		if len(m.messages) > 0 {
-			selected := m.messagesTable.Cursor()
-			if selected < len(m.messages) {
-				cmds = append(cmds, m.deleteMessage(m.messages[selected].ID))
+		// Deletes the selected messages, or the one under the cursor
+		if len(m.messagesTable.SelectedIDs()) == 0 {
+			if selected := m.SelectedMessage(); selected != nil {
+				cmds = append(cmds, m.deleteMessage(selected.ID))
 			}
+		} else if ids := m.messagesTable.TargetIDs(); len(ids) > 0 {
+			m.messagesTable.ClearSelection()
+			cmds = append(cmds, applyBulk(components.MessageBulkMsg{
+				IDs:    ids,
+				Action: database.BulkMessageAction{Kind: database.BulkDelete},
+			}))
 		}
 
-	case key.Matches(msg, m.keyMap.RefreshMessages):
-		cmds = append(cmds, m.refreshData())
+	default:
+		// Selection, bulk actions and cursor movement
+		var cmd tea.Cmd
+		m.messagesTable, cmd = m.messagesTable.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// handleFailedKeys handles key presses in the failed messages tab. Requeue
+// and delete act on the selected messages, or the one under the cursor.
+func (m *Messages) handleFailedKeys(msg tea.KeyMsg) (*Messages, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.ToggleMark):
+		if cursor := m.failedTable.Cursor(); cursor < len(m.failed) {
+			id := m.failed[cursor].ID
+			if m.marked[id] {
+				delete(m.marked, id)
+			} else {
+				m.marked[id] = true
+			}
+			m.refreshFailedRows()
+		}
+
+	case key.Matches(msg, m.keyMap.MarkAll):
+		if len(m.marked) == len(m.failed) {
+			m.marked = make(map[uint]bool)
+		} else {
+			for _, message := range m.failed {
+				m.marked[message.ID] = true
+			}
+		}
+		m.refreshFailedRows()
+
+	case key.Matches(msg, m.keyMap.Requeue):
+		if ids := m.failedSelection(); len(ids) > 0 {
+			return m, m.requeueFailed(ids)
+		}
+
+	case key.Matches(msg, m.keyMap.DeleteMessage):
+		if ids := m.failedSelection(); len(ids) > 0 {
+			return m, m.deleteFailed(ids)
+		}
+
+	default:
+		// Cursor movement
+		var cmd tea.Cmd
+		m.failedTable, cmd = m.failedTable.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// failedSelection returns the IDs of the selected failed messages, or of the one under the cursor
+func (m *Messages) failedSelection() []uint {
+	var ids []uint
+	for _, message := range m.failed {
+		if m.marked[message.ID] {
+			ids = append(ids, message.ID)
+		}
+	}
+	if len(ids) == 0 {
+		if cursor := m.failedTable.Cursor(); cursor < len(m.failed) {
+			ids = append(ids, m.failed[cursor].ID)
+		}
+	}
+	return ids
+}
+
 // View renders the messages view
 func (m *Messages) View() string {
 	if m.width == 0 {
@@ -330,7 +504,14 @@ func (m *Messages) View() string {
 		return m.renderForm()
 	}
 
-	var content []string
+	content := []string{m.renderTabs()}
+
+	if m.showFailed {
+		content = append(content, m.renderFailedTable())
+		content = append(content, m.renderAttempts())
+		content = append(content, m.renderHelp())
+		return lipgloss.JoinVertical(lipgloss.Left, content...)
+	}
 
 	// Messages table (grouped by session)
 	content = append(content, m.renderMessagesTable())
@@ -344,6 +525,62 @@ func (m *Messages) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, content...)
 }
 
+// tabLabels returns the labels of the scheduled and failed tabs
+func (m *Messages) tabLabels() []string {
+	return []string{
+		fmt.Sprintf(" Scheduled (%d) ", len(m.messages)),
+		fmt.Sprintf(" Failed (%d) ", len(m.failed)),
+	}
+}
+
+// renderTabs renders the tabs on the first line, highlighting the current one
+func (m *Messages) renderTabs() string {
+	labels := m.tabLabels()
+	if m.showFailed {
+		return m.inactiveStyle.Render(labels[0]) + " " + m.selectedStyle.Render(labels[1])
+	}
+	return m.selectedStyle.Render(labels[0]) + " " + m.inactiveStyle.Render(labels[1])
+}
+
+// handleMouse switches tabs on a click on the first line, and selects clicked
+// rows and scrolls with the wheel in the table of the current tab
+func (m *Messages) handleMouse(msg tea.MouseMsg) (*Messages, tea.Cmd) {
+	if msg.Y == 0 {
+		labels := m.tabLabels()
+		if !components.IsLeftClick(msg) || msg.X > lipgloss.Width(labels[0])+1+lipgloss.Width(labels[1]) {
+			return m, nil
+		}
+		if failed := msg.X > lipgloss.Width(labels[0]); failed != m.showFailed {
+			m.showFailed = failed
+			return m, m.refreshData()
+		}
+		return m, nil
+	}
+
+	if !m.showFailed {
+		// The table follows the tabs and its title
+		top := 1 + lipgloss.Height(m.renderMessagesTable()) - lipgloss.Height(m.messagesTable.View())
+		m.messagesTable.HandleMouse(msg, msg.Y-top)
+		m.selectID = 0
+		return m, nil
+	}
+
+	tbl, section := &m.failedTable, m.renderFailedTable()
+	if components.ScrollTable(tbl, msg) {
+		m.selectID = 0
+		return m, nil
+	}
+	if components.IsLeftClick(msg) {
+		// The table follows the tabs and its title
+		top := 1 + lipgloss.Height(section) - lipgloss.Height(tbl.View())
+		if row, ok := components.TableRowAt(*tbl, msg.Y-top); ok {
+			tbl.SetCursor(row)
+			m.selectID = 0
+		}
+	}
+	return m, nil
+}
+
 // renderMessagesTable renders the messages table
 func (m *Messages) renderMessagesTable() string {
 	title := "Scheduled Messages (grouped by session)"
@@ -354,12 +591,65 @@ func (m *Messages) renderMessagesTable() string {
 		m.messagesTable.View())
 }
 
+// renderFailedTable renders the dead-lettered messages
+func (m *Messages) renderFailedTable() string {
+	title := fmt.Sprintf("Failed Messages (%d)", len(m.failed))
+	if len(m.marked) > 0 {
+		title += fmt.Sprintf(", %d selected", len(m.marked))
+	}
+	title = m.selectedStyle.Render("► " + title)
+
+	if len(m.failed) == 0 {
+		return fmt.Sprintf("%s\n%s", m.titleStyle.Render(title), m.inactiveStyle.Render("No failed messages"))
+	}
+
+	return fmt.Sprintf("%s\n%s",
+		m.titleStyle.Render(title),
+		m.failedTable.View())
+}
+
+// renderAttempts renders the attempt history of the failed message under the cursor
+func (m *Messages) renderAttempts() string {
+	cursor := m.failedTable.Cursor()
+	if cursor >= len(m.failed) {
+		return ""
+	}
+
+	message := m.failed[cursor]
+	attempts := m.attempts[message.ID]
+	lines := []string{fmt.Sprintf("\nAttempts for #%d:", message.ID)}
+	if len(attempts) == 0 {
+		lines = append(lines, "  no attempts recorded")
+	}
+	// The most recent attempts are the interesting ones
+	if len(attempts) > 5 {
+		lines = append(lines, fmt.Sprintf("  ... %d earlier attempts", len(attempts)-5))
+		attempts = attempts[len(attempts)-5:]
+	}
+	for _, attempt := range attempts {
+		line := fmt.Sprintf("  #%d %s %s", attempt.Attempt, attempt.CreatedAt.Format("01-02 15:04:05"), attempt.Outcome)
+		if attempt.ErrorClass != "" {
+			line += " " + attempt.ErrorClass
+		}
+		if attempt.Error != "" {
+			line += ": " + attempt.Error
+		}
+		if m.width > 8 && len(line) > m.width-4 {
+			line = line[:m.width-7] + "..."
+		}
+		lines = append(lines, line)
+	}
+
+	return m.inactiveStyle.Render(strings.Join(lines, "\n"))
+}
+
 // renderStats renders message statistics
 func (m *Messages) renderStats() string {
 	totalMessages := len(m.messages)
 	pendingMessages := 0
 	sentMessages := 0
 	failedMessages := 0
+	expiredMessages := 0
 	sessionCount := len(m.sessionGroups)
 
 	for _, msg := range m.messages {
@@ -370,12 +660,14 @@ func (m *Messages) renderStats() string {
 			sentMessages++
 		case database.MessageStatusFailed:
 			failedMessages++
+		case database.MessageStatusExpired:
+			expiredMessages++
 		}
 	}
 
 	stats := fmt.Sprintf(
-		"\nMessages: %d total (%d pending, %d sent, %d failed) across %d sessions",
-		totalMessages, pendingMessages, sentMessages, failedMessages, sessionCount,
+		"\nMessages: %d total (%d pending, %d sent, %d failed, %d expired) across %d sessions",
+		totalMessages, pendingMessages, sentMessages, failedMessages, expiredMessages, sessionCount,
 	)
 
 	return m.inactiveStyle.Render(stats)
@@ -408,7 +700,11 @@ func (m *Messages) renderForm() string {
 
 // renderHelp renders the help text
 func (m *Messages) renderHelp() string {
-	help := "\nn: New Message  e: Edit  d: Delete  r: Refresh"
+	k := m.keyMap
+	help := "\n" + keys.Hint(k.NewMessage, k.EditMessage, k.DeleteMessage, k.SwitchTab) + "\n" + m.messagesTable.Hint()
+	if m.showFailed {
+		help = "\n" + keys.Hint(k.ToggleMark, k.MarkAll, k.Requeue, k.DeleteMessage, k.SwitchTab)
+	}
 	return m.inactiveStyle.Render(help)
 }
 
@@ -428,27 +724,34 @@ func (m *Messages) Refresh() tea.Cmd {
 func (m *Messages) updateTableSizes() {
 	tableWidth := m.width - 4
 
-	// Adjust content column width based on available space
-	columns := m.messagesTable.Columns()
-	if len(columns) > 4 {
-		contentWidth := 20
-		if tableWidth-60 > contentWidth {
-			contentWidth = tableWidth - 60
-		}
-		columns[4].Width = contentWidth // Content column
-		m.messagesTable.SetColumns(columns)
-	}
-
-	m.messagesTable.SetWidth(tableWidth)
 	tableHeight := 15
 	if (m.height*2)/3 < tableHeight {
 		tableHeight = (m.height * 2) / 3
 	}
-	m.messagesTable.SetHeight(tableHeight)
+	m.messagesTable.SetSize(tableWidth, tableHeight)
+
+	// The failed table leaves room for the attempt history below it
+	failedColumns := m.failedTable.Columns()
+	if len(failedColumns) > 7 {
+		errorWidth := 20
+		if tableWidth-110 > errorWidth {
+			errorWidth = tableWidth - 110
+		}
+		failedColumns[7].Width = errorWidth
+		m.failedTable.SetColumns(failedColumns)
+	}
+	m.failedTable.SetWidth(tableWidth)
+	m.failedTable.SetHeight(max(3, tableHeight-6))
 }
 
 // refreshData refreshes all messages data
 func (m *Messages) refreshData() tea.Cmd {
+	return tea.Batch(m.refreshMessages(), m.refreshFailed())
+}
+
+// refreshMessages loads the most recent messages, and the pinned message
+func (m *Messages) refreshMessages() tea.Cmd {
+	pinnedID := m.pinnedID
 	return tea.Cmd(func() tea.Msg {
 		if m.db == nil {
 			return types.ErrorMsg{Title: "Database Error", Message: "Database not available"}
@@ -464,6 +767,17 @@ func (m *Messages) refreshData() tea.Cmd {
 			return types.ErrorMsg{Title: "Refresh failed", Message: err.Error()}
 		}
 
+		// A message picked in the command palette may be older than the latest.
+		// It is older than all of them, so it goes last.
+		if pinnedID != 0 && !slices.ContainsFunc(messages, func(message database.Message) bool {
+			return message.ID == pinnedID
+		}) {
+			var pinned database.Message
+			if err := m.db.Preload("Window").First(&pinned, pinnedID).Error; err == nil {
+				messages = append(messages, pinned)
+			}
+		}
+
 		// Return the data in the message so UI updates happen in main thread
 		return types.RefreshDataMsg{
 			Type: "messages",
@@ -472,11 +786,202 @@ func (m *Messages) refreshData() tea.Cmd {
 	})
 }
 
+// SelectMessage shows the tab of the message with the given ID and highlights
+// it, once it has been loaded
+func (m *Messages) SelectMessage(id uint, failed bool) tea.Cmd {
+	m.showFailed = failed
+	m.pinnedID = id
+	m.selectID = id
+	m.applySelection()
+	return m.refreshData()
+}
+
+// SelectedMessage returns the message under the cursor in the current tab
+func (m *Messages) SelectedMessage() *database.Message {
+	if m.showFailed {
+		cursor := m.failedTable.Cursor()
+		if cursor < 0 || cursor >= len(m.failed) {
+			return nil
+		}
+		return &m.failed[cursor]
+	}
+
+	selected := m.messagesTable.GetSelectedMessage()
+	if selected == nil {
+		return nil
+	}
+	for i := range m.messages {
+		if m.messages[i].ID == selected.ID {
+			return &m.messages[i]
+		}
+	}
+	return nil
+}
+
+// applySelection moves the cursor to the message waiting to be selected, if it is loaded
+func (m *Messages) applySelection() {
+	if m.selectID == 0 {
+		return
+	}
+
+	if m.showFailed {
+		for i, message := range m.failed {
+			if message.ID == m.selectID {
+				m.failedTable.SetCursor(i)
+				m.selectID = 0
+				return
+			}
+		}
+		return
+	}
+
+	// Messages without a window have no row
+	row := 0
+	for _, message := range m.messages {
+		if message.Window.Target == "" {
+			continue
+		}
+		if message.ID == m.selectID {
+			m.messagesTable.SetSelectedIndex(row)
+			m.selectID = 0
+			return
+		}
+		row++
+	}
+}
+
+// refreshFailed loads the failed messages and their attempt history
+func (m *Messages) refreshFailed() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if m.db == nil {
+			return types.ErrorMsg{Title: "Database Error", Message: "Database not available"}
+		}
+
+		messages, err := database.GetFailedMessages(m.db, 0)
+		if err != nil {
+			return types.ErrorMsg{Title: "Refresh failed", Message: err.Error()}
+		}
+
+		attempts := make(map[uint][]database.MessageAttempt, len(messages))
+		for _, message := range messages {
+			history, err := database.GetMessageAttempts(m.db, message.ID)
+			if err != nil {
+				return types.ErrorMsg{Title: "Refresh failed", Message: err.Error()}
+			}
+			attempts[message.ID] = history
+		}
+
+		return types.RefreshDataMsg{
+			Type: "messages",
+			Data: failedMessagesData{messages: messages, attempts: attempts},
+		}
+	})
+}
+
+// refreshFailedWithData shows freshly loaded failed messages (called from main thread)
+func (m *Messages) refreshFailedWithData(data failedMessagesData) {
+	m.failed = data.messages
+	m.attempts = data.attempts
+
+	// Forget selections of messages that are no longer failed
+	current := make(map[uint]bool, len(m.failed))
+	for _, message := range m.failed {
+		current[message.ID] = true
+	}
+	for id := range m.marked {
+		if !current[id] {
+			delete(m.marked, id)
+		}
+	}
+
+	m.refreshFailedRows()
+}
+
+// refreshFailedRows rebuilds the failed table rows
+func (m *Messages) refreshFailedRows() {
+	rows := make([]table.Row, 0, len(m.failed))
+	for _, message := range m.failed {
+		mark := " "
+		if m.marked[message.ID] {
+			mark = "✓"
+		}
+
+		target := message.Window.Target
+		if message.Route != "" {
+			target = message.Route
+		}
+
+		content := message.Content
+		if len(content) > 30 {
+			content = content[:27] + "..."
+		}
+
+		class := ""
+		if history := m.attempts[message.ID]; len(history) > 0 {
+			class = history[len(history)-1].ErrorClass
+		}
+
+		rows = append(rows, table.Row{
+			mark,
+			strconv.Itoa(int(message.ID)),
+			target,
+			content,
+			message.UpdatedAt.Format("01-02 15:04"),
+			strconv.Itoa(message.Retries),
+			class,
+			message.Error,
+		})
+	}
+	m.failedTable.SetRows(rows)
+}
+
+// requeueFailed moves failed messages back to the queue
+func (m *Messages) requeueFailed(ids []uint) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		requeued, err := database.RequeueFailedMessages(m.db, ids, 0, "")
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to requeue messages", Message: err.Error()}
+		}
+		if m.remote != nil {
+			if err := m.remote.Request(daemon.RequestProcess); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		} else if m.scheduler != nil {
+			m.scheduler.TriggerImmediateProcessing()
+		}
+
+		return types.SuccessMsg{
+			Title:   "Messages Requeued",
+			Message: fmt.Sprintf("Requeued %d messages", requeued),
+		}
+	})
+}
+
+// SetRemote forwards processing to a running daemon instead of the local scheduler
+func (m *Messages) SetRemote(client *daemon.Client) {
+	m.remote = client
+}
+
+// deleteFailed deletes failed messages
+func (m *Messages) deleteFailed(ids []uint) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		deleted, err := database.DeleteFailedMessages(m.db, ids)
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to delete messages", Message: err.Error()}
+		}
+
+		return types.SuccessMsg{
+			Title:   "Messages Deleted",
+			Message: fmt.Sprintf("Deleted %d failed messages", deleted),
+		}
+	})
+}
+
 // refreshMessagesWithData refreshes messages data using provided messages slice (called from main thread)
 func (m *Messages) refreshMessagesWithData(messages []database.Message) {
 	// Group by session for display as requested
 	m.sessionGroups = make(map[string][]database.Message)
-	var rows []table.Row
+	var rows []types.MessageDisplayInfo
 
 	for _, msg := range messages {
 		// Skip messages without window info
@@ -487,29 +992,28 @@ func (m *Messages) refreshMessagesWithData(messages []database.Message) {
 		sessionName := msg.Window.SessionName
 		m.sessionGroups[sessionName] = append(m.sessionGroups[sessionName], msg)
 
-		// Truncate content for display
-		content := msg.Content
-		if len(content) > 30 {
-			content = content[:27] + "..."
+		// Routed messages show their route until they are sent
+		target := msg.Window.Target
+		if msg.Route != "" && msg.Status == database.MessageStatusPending {
+			target = msg.Route
 		}
 
-		// Format scheduled time
-		scheduledTime := msg.ScheduledTime.Format("01-02 15:04")
-
-		rows = append(rows, table.Row{
-			strconv.Itoa(int(msg.ID)),
-			sessionName,
-			msg.Window.Target,
-			strconv.Itoa(msg.Priority),
-			content,
-			scheduledTime,
-			msg.Status,
-			strconv.Itoa(msg.Retries),
+		rows = append(rows, types.MessageDisplayInfo{
+			ID:            msg.ID,
+			SessionName:   target,
+			Content:       msg.Content,
+			Priority:      msg.Priority,
+			Status:        msg.Status,
+			ScheduledTime: msg.ScheduledTime,
+			SentTime:      msg.SentAt,
+			CreatedAt:     msg.CreatedAt,
+			Error:         msg.Error,
+			TimeUntilSend: max(0, time.Until(msg.ScheduledTime)),
 		})
 	}
 
 	m.messages = messages
-	m.messagesTable.SetRows(rows)
+	m.messagesTable.SetMessages(rows)
 }
 
 // startEditMessage starts editing a message
@@ -522,6 +1026,12 @@ func (m *Messages) startEditMessage(message database.Message) tea.Cmd {
 				Message: "Message has already been sent",
 			}
 		}
+		if message.Status == database.MessageStatusSending {
+			return types.ErrorMsg{
+				Title:   "Cannot Edit",
+				Message: "Message is being sent",
+			}
+		}
 
 		// Load window info if not already loaded
 		if message.Window.Target == "" {
@@ -529,7 +1039,11 @@ func (m *Messages) startEditMessage(message database.Message) tea.Cmd {
 		}
 
 		// Pre-fill form with existing values
-		m.targetInput.SetValue(message.Window.Target)
+		if message.Route != "" {
+			m.targetInput.SetValue(message.Route)
+		} else {
+			m.targetInput.SetValue(message.Window.Target)
+		}
 		m.contentInput.SetValue(message.Content)
 		m.priorityInput.SetValue(strconv.Itoa(message.Priority))
 
@@ -578,21 +1092,14 @@ func (m *Messages) handleFormSubmit() (*Messages, tea.Cmd) {
 
 	// Parse schedule time
 	scheduledTime := time.Now()
-	if when != "" && when != "now" {
-		if when[0] == '+' {
-			if duration, err := time.ParseDuration(when[1:]); err == nil {
-				scheduledTime = roundTimeToMinute(time.Now().Add(duration))
-			}
-		} else {
-			if t, err := time.Parse("15:04", when); err == nil {
-				// If time is in the past, schedule for tomorrow
-				if t.Before(time.Now()) {
-					scheduledTime = t.AddDate(0, 0, 1)
-				} else {
-					scheduledTime = t
-				}
+	if when != "" {
+		parsed, err := utils.ParseScheduleTime(when, scheduledTime)
+		if err != nil {
+			return m, func() tea.Msg {
+				return types.ErrorMsg{Title: "Validation Error", Message: err.Error()}
 			}
 		}
+		scheduledTime = parsed
 	}
 
 	// Clear form and hide it
@@ -644,19 +1151,33 @@ func (m *Messages) updateMessage(messageID uint, target, content string, priorit
 		updates["scheduled_time"] = scheduledTime
 
 		// Handle target change if needed
-		if target != message.Window.Target {
+		if scheduler.IsRoute(target) {
+			route, err := scheduler.ParseRoute(target)
+			if err != nil {
+				return types.ErrorMsg{Title: "Invalid route", Message: err.Error()}
+			}
+			updates["route"] = route.String()
+		} else if target != message.Window.Target || message.Route != "" {
 			window, err := database.GetTmuxWindow(m.db, target)
 			if err != nil {
 				return types.ErrorMsg{Title: "Invalid target", Message: fmt.Sprintf("Target '%s' not found", target)}
 			}
 			updates["window_id"] = window.ID
+			updates["route"] = ""
 		}
 
 		// Apply updates
+		windowIDs := []uint{message.WindowID}
+		if windowID, ok := updates["window_id"].(uint); ok && windowID != message.WindowID {
+			windowIDs = append(windowIDs, windowID)
+		}
 		err = m.db.Model(&message).Updates(updates).Error
 		if err != nil {
 			return types.ErrorMsg{Title: "Failed to update message", Message: err.Error()}
 		}
+		for _, windowID := range windowIDs {
+			_ = database.SyncQueueMessageCount(m.db, windowID)
+		}
 
 		// Data will refresh via SuccessMsg handling
 
@@ -683,12 +1204,19 @@ func (m *Messages) deleteMessage(messageID uint) tea.Cmd {
 				Message: "Message has already been sent",
 			}
 		}
+		if message.Status == database.MessageStatusSending {
+			return types.ErrorMsg{
+				Title:   "Cannot Delete",
+				Message: "Message is being sent",
+			}
+		}
 
 		// Delete message
 		err = m.db.Delete(&message).Error
 		if err != nil {
 			return types.ErrorMsg{Title: "Failed to delete message", Message: err.Error()}
 		}
+		_ = database.SyncQueueMessageCount(m.db, message.WindowID)
 
 		// Data will refresh via SuccessMsg handling
 
@@ -709,9 +1237,4 @@ func (m *Messages) clearForm() {
 	m.editingID = 0
 }
 
-// roundTimeToMinute rounds a time down to the nearest minute (ignores seconds)
-func roundTimeToMinute(t time.Time) time.Time {
-	return t.Truncate(time.Minute)
-}
-
 // Helper functions - remove these as they're duplicated in other files
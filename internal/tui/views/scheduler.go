@@ -2,17 +2,23 @@ package views
 
 import (
 	"fmt"
+	"log"
 	"strconv"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
-	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/daemon"
+	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/scheduler"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/types"
 )
 
@@ -20,25 +26,24 @@ import (
 type Scheduler struct {
 	db        *gorm.DB
 	scheduler *scheduler.Scheduler
+	remote    *daemon.Client // set when attached to a running daemon
 	// sessionMonitor removed - using window-based architecture
 
 	// UI components
-	messagesTable table.Model
+	messagesTable *components.MessageTable
 	queueTable    table.Model
 
-	// Form inputs for new message
-	sessionInput  textinput.Model
-	contentInput  textinput.Model
-	priorityInput textinput.Model
-	whenInput     textinput.Model
+	// Full-screen composer for new messages
+	composer *components.Composer
 
 	// State
 	width       int
 	height      int
-	activeTable string // "messages", "queue", "form"
-	showForm    bool
+	activeTable string // "messages", "queue"
+	showForm    bool   // the composer is open
 	messages    []types.MessageDisplayInfo
 	queueItems  []types.MessageDisplayInfo
+	hold        string // pause or quiet hours in effect
 
 	// Key bindings
 	keyMap SchedulerKeyMap
@@ -57,11 +62,8 @@ type SchedulerKeyMap struct {
 	DeleteMessage   key.Binding
 	SendNow         key.Binding
 	CancelMessage   key.Binding
-	RefreshQueue    key.Binding
 	SwitchTable     key.Binding
 	ShowForm        key.Binding
-	CancelForm      key.Binding
-	SubmitForm      key.Binding
 	PauseScheduler  key.Binding
 	ResumeScheduler key.Binding
 }
@@ -85,10 +87,6 @@ func DefaultSchedulerKeyMap() SchedulerKeyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "cancel message"),
 		),
-		RefreshQueue: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
-		),
 		SwitchTable: key.NewBinding(
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "switch table"),
@@ -97,14 +95,6 @@ func DefaultSchedulerKeyMap() SchedulerKeyMap {
 			key.WithKeys("f"),
 			key.WithHelp("f", "show form"),
 		),
-		CancelForm: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "cancel"),
-		),
-		SubmitForm: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "submit"),
-		),
 		PauseScheduler: key.NewBinding(
 			key.WithKeys("p"),
 			key.WithHelp("p", "pause scheduler"),
@@ -116,24 +106,27 @@ func DefaultSchedulerKeyMap() SchedulerKeyMap {
 	}
 }
 
-// NewScheduler creates a new scheduler view
-func NewScheduler(db *gorm.DB, schedulerInstance *scheduler.Scheduler, windowDiscovery interface{}) *Scheduler {
-	// Create messages table
-	messagesColumns := []table.Column{
-		{Title: "ID", Width: 4},
-		{Title: "Session", Width: 12},
-		{Title: "Content", Width: 30},
-		{Title: "Priority", Width: 8},
-		{Title: "Status", Width: 8},
-		{Title: "Scheduled", Width: 16},
-		{Title: "Time Until", Width: 12},
+// Actions returns the bindings by their names in the tui.keys config
+func (k *SchedulerKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "new", Binding: &k.NewMessage},
+		{Name: "show_form", Binding: &k.ShowForm},
+		{Name: "delete", Binding: &k.DeleteMessage},
+		{Name: "send_now", Binding: &k.SendNow},
+		{Name: "cancel", Binding: &k.CancelMessage},
+		{Name: "switch_table", Binding: &k.SwitchTable},
+		{Name: "pause", Binding: &k.PauseScheduler},
+		{Name: "resume", Binding: &k.ResumeScheduler},
 	}
+}
 
-	messagesTable := table.New(
-		table.WithColumns(messagesColumns),
-		table.WithFocused(true),
-		table.WithHeight(12),
-	)
+// NewScheduler creates a new scheduler view
+func NewScheduler(db *gorm.DB, schedulerInstance *scheduler.Scheduler, windowDiscovery interface{}) *Scheduler {
+	// Create messages table, with selection for bulk actions
+	messagesTable := components.NewMessageTable(components.MessageTableOptions{
+		Height:  12,
+		Focused: true,
+	})
 
 	// Create queue table (active/processing messages)
 	queueColumns := []table.Column{
@@ -151,70 +144,65 @@ func NewScheduler(db *gorm.DB, schedulerInstance *scheduler.Scheduler, windowDis
 		table.WithHeight(8),
 	)
 
-	// Create form inputs
-	sessionInput := textinput.New()
-	sessionInput.Placeholder = "session-name"
-	sessionInput.Focus()
-	sessionInput.CharLimit = 50
-	sessionInput.Width = 30
-
-	contentInput := textinput.New()
-	contentInput.Placeholder = "Message content"
-	contentInput.CharLimit = 500
-	contentInput.Width = 50
-
-	priorityInput := textinput.New()
-	priorityInput.Placeholder = "1-10 (default: 5)"
-	priorityInput.CharLimit = 2
-	priorityInput.Width = 20
-
-	whenInput := textinput.New()
-	whenInput.Placeholder = "now, +5m, 14:30, etc."
-	whenInput.CharLimit = 20
-	whenInput.Width = 30
-
 	s := &Scheduler{
 		db:        db,
 		scheduler: schedulerInstance,
 		// sessionMonitor removed
 		messagesTable: messagesTable,
 		queueTable:    queueTable,
-		sessionInput:  sessionInput,
-		contentInput:  contentInput,
-		priorityInput: priorityInput,
-		whenInput:     whenInput,
+		composer:      components.NewComposer(config.GetTUIConfig().Templates),
 		activeTable:   "messages",
 		keyMap:        DefaultSchedulerKeyMap(),
 	}
 
-	s.initStyles()
+	s.initStyles(theme.Current())
 	return s
 }
 
+// SetTheme restyles the view and its composer with the named theme
+func (s *Scheduler) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	s.initStyles(t)
+	s.composer.SetTheme(name)
+}
+
+// KeySections returns the key bindings of the view, including the bulk
+// actions of its messages table, and its composer for remapping and the help view
+func (s *Scheduler) KeySections() []keys.Section {
+	actions := append(s.keyMap.Actions(), s.messagesTable.KeyActions()...)
+	return []keys.Section{
+		{Name: "scheduler", Title: "Scheduler", Actions: actions},
+		s.composer.KeySection(),
+	}
+}
+
 // initStyles initializes the scheduler view styles
-func (s *Scheduler) initStyles() {
+func (s *Scheduler) initStyles(t theme.Theme) {
 	s.titleStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
+		Foreground(t.Text).
 		MarginBottom(1)
 
 	s.selectedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("57")).
+		Foreground(t.SelectedText).
+		Background(t.Selected).
 		Bold(true)
 
 	s.inactiveStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8"))
+		Foreground(t.Muted)
 
 	s.formStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("12")).
+		BorderForeground(t.Accent).
 		Padding(1).
 		Width(60)
 
 	s.statusStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
+		Foreground(t.Success).
 		Bold(true)
+
+	s.messagesTable.SetTheme(t.Name)
+	s.queueTable.SetStyles(t.TableStyles())
 }
 
 // Init initializes the scheduler view
@@ -234,10 +222,39 @@ func (s *Scheduler) Update(msg tea.Msg) (*Scheduler, tea.Cmd) {
 
 	case tea.KeyMsg:
 		if s.showForm {
-			return s.handleFormKeys(msg)
+			var cmd tea.Cmd
+			s.composer, cmd = s.composer.Update(msg)
+			return s, cmd
+		}
+		if s.messagesTable.IsPromptActive() {
+			var cmd tea.Cmd
+			s.messagesTable, cmd = s.messagesTable.Update(msg)
+			return s, cmd
 		}
 		return s.handleTableKeys(msg)
 
+	case tea.MouseMsg:
+		if s.showForm {
+			return s, nil
+		}
+		return s.handleMouse(msg), nil
+
+	case components.ComposerSubmitMsg:
+		s.showForm = false
+		return s, s.scheduleMessage(msg.Message)
+
+	case components.MessageBulkMsg:
+		return s, applyBulk(msg)
+
+	case components.ComposerCancelMsg:
+		s.showForm = false
+		return s, nil
+
+	case components.ComposerEditedMsg:
+		var cmd tea.Cmd
+		s.composer, cmd = s.composer.Update(msg)
+		return s, cmd
+
 	case types.RefreshDataMsg:
 		if msg.Type == "all" || msg.Type == "scheduler" || msg.Type == "messages" {
 			if msg.Data != nil {
@@ -256,6 +273,10 @@ func (s *Scheduler) Update(msg tea.Msg) (*Scheduler, tea.Cmd) {
 				}); ok {
 					s.refreshMessagesWithData(dbMessages)
 					s.refreshQueueWithData()
+				} else if hold, ok := msg.Data.(schedulerHold); ok {
+					s.hold = hold.description
+				} else if windows, ok := msg.Data.(composerWindows); ok {
+					s.composer.SetWindows(windows)
 				}
 			} else {
 				// Trigger new data fetch
@@ -265,11 +286,19 @@ func (s *Scheduler) Update(msg tea.Msg) (*Scheduler, tea.Cmd) {
 
 	case types.SuccessMsg:
 		// After successful operations, trigger a refresh
-		if msg.Title == "Message Scheduled" || msg.Title == "Message Deleted" || msg.Title == "Message Updated" || msg.Title == "Message Canceled" {
+		switch msg.Title {
+		case "Message Scheduled", "Messages Updated", "Message Updated", "Message Canceled", "Sending Paused", "Sending Resumed":
 			cmds = append(cmds, s.refreshData())
 		}
 	}
 
+	// Cursor blinking in the composer
+	if s.showForm {
+		var cmd tea.Cmd
+		s.composer, cmd = s.composer.Update(msg)
+		return s, tea.Batch(append(cmds, cmd)...)
+	}
+
 	// Update tables
 	if s.activeTable == "messages" {
 		s.messagesTable, _ = s.messagesTable.Update(msg)
@@ -280,54 +309,15 @@ func (s *Scheduler) Update(msg tea.Msg) (*Scheduler, tea.Cmd) {
 	return s, tea.Batch(cmds...)
 }
 
-// IsFormActive returns true if the form is currently active
-func (s *Scheduler) IsFormActive() bool {
-	return s.showForm
+// OpenComposer opens the composer for a new message
+func (s *Scheduler) OpenComposer() tea.Cmd {
+	s.showForm = true
+	return tea.Batch(s.composer.Open(), s.loadComposerWindows())
 }
 
-// handleFormKeys handles key presses when form is shown
-func (s *Scheduler) handleFormKeys(msg tea.KeyMsg) (*Scheduler, tea.Cmd) {
-	var cmds []tea.Cmd
-
-	switch {
-	case key.Matches(msg, s.keyMap.CancelForm):
-		s.showForm = false
-		s.clearForm()
-		return s, nil
-
-	case key.Matches(msg, s.keyMap.SubmitForm):
-		return s.handleFormSubmit()
-
-	case msg.String() == "tab":
-		// Cycle through form inputs
-		if s.sessionInput.Focused() {
-			s.sessionInput.Blur()
-			s.contentInput.Focus()
-		} else if s.contentInput.Focused() {
-			s.contentInput.Blur()
-			s.priorityInput.Focus()
-		} else if s.priorityInput.Focused() {
-			s.priorityInput.Blur()
-			s.whenInput.Focus()
-		} else {
-			s.whenInput.Blur()
-			s.sessionInput.Focus()
-		}
-		return s, nil
-	}
-
-	// Update focused input
-	if s.sessionInput.Focused() {
-		s.sessionInput, _ = s.sessionInput.Update(msg)
-	} else if s.contentInput.Focused() {
-		s.contentInput, _ = s.contentInput.Update(msg)
-	} else if s.priorityInput.Focused() {
-		s.priorityInput, _ = s.priorityInput.Update(msg)
-	} else if s.whenInput.Focused() {
-		s.whenInput, _ = s.whenInput.Update(msg)
-	}
-
-	return s, tea.Batch(cmds...)
+// IsFormActive returns true if the form or a prompt of the messages table is active
+func (s *Scheduler) IsFormActive() bool {
+	return s.showForm || s.messagesTable.IsPromptActive()
 }
 
 // handleTableKeys handles key presses when tables are active
@@ -335,48 +325,53 @@ func (s *Scheduler) handleTableKeys(msg tea.KeyMsg) (*Scheduler, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch {
-	case key.Matches(msg, s.keyMap.NewMessage):
-		s.showForm = true
-		s.sessionInput.Focus()
-		return s, nil
+	case key.Matches(msg, s.keyMap.NewMessage), key.Matches(msg, s.keyMap.ShowForm):
+		return s, s.OpenComposer()
 
 	case key.Matches(msg, s.keyMap.DeleteMessage):
-		if s.activeTable == "messages" && len(s.messages) > 0 {
-			selected := s.messagesTable.Cursor()
-			if selected < len(s.messages) {
-				cmds = append(cmds, s.deleteMessage(s.messages[selected].ID))
+		// Deletes the selected messages, or the one under the cursor
+		if s.activeTable == "messages" {
+			if ids := s.messagesTable.TargetIDs(); len(ids) > 0 {
+				s.messagesTable.ClearSelection()
+				cmds = append(cmds, applyBulk(components.MessageBulkMsg{
+					IDs:    ids,
+					Action: database.BulkMessageAction{Kind: database.BulkDelete},
+				}))
 			}
 		}
 
 	case key.Matches(msg, s.keyMap.SendNow):
-		if s.activeTable == "messages" && len(s.messages) > 0 {
-			selected := s.messagesTable.Cursor()
-			if selected < len(s.messages) && s.messages[selected].Status == "pending" {
-				cmds = append(cmds, s.sendMessageNow(s.messages[selected].ID))
-			}
+		if selected := s.messagesTable.GetSelectedMessage(); s.activeTable == "messages" && selected != nil && selected.Status == "pending" {
+			cmds = append(cmds, s.sendMessageNow(selected.ID))
 		}
 
 	case key.Matches(msg, s.keyMap.CancelMessage):
-		if s.activeTable == "messages" && len(s.messages) > 0 {
-			selected := s.messagesTable.Cursor()
-			if selected < len(s.messages) && s.messages[selected].Status == "pending" {
-				cmds = append(cmds, s.cancelMessage(s.messages[selected].ID))
-			}
+		if selected := s.messagesTable.GetSelectedMessage(); s.activeTable == "messages" && selected != nil && selected.Status == "pending" {
+			cmds = append(cmds, s.cancelMessage(selected.ID))
 		}
 
 	case key.Matches(msg, s.keyMap.SwitchTable):
 		if s.activeTable == "messages" {
-			s.activeTable = "queue"
-			s.messagesTable.Blur()
-			s.queueTable.Focus()
+			s.focusTable("queue")
 		} else {
-			s.activeTable = "messages"
-			s.queueTable.Blur()
-			s.messagesTable.Focus()
+			s.focusTable("messages")
 		}
 
-	case key.Matches(msg, s.keyMap.RefreshQueue):
-		cmds = append(cmds, s.refreshData())
+	case key.Matches(msg, s.keyMap.PauseScheduler):
+		cmds = append(cmds, s.PauseSending())
+
+	case key.Matches(msg, s.keyMap.ResumeScheduler):
+		cmds = append(cmds, s.ResumeSending())
+
+	default:
+		// Selection, bulk actions and cursor movement
+		var cmd tea.Cmd
+		if s.activeTable == "messages" {
+			s.messagesTable, cmd = s.messagesTable.Update(msg)
+		} else {
+			s.queueTable, cmd = s.queueTable.Update(msg)
+		}
+		cmds = append(cmds, cmd)
 	}
 
 	return s, tea.Batch(cmds...)
@@ -389,7 +384,7 @@ func (s *Scheduler) View() string {
 	}
 
 	if s.showForm {
-		return s.renderForm()
+		return s.composer.View()
 	}
 
 	var content []string
@@ -409,12 +404,48 @@ func (s *Scheduler) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, content...)
 }
 
+// handleMouse selects clicked rows and scrolls the table under the wheel
+func (s *Scheduler) handleMouse(msg tea.MouseMsg) *Scheduler {
+	messagesSection := lipgloss.Height(s.renderSchedulerStatus()) + lipgloss.Height(s.renderMessagesTable())
+	messagesTop := messagesSection - lipgloss.Height(s.messagesTable.View())
+	queueTop := messagesSection + lipgloss.Height(s.renderQueueTable()) - lipgloss.Height(s.queueTable.View())
+
+	if msg.Y < messagesSection {
+		if components.IsLeftClick(msg) {
+			s.focusTable("messages")
+		}
+		s.messagesTable.HandleMouse(msg, msg.Y-messagesTop)
+		return s
+	}
+
+	if components.ScrollTable(&s.queueTable, msg) || !components.IsLeftClick(msg) {
+		return s
+	}
+	s.focusTable("queue")
+	if row, ok := components.TableRowAt(s.queueTable, msg.Y-queueTop); ok {
+		s.queueTable.SetCursor(row)
+	}
+	return s
+}
+
+// focusTable makes "messages" or "queue" the table that takes keys
+func (s *Scheduler) focusTable(name string) {
+	s.activeTable = name
+	if name == "messages" {
+		s.queueTable.Blur()
+		s.messagesTable.SetFocused(true)
+	} else {
+		s.messagesTable.SetFocused(false)
+		s.queueTable.Focus()
+	}
+}
+
 // renderSchedulerStatus renders the scheduler status section
 func (s *Scheduler) renderSchedulerStatus() string {
 	status := "🔄 Scheduler Status: "
 
 	// Get basic status info
-	smartEnabled := "Smart: ✓"
+	policy := "Policy: " + config.GetSchedulerConfig().Policy
 	cronEnabled := "Cron: ✓"
 
 	// TODO: Get actual scheduler state from scheduler instance
@@ -422,13 +453,16 @@ func (s *Scheduler) renderSchedulerStatus() string {
 	processingCount := 0
 
 	for _, msg := range s.messages {
-		if msg.Status == "processing" {
+		if msg.Status == database.MessageStatusSending {
 			processingCount++
 		}
 	}
 
-	statusLine := fmt.Sprintf("%s%s  %s  Pending: %d  Processing: %d",
-		status, smartEnabled, cronEnabled, pendingCount, processingCount)
+	statusLine := fmt.Sprintf("%s%s  %s  Pending: %d  Sending: %d",
+		status, policy, cronEnabled, pendingCount, processingCount)
+	if s.hold != "" {
+		statusLine += "  ⏸ " + s.hold
+	}
 
 	return s.statusStyle.Render(statusLine) + "\n"
 }
@@ -461,27 +495,11 @@ func (s *Scheduler) renderQueueTable() string {
 		s.queueTable.View())
 }
 
-// renderForm renders the new message form
-func (s *Scheduler) renderForm() string {
-	form := fmt.Sprintf(
-		"Schedule New Message\n\n"+
-			"Session:\n%s\n\n"+
-			"Content:\n%s\n\n"+
-			"Priority (1-10):\n%s\n\n"+
-			"When:\n%s\n\n"+
-			"Press Enter to schedule, Esc to cancel, Tab to navigate",
-		s.sessionInput.View(),
-		s.contentInput.View(),
-		s.priorityInput.View(),
-		s.whenInput.View(),
-	)
-
-	return s.formStyle.Render(form)
-}
-
 // renderHelp renders the help text
 func (s *Scheduler) renderHelp() string {
-	help := "\nn: New Message  d: Delete  s: Send Now  c: Cancel  tab: Switch Table  r: Refresh  p/P: Pause/Resume"
+	k := s.keyMap
+	help := "\n" + keys.Hint(k.NewMessage, k.DeleteMessage, k.SendNow, k.CancelMessage, k.SwitchTable,
+		k.PauseScheduler, k.ResumeScheduler) + "\n" + s.messagesTable.Hint()
 	return s.inactiveStyle.Render(help)
 }
 
@@ -490,6 +508,7 @@ func (s *Scheduler) SetSize(width, height int) {
 	s.width = width
 	s.height = height
 	s.updateTableSizes()
+	s.composer.SetSize(width, height)
 }
 
 // Refresh refreshes the scheduler data
@@ -502,17 +521,7 @@ func (s *Scheduler) updateTableSizes() {
 	tableWidth := s.width - 4
 
 	// Update messages table
-	columns := s.messagesTable.Columns()
-	if len(columns) > 0 {
-		// Adjust content column based on available width
-		contentWidth := max(20, tableWidth-70) // Reserve space for other columns
-
-		columns[2].Width = contentWidth // Content
-		s.messagesTable.SetColumns(columns)
-	}
-
-	s.messagesTable.SetWidth(tableWidth)
-	s.messagesTable.SetHeight(min(12, s.height/2))
+	s.messagesTable.SetSize(tableWidth, min(12, s.height/2))
 
 	// Update queue table
 	queueColumns := s.queueTable.Columns()
@@ -527,8 +536,37 @@ func (s *Scheduler) updateTableSizes() {
 	s.queueTable.SetHeight(min(8, s.height/3))
 }
 
+// schedulerHold carries the pause or quiet hours in effect to the main thread
+type schedulerHold struct {
+	description string
+}
+
 // refreshData refreshes all scheduler data
 func (s *Scheduler) refreshData() tea.Cmd {
+	return tea.Batch(s.refreshMessages(), s.refreshHold())
+}
+
+// refreshHold checks for a global pause or quiet hours
+func (s *Scheduler) refreshHold() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if s.scheduler == nil {
+			return nil
+		}
+		hold, err := s.scheduler.Hold()
+		if err != nil {
+			return types.ErrorMsg{Title: "Refresh failed", Message: err.Error()}
+		}
+
+		description := ""
+		if hold != nil {
+			description = hold.String()
+		}
+		return types.RefreshDataMsg{Type: "scheduler", Data: schedulerHold{description: description}}
+	})
+}
+
+// refreshMessages loads the most recent messages
+func (s *Scheduler) refreshMessages() tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		if s.db == nil {
 			return types.ErrorMsg{Title: "Database Error", Message: "Database not available"}
@@ -581,7 +619,6 @@ func (s *Scheduler) refreshMessagesWithData(dbMessages []struct {
 	WindowTarget  string
 }) {
 	s.messages = make([]types.MessageDisplayInfo, len(dbMessages))
-	var rows []table.Row
 
 	for i, msg := range dbMessages {
 		timeUntilSend := time.Until(msg.ScheduledTime)
@@ -601,34 +638,9 @@ func (s *Scheduler) refreshMessagesWithData(dbMessages []struct {
 			Error:         msg.Error,
 			TimeUntilSend: timeUntilSend,
 		}
-
-		// Truncate content for display
-		displayContent := msg.Content
-		if len(displayContent) > 27 {
-			displayContent = displayContent[:27] + "..."
-		}
-
-		timeUntilStr := "-"
-		if msg.Status == "pending" {
-			if timeUntilSend > 0 {
-				timeUntilStr = timeUntilSend.Round(time.Second).String()
-			} else {
-				timeUntilStr = "now"
-			}
-		}
-
-		rows = append(rows, table.Row{
-			strconv.Itoa(int(msg.ID)),
-			msg.WindowTarget,
-			displayContent,
-			strconv.Itoa(msg.Priority),
-			msg.Status,
-			msg.ScheduledTime.Format("01-02 15:04"),
-			timeUntilStr,
-		})
 	}
 
-	s.messagesTable.SetRows(rows)
+	s.messagesTable.SetMessages(s.messages)
 }
 
 // refreshQueueWithData refreshes the processing queue data (called from main thread)
@@ -661,111 +673,72 @@ func (s *Scheduler) refreshQueueWithData() {
 	s.queueTable.SetRows(rows)
 }
 
-// handleFormSubmit handles form submission
-func (s *Scheduler) handleFormSubmit() (*Scheduler, tea.Cmd) {
-	sessionName := s.sessionInput.Value()
-	content := s.contentInput.Value()
-	priorityStr := s.priorityInput.Value()
-	when := s.whenInput.Value()
+// composerWindows carries the windows the composer can send to
+type composerWindows []database.TmuxWindow
 
-	// Validate inputs
-	if sessionName == "" || content == "" {
-		return s, nil // TODO: Show error
-	}
-
-	priority := 5 // Default priority
-	if priorityStr != "" {
-		if p, err := strconv.Atoi(priorityStr); err == nil && p >= 1 && p <= 10 {
-			priority = p
+// loadComposerWindows loads the active windows for the composer's window picker
+func (s *Scheduler) loadComposerWindows() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if s.db == nil {
+			return types.ErrorMsg{Title: "Database Error", Message: "Database not available"}
 		}
-	}
-
-	if when == "" {
-		when = "now"
-	}
 
-	// Clear form and hide it
-	s.showForm = false
-	s.clearForm()
-
-	return s, s.scheduleMessage(sessionName, content, priority, when)
-}
-
-// clearForm clears all form inputs
-func (s *Scheduler) clearForm() {
-	s.sessionInput.SetValue("")
-	s.contentInput.SetValue("")
-	s.priorityInput.SetValue("")
-	s.whenInput.SetValue("")
-	s.sessionInput.Focus()
+		windows, err := database.GetActiveTmuxWindows(s.db)
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to load windows", Message: err.Error()}
+		}
+		return types.RefreshDataMsg{Type: "scheduler", Data: composerWindows(windows)}
+	})
 }
 
-// scheduleMessage schedules a new message
-func (s *Scheduler) scheduleMessage(sessionName, content string, priority int, when string) tea.Cmd {
+// scheduleMessage schedules a message written in the composer
+func (s *Scheduler) scheduleMessage(message components.ComposedMessage) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		if s.scheduler == nil {
 			return types.ErrorMsg{Title: "Error", Message: "Scheduler not available"}
 		}
 
-		// Parse schedule time
-		var scheduledTime time.Time
-		var err error
-
-		switch when {
-		case "now":
-			scheduledTime = time.Now()
-		default:
-			// Try to parse as duration (e.g., "+5m")
-			if when[0] == '+' {
-				duration, err := time.ParseDuration(when[1:])
-				if err != nil {
-					return types.ErrorMsg{Title: "Invalid time format", Message: err.Error()}
-				}
-				scheduledTime = roundScheduledTimeToMinute(time.Now().Add(duration))
-			} else {
-				// Try to parse as time (e.g., "14:30")
-				scheduledTime, err = time.Parse("15:04", when)
-				if err != nil {
-					return types.ErrorMsg{Title: "Invalid time format", Message: err.Error()}
-				}
-				// If time is in the past, schedule for tomorrow
-				if scheduledTime.Before(time.Now()) {
-					scheduledTime = scheduledTime.AddDate(0, 0, 1)
-				}
-			}
-		}
-
-		_, err = s.scheduler.ScheduleMessage(sessionName, content, scheduledTime, priority)
+		_, err := s.scheduler.ScheduleMessage(message.Target, message.Content, message.ScheduledTime, message.Priority)
 		if err != nil {
 			return types.ErrorMsg{Title: "Failed to schedule message", Message: err.Error()}
 		}
 
+		// The message is queued either way, so a failed trigger only delays it
+		if !message.ScheduledTime.After(time.Now()) {
+			if err := s.triggerProcessing(); err != nil {
+				log.Printf("Warning: failed to trigger processing: %v", err)
+			}
+		}
+
 		// Data will refresh via SuccessMsg handling
 
 		return types.SuccessMsg{
-			Title:   "Message Scheduled",
-			Message: fmt.Sprintf("Scheduled message for session '%s'", sessionName),
+			Title: "Message Scheduled",
+			Message: fmt.Sprintf("Scheduled message for %s at %s",
+				message.Target, message.ScheduledTime.Format("Jan 2 15:04")),
 		}
 	})
 }
 
-// deleteMessage deletes a message
-func (s *Scheduler) deleteMessage(id uint) tea.Cmd {
+// applyBulk runs a bulk action on messages in one transaction. The Scheduler
+// and Messages views share it.
+func applyBulk(msg components.MessageBulkMsg) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
-		if s.db == nil {
-			return types.ErrorMsg{Title: "Error", Message: "Database not available"}
-		}
-
-		err := s.db.Delete(&types.MessageDisplayInfo{}, id).Error
+		var affected int64
+		err := database.Transaction(func(tx *gorm.DB) error {
+			var err error
+			affected, err = database.ApplyBulkMessageAction(tx, msg.IDs, msg.Action)
+			return err
+		})
 		if err != nil {
-			return types.ErrorMsg{Title: "Failed to delete message", Message: err.Error()}
+			return types.ErrorMsg{Title: "Bulk " + msg.Action.Kind + " failed", Message: err.Error()}
 		}
 
 		// Data will refresh via SuccessMsg handling
 
 		return types.SuccessMsg{
-			Title:   "Message Deleted",
-			Message: fmt.Sprintf("Deleted message with ID %d", id),
+			Title:   "Messages Updated",
+			Message: fmt.Sprintf("%s: %d of %d messages changed", msg.Action.Kind, affected, len(msg.IDs)),
 		}
 	})
 }
@@ -773,11 +746,19 @@ func (s *Scheduler) deleteMessage(id uint) tea.Cmd {
 // sendMessageNow sends a message immediately
 func (s *Scheduler) sendMessageNow(id uint) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
+		if s.db == nil {
+			return types.ErrorMsg{Title: "Database Error", Message: "Database not available"}
+		}
+
 		// Update message to be sent now
-		if s.db != nil {
-			s.db.Model(&types.MessageDisplayInfo{}).
-				Where("id = ?", id).
-				Update("scheduled_time", time.Now())
+		err := s.db.Model(&database.Message{}).
+			Where("id = ? AND status = ?", id, database.MessageStatusPending).
+			Update("scheduled_time", time.Now()).Error
+		if err != nil {
+			return types.ErrorMsg{Title: "Failed to send now", Message: err.Error()}
+		}
+		if err := s.triggerProcessing(); err != nil {
+			return types.ErrorMsg{Title: "Failed to send now", Message: err.Error()}
 		}
 
 		// Data will refresh via SuccessMsg handling
@@ -789,6 +770,46 @@ func (s *Scheduler) sendMessageNow(id uint) tea.Cmd {
 	})
 }
 
+// PauseSending pauses all schedulers until resumed
+func (s *Scheduler) PauseSending() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := database.PauseScheduling(s.db, nil, "paused from the TUI"); err != nil {
+			return types.ErrorMsg{Title: "Failed to pause", Message: err.Error()}
+		}
+		return types.SuccessMsg{Title: "Sending Paused", Message: "No messages are sent until resumed (P)"}
+	})
+}
+
+// ResumeSending lifts a global pause
+func (s *Scheduler) ResumeSending() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := database.ResumeScheduling(s.db); err != nil {
+			return types.ErrorMsg{Title: "Failed to resume", Message: err.Error()}
+		}
+		if err := s.triggerProcessing(); err != nil {
+			return types.ErrorMsg{Title: "Failed to resume", Message: err.Error()}
+		}
+		return types.SuccessMsg{Title: "Sending Resumed", Message: "Scheduled messages are sent again"}
+	})
+}
+
+// SetRemote forwards processing to a running daemon instead of the local scheduler
+func (s *Scheduler) SetRemote(client *daemon.Client) {
+	s.remote = client
+}
+
+// triggerProcessing starts a processing round in the daemon when attached to
+// one, or in the local scheduler
+func (s *Scheduler) triggerProcessing() error {
+	if s.remote != nil {
+		return s.remote.Request(daemon.RequestProcess)
+	}
+	if s.scheduler != nil {
+		s.scheduler.TriggerImmediateProcessing()
+	}
+	return nil
+}
+
 // cancelMessage cancels a message
 func (s *Scheduler) cancelMessage(id uint) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
@@ -806,16 +827,3 @@ func (s *Scheduler) cancelMessage(id uint) tea.Cmd {
 		}
 	})
 }
-
-// roundScheduledTimeToMinute rounds a time down to the nearest minute (ignores seconds)
-func roundScheduledTimeToMinute(t time.Time) time.Time {
-	return t.Truncate(time.Minute)
-}
-
-// max returns the maximum of two integers
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
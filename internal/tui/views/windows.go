@@ -1,9 +1,12 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -12,9 +15,14 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/daemon"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/discovery"
 	"github.com/derekxwang/tcs/internal/tmux"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/theme"
 	"github.com/derekxwang/tcs/internal/types"
 	"github.com/derekxwang/tcs/internal/utils"
 )
@@ -26,11 +34,36 @@ type TmuxInterface interface {
 	CapturePane(target string, lines int) (string, error)
 }
 
+// WindowPreviewer is implemented by tmux clients that can stream a window's
+// content into the preview and switch to the window
+type WindowPreviewer interface {
+	MonitorWindowContext(ctx context.Context, target string, interval time.Duration, opts tmux.MonitorOptions) (<-chan string, error)
+	SwitchClient(target string) error
+}
+
+// previewInterval is how often the preview captures the highlighted window
+const previewInterval = 500 * time.Millisecond
+
+// rescanTimeout is how long a rescan forwarded to the daemon may take
+const rescanTimeout = 30 * time.Second
+
+// WindowPreviewMsg carries pane content for the preview. The app routes it to
+// the Windows view whichever view is showing, so the stream is never dropped.
+type WindowPreviewMsg struct {
+	seq     int                // preview stream the message belongs to
+	updates <-chan string      // set when the stream started
+	cancel  context.CancelFunc // set when the stream started
+	content string
+	err     error
+	closed  bool
+}
+
 // Windows represents the window management view
 type Windows struct {
 	db              *gorm.DB
 	windowDiscovery *discovery.WindowDiscovery
 	tmuxClient      TmuxInterface
+	remote          *daemon.Client // set when attached to a running daemon
 
 	// UI components
 	windowsTable table.Model
@@ -42,6 +75,23 @@ type Windows struct {
 	activeTable   string // "windows", "queue"
 	windows       []database.TmuxWindow
 	sessionQueues map[string][]WindowQueueInfo
+	selectTarget  string // window to select once it is loaded
+
+	// Share of the table rows for the windows table, changed by dragging the split
+	split      float64
+	dragging   bool
+	dragOrigin float64 // split when the drag started
+	dragStart  int     // line and windows table height when the drag started
+	dragHeight int
+
+	// Live preview of the highlighted window
+	showPreview    bool
+	preview        string // latest pane content, with ANSI colors
+	previewErr     string
+	previewTarget  string
+	previewSeq     int // bumped whenever the stream stops, to drop stale messages
+	previewUpdates <-chan string
+	previewCancel  context.CancelFunc
 
 	// Key bindings
 	keyMap WindowsKeyMap
@@ -50,8 +100,12 @@ type Windows struct {
 	titleStyle    lipgloss.Style
 	selectedStyle lipgloss.Style
 	inactiveStyle lipgloss.Style
+	previewStyle  lipgloss.Style
 }
 
+// minTableHeight keeps the header and a row of each table when resizing
+const minTableHeight = 3
+
 // WindowQueueInfo holds queue information grouped by session
 type WindowQueueInfo struct {
 	Window        database.TmuxWindow
@@ -61,12 +115,12 @@ type WindowQueueInfo struct {
 
 // WindowsKeyMap defines key bindings for the windows view
 type WindowsKeyMap struct {
-	ScanWindows    key.Binding
-	RefreshWindows key.Binding
-	SwitchTable    key.Binding
-	ToggleActive   key.Binding
-	ChangePriority key.Binding
-	ForceRescan    key.Binding
+	ScanWindows   key.Binding
+	SwitchTable   key.Binding
+	ToggleActive  key.Binding
+	ForceRescan   key.Binding
+	TogglePreview key.Binding
+	JumpToWindow  key.Binding
 }
 
 // DefaultWindowsKeyMap returns the default windows key bindings
@@ -76,10 +130,6 @@ func DefaultWindowsKeyMap() WindowsKeyMap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "scan windows"),
 		),
-		RefreshWindows: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "refresh"),
-		),
 		SwitchTable: key.NewBinding(
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "switch table"),
@@ -88,14 +138,30 @@ func DefaultWindowsKeyMap() WindowsKeyMap {
 			key.WithKeys("a"),
 			key.WithHelp("a", "toggle active"),
 		),
-		ChangePriority: key.NewBinding(
-			key.WithKeys("p"),
-			key.WithHelp("p", "change priority"),
-		),
 		ForceRescan: key.NewBinding(
 			key.WithKeys("F"),
 			key.WithHelp("F", "force rescan"),
 		),
+		TogglePreview: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "toggle preview"),
+		),
+		JumpToWindow: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "jump to window"),
+		),
+	}
+}
+
+// Actions returns the bindings by their names in the tui.keys config
+func (k *WindowsKeyMap) Actions() []keys.Action {
+	return []keys.Action{
+		{Name: "scan", Binding: &k.ScanWindows},
+		{Name: "switch_table", Binding: &k.SwitchTable},
+		{Name: "toggle_active", Binding: &k.ToggleActive},
+		{Name: "force_rescan", Binding: &k.ForceRescan},
+		{Name: "toggle_preview", Binding: &k.TogglePreview},
+		{Name: "jump", Binding: &k.JumpToWindow},
 	}
 }
 
@@ -108,6 +174,9 @@ func NewWindows(db *gorm.DB, windowDiscovery *discovery.WindowDiscovery, tmuxCli
 		{Title: "Name", Width: 15},
 		{Title: "Target", Width: 15},
 		{Title: "Claude", Width: 8},
+		{Title: "Model", Width: 12},
+		{Title: "Mode", Width: 11},
+		{Title: "Version", Width: 8},
 		{Title: "Priority", Width: 8},
 		{Title: "Active", Width: 8},
 		{Title: "Last Seen", Width: 16},
@@ -143,27 +212,51 @@ func NewWindows(db *gorm.DB, windowDiscovery *discovery.WindowDiscovery, tmuxCli
 		queueTable:      queueTable,
 		activeTable:     "windows",
 		sessionQueues:   make(map[string][]WindowQueueInfo),
+		showPreview:     true,
+		split:           config.GetTUIConfig().Layout.WindowsSplit,
 		keyMap:          DefaultWindowsKeyMap(),
 	}
+	if w.split <= 0 || w.split >= 1 {
+		w.split = config.DefaultWindowsSplit
+	}
 
-	w.initStyles()
+	w.initStyles(theme.Current())
 	return w
 }
 
+// SetTheme restyles the view with the named theme
+func (w *Windows) SetTheme(name string) {
+	t, _ := theme.Get(name)
+	w.initStyles(t)
+}
+
+// KeySections returns the view's key bindings for remapping and the help view
+func (w *Windows) KeySections() []keys.Section {
+	return []keys.Section{{Name: "windows", Title: "Windows", Actions: w.keyMap.Actions()}}
+}
+
 // initStyles initializes the windows view styles
-func (w *Windows) initStyles() {
+func (w *Windows) initStyles(t theme.Theme) {
 	w.titleStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("15")).
+		Foreground(t.Text).
 		MarginBottom(1)
 
 	w.selectedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("57")).
+		Foreground(t.SelectedText).
+		Background(t.Selected).
 		Bold(true)
 
 	w.inactiveStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8"))
+		Foreground(t.Muted)
+
+	w.previewStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Muted).
+		Padding(0, 1)
+
+	w.windowsTable.SetStyles(t.TableStyles())
+	w.queueTable.SetStyles(t.TableStyles())
 }
 
 // Init initializes the windows view
@@ -184,6 +277,9 @@ func (w *Windows) Update(msg tea.Msg) (*Windows, tea.Cmd) {
 	case tea.KeyMsg:
 		return w.handleKeys(msg)
 
+	case tea.MouseMsg:
+		return w.handleMouse(msg)
+
 	case types.RefreshDataMsg:
 		if msg.Type == "all" || msg.Type == "windows" {
 			if msg.Data != nil {
@@ -191,6 +287,8 @@ func (w *Windows) Update(msg tea.Msg) (*Windows, tea.Cmd) {
 				if windows, ok := msg.Data.([]database.TmuxWindow); ok {
 					w.refreshWindowsWithData(windows)
 					w.refreshQueuesWithData(windows)
+					w.applySelection()
+					cmds = append(cmds, w.syncPreview())
 				}
 			} else {
 				// Trigger new data fetch
@@ -198,6 +296,9 @@ func (w *Windows) Update(msg tea.Msg) (*Windows, tea.Cmd) {
 			}
 		}
 
+	case WindowPreviewMsg:
+		return w, w.handlePreview(msg)
+
 	case types.SuccessMsg:
 		// After successful operations, trigger a refresh
 		if msg.Title == "Force Rescan Complete" || msg.Title == "Window Updated" {
@@ -224,22 +325,18 @@ func (w *Windows) IsFormActive() bool {
 func (w *Windows) handleKeys(msg tea.KeyMsg) (*Windows, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	// Moving on before a pending selection arrives cancels it
+	w.selectTarget = ""
+
 	switch {
 	case key.Matches(msg, w.keyMap.ScanWindows):
 		cmds = append(cmds, w.scanWindows())
 
-	case key.Matches(msg, w.keyMap.RefreshWindows):
-		cmds = append(cmds, w.refreshData())
-
 	case key.Matches(msg, w.keyMap.SwitchTable):
 		if w.activeTable == "windows" {
-			w.activeTable = "queue"
-			w.windowsTable.Blur()
-			w.queueTable.Focus()
+			w.focusTable("queue")
 		} else {
-			w.activeTable = "windows"
-			w.queueTable.Blur()
-			w.windowsTable.Focus()
+			w.focusTable("windows")
 		}
 
 	case key.Matches(msg, w.keyMap.ToggleActive):
@@ -252,11 +349,134 @@ func (w *Windows) handleKeys(msg tea.KeyMsg) (*Windows, tea.Cmd) {
 
 	case key.Matches(msg, w.keyMap.ForceRescan):
 		cmds = append(cmds, w.forceRescan())
+
+	case key.Matches(msg, w.keyMap.TogglePreview):
+		w.showPreview = !w.showPreview
+		w.updateTableSizes()
+
+	case key.Matches(msg, w.keyMap.JumpToWindow):
+		if window := w.SelectedWindow(); window != nil {
+			cmds = append(cmds, w.jumpToWindow(window.Target))
+		}
+
+	default:
+		// Navigation within the active table
+		if w.activeTable == "windows" {
+			w.windowsTable, _ = w.windowsTable.Update(msg)
+		} else {
+			w.queueTable, _ = w.queueTable.Update(msg)
+		}
 	}
 
+	// Follow the highlighted window in the preview
+	cmds = append(cmds, w.syncPreview())
+
 	return w, tea.Batch(cmds...)
 }
 
+// handleMouse selects clicked rows, scrolls the table under the wheel and
+// resizes the tables while the split between them is dragged
+func (w *Windows) handleMouse(msg tea.MouseMsg) (*Windows, tea.Cmd) {
+	windowsTop, splitTop, queueTop := w.layout()
+
+	if w.dragging {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			w.resizeSplit(w.dragHeight + msg.Y - w.dragStart)
+		case tea.MouseActionRelease:
+			w.dragging = false
+			if w.split != w.dragOrigin {
+				return w, w.saveLayout()
+			}
+		}
+		return w, nil
+	}
+
+	// The preview doesn't take clicks
+	if msg.X >= w.width-w.previewWidth() {
+		return w, nil
+	}
+
+	switch {
+	case msg.Y >= splitTop && msg.Y < queueTop:
+		// The gap and title above the queue table are the handle of the split
+		if components.IsLeftClick(msg) {
+			w.focusTable("queue")
+			w.dragging = true
+			w.dragOrigin = w.split
+			w.dragStart = msg.Y
+			w.dragHeight = lipgloss.Height(w.windowsTable.View())
+		}
+
+	case msg.Y >= queueTop:
+		if !components.ScrollTable(&w.queueTable, msg) && components.IsLeftClick(msg) {
+			w.focusTable("queue")
+			if row, ok := components.TableRowAt(w.queueTable, msg.Y-queueTop); ok {
+				w.queueTable.SetCursor(row)
+			}
+		}
+
+	default:
+		if !components.ScrollTable(&w.windowsTable, msg) && components.IsLeftClick(msg) {
+			w.focusTable("windows")
+			if row, ok := components.TableRowAt(w.windowsTable, msg.Y-windowsTop); ok {
+				w.windowsTable.SetCursor(row)
+			}
+		}
+	}
+
+	// Moving on before a pending selection arrives cancels it
+	w.selectTarget = ""
+	return w, w.syncPreview()
+}
+
+// focusTable makes "windows" or "queue" the table that takes keys
+func (w *Windows) focusTable(name string) {
+	w.activeTable = name
+	if name == "windows" {
+		w.queueTable.Blur()
+		w.windowsTable.Focus()
+	} else {
+		w.windowsTable.Blur()
+		w.queueTable.Focus()
+	}
+}
+
+// layout returns the lines where the windows table, the handle of the split
+// and the queue table start
+func (w *Windows) layout() (windowsTop, splitTop, queueTop int) {
+	windowsSection := lipgloss.Height(w.renderWindowsTable())
+	windowsTop = windowsSection - lipgloss.Height(w.windowsTable.View())
+	splitTop = windowsSection
+	queueTop = splitTop + lipgloss.Height(w.renderQueueTable()) - lipgloss.Height(w.queueTable.View())
+	return windowsTop, splitTop, queueTop
+}
+
+// resizeSplit gives the windows table the given height, and the queue table
+// the rest of the rows
+func (w *Windows) resizeSplit(windowsHeight int) {
+	total := w.tableRows()
+	if total < 2*minTableHeight {
+		return
+	}
+	windowsHeight = max(minTableHeight, min(windowsHeight, total-minTableHeight))
+	w.split = float64(windowsHeight) / float64(total)
+	w.updateTableSizes()
+}
+
+// saveLayout saves the split to the TUI config
+func (w *Windows) saveLayout() tea.Cmd {
+	split := math.Round(w.split*100) / 100
+	return tea.Cmd(func() tea.Msg {
+		layout := config.GetTUIConfig().Layout
+		layout.WindowsSplit = split
+		if err := config.SaveTUILayout(layout); err != nil {
+			return types.ErrorMsg{Title: "Failed to save layout", Message: err.Error()}
+		}
+		return nil
+	})
+}
+
 // View renders the windows view
 func (w *Windows) View() string {
 	if w.width == 0 {
@@ -275,7 +495,11 @@ func (w *Windows) View() string {
 	content = append(content, w.renderStats())
 	content = append(content, w.renderHelp())
 
-	return lipgloss.JoinVertical(lipgloss.Left, content...)
+	tables := lipgloss.JoinVertical(lipgloss.Left, content...)
+	if previewWidth := w.previewWidth(); previewWidth > 0 {
+		return lipgloss.JoinHorizontal(lipgloss.Top, tables, w.renderPreview(previewWidth))
+	}
+	return tables
 }
 
 // renderWindowsTable renders the windows table
@@ -338,7 +562,9 @@ func (w *Windows) renderStats() string {
 
 // renderHelp renders the help text
 func (w *Windows) renderHelp() string {
-	help := "\ns: Scan (discovery service)  F: Force Rescan (manual)  a: Toggle Active  tab: Switch Table  r: Refresh"
+	k := w.keyMap
+	help := "\n" + keys.Hint(k.ScanWindows, k.ForceRescan, k.ToggleActive, k.SwitchTable) + "\n" +
+		keys.Hint(k.TogglePreview, k.JumpToWindow)
 	return w.inactiveStyle.Render(help)
 }
 
@@ -349,22 +575,32 @@ func (w *Windows) SetSize(width, height int) {
 	w.updateTableSizes()
 }
 
-// Refresh refreshes the windows data
+// Refresh refreshes the windows data and restarts the preview if it was stopped
 func (w *Windows) Refresh() tea.Cmd {
-	return w.refreshData()
+	return tea.Batch(w.refreshData(), w.syncPreview())
 }
 
 // updateTableSizes updates table sizes based on current dimensions
 func (w *Windows) updateTableSizes() {
-	tableWidth := w.width - 4
+	tableWidth := w.width - 4 - w.previewWidth()
+
+	// The tables share the rows by the split
+	total := w.tableRows()
+	windowsHeight := int(math.Round(float64(total) * w.split))
+	windowsHeight = max(minTableHeight, min(windowsHeight, total-minTableHeight))
 
 	// Update windows table
 	w.windowsTable.SetWidth(tableWidth)
-	w.windowsTable.SetHeight(min(12, w.height/3))
+	w.windowsTable.SetHeight(windowsHeight)
 
 	// Update queue table
 	w.queueTable.SetWidth(tableWidth)
-	w.queueTable.SetHeight(min(10, w.height/3))
+	w.queueTable.SetHeight(max(minTableHeight, total-windowsHeight))
+}
+
+// tableRows returns the rows shared by the windows and queue tables
+func (w *Windows) tableRows() int {
+	return min(22, 2*(w.height/3))
 }
 
 // refreshData refreshes all windows data
@@ -415,6 +651,9 @@ func (w *Windows) refreshWindowsWithData(windows []database.TmuxWindow) {
 			window.WindowName,
 			window.Target,
 			claudeStatus,
+			window.ClaudeModel,
+			window.ClaudePermissionMode,
+			window.ClaudeVersion,
 			strconv.Itoa(window.Priority),
 			activeStatus,
 			lastSeen,
@@ -440,8 +679,10 @@ func (w *Windows) refreshQueuesWithData(windows []database.TmuxWindow) {
 		// Get queue priority
 		queue, err := database.GetOrCreateWindowMessageQueue(w.db, window.ID)
 		queuePriority := 5 // default
+		queueState := "ready"
 		if err == nil {
 			queuePriority = queue.Priority
+			queueState = queue.State()
 		}
 
 		queueInfo := WindowQueueInfo{
@@ -459,8 +700,15 @@ func (w *Windows) refreshQueuesWithData(windows []database.TmuxWindow) {
 		}
 
 		status := "Active"
-		if !window.Active {
+		switch {
+		case !window.Active:
 			status = "Inactive"
+		case queueState == "paused":
+			status = "Paused"
+		case queueState == "sending":
+			status = "Sending"
+		case queueState == "spacing":
+			status = "Spacing"
 		}
 
 		rows = append(rows, table.Row{
@@ -478,6 +726,9 @@ func (w *Windows) refreshQueuesWithData(windows []database.TmuxWindow) {
 
 // scanWindows triggers a window scan with auto-refresh
 func (w *Windows) scanWindows() tea.Cmd {
+	if w.remote != nil {
+		return w.requestRescan()
+	}
 	return tea.Cmd(func() tea.Msg {
 		if w.windowDiscovery == nil {
 			return types.ErrorMsg{
@@ -518,6 +769,9 @@ func (w *Windows) ForceRescan() tea.Cmd {
 
 // forceRescan forces a complete rescan
 func (w *Windows) forceRescan() tea.Cmd {
+	if w.remote != nil {
+		return w.requestRescan()
+	}
 	return tea.Cmd(func() (result tea.Msg) {
 		// Double-layer panic recovery - protect the entire command
 		defer func() {
@@ -535,6 +789,26 @@ func (w *Windows) forceRescan() tea.Cmd {
 	})
 }
 
+// SetRemote forwards scans to a running daemon instead of scanning tmux here
+func (w *Windows) SetRemote(client *daemon.Client) {
+	w.remote = client
+}
+
+// requestRescan asks the daemon to scan the windows and refreshes once the scan ran
+func (w *Windows) requestRescan() tea.Cmd {
+	remote := w.remote
+	return tea.Cmd(func() tea.Msg {
+		if err := remote.RequestAndWait(daemon.RequestRescan, rescanTimeout); err != nil {
+			return types.ErrorMsg{Title: "Scan Failed", Message: err.Error()}
+		}
+
+		return types.SuccessMsg{
+			Title:   "Force Rescan Complete",
+			Message: fmt.Sprintf("The daemon (%s) rescanned the tmux windows", remote.Owner()),
+		}
+	})
+}
+
 // PerformForceRescan does the actual force rescan work with proper panic recovery
 // Made public for testing purposes
 func (w *Windows) PerformForceRescan() (result tea.Msg) {
@@ -572,7 +846,7 @@ func (w *Windows) PerformForceRescan() (result tea.Msg) {
 			hasClaude := false
 			content, err := w.tmuxClient.CapturePane(window.Target, 50)
 			if err == nil {
-				hasClaude = utils.IsClaudeWindow(content)
+				hasClaude = utils.ConfiguredRuleSet().Evaluate(content, "").IsClaude
 			}
 
 			// Create or update window
@@ -631,3 +905,197 @@ func (w *Windows) toggleWindowActive(window database.TmuxWindow) tea.Cmd {
 		}
 	})
 }
+
+// SelectedWindow returns the window highlighted in the active table. Both
+// tables list the windows in the same order.
+func (w *Windows) SelectedWindow() *database.TmuxWindow {
+	cursor := w.windowsTable.Cursor()
+	if w.activeTable == "queue" {
+		cursor = w.queueTable.Cursor()
+	}
+	if cursor < 0 || cursor >= len(w.windows) {
+		return nil
+	}
+	return &w.windows[cursor]
+}
+
+// SelectWindow highlights the window with the given target in the windows
+// table, once the window has been loaded
+func (w *Windows) SelectWindow(target string) tea.Cmd {
+	w.selectTarget = target
+	w.focusTable("windows")
+	w.applySelection()
+	return tea.Batch(w.refreshData(), w.syncPreview())
+}
+
+// applySelection moves the cursors to the window waiting to be selected, if it is loaded
+func (w *Windows) applySelection() {
+	if w.selectTarget == "" {
+		return
+	}
+	for i, window := range w.windows {
+		if window.Target == w.selectTarget {
+			w.windowsTable.SetCursor(i)
+			w.queueTable.SetCursor(i)
+			w.selectTarget = ""
+			return
+		}
+	}
+}
+
+// previewWidth returns the width of the preview pane, or 0 when it is hidden
+// or the terminal is too narrow to split
+func (w *Windows) previewWidth() int {
+	if !w.showPreview || w.width < 100 {
+		return 0
+	}
+	return w.width * 2 / 5
+}
+
+// syncPreview streams the highlighted window into the preview, restarting the
+// stream when the selection changes
+func (w *Windows) syncPreview() tea.Cmd {
+	target := ""
+	if window := w.SelectedWindow(); window != nil && w.previewWidth() > 0 {
+		target = window.Target
+	}
+	if target == w.previewTarget {
+		return nil
+	}
+
+	w.StopPreview()
+	w.previewTarget = target
+	if target == "" {
+		return nil
+	}
+
+	previewer, ok := w.tmuxClient.(WindowPreviewer)
+	if !ok {
+		w.previewErr = "Preview not available"
+		return nil
+	}
+
+	seq := w.previewSeq
+	return tea.Cmd(func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		updates, err := previewer.MonitorWindowContext(ctx, target, previewInterval, tmux.MonitorOptions{ANSI: true})
+		if err != nil {
+			cancel()
+			return WindowPreviewMsg{seq: seq, err: err}
+		}
+		return WindowPreviewMsg{seq: seq, updates: updates, cancel: cancel}
+	})
+}
+
+// StopPreview stops streaming the preview
+func (w *Windows) StopPreview() {
+	if w.previewCancel != nil {
+		w.previewCancel()
+		w.previewCancel = nil
+	}
+	w.previewSeq++
+	w.previewUpdates = nil
+	w.previewTarget = ""
+	w.preview = ""
+	w.previewErr = ""
+}
+
+// handlePreview applies a preview message and waits for the next update
+func (w *Windows) handlePreview(msg WindowPreviewMsg) tea.Cmd {
+	if msg.seq != w.previewSeq {
+		// The selection moved on while the stream was starting
+		if msg.cancel != nil {
+			msg.cancel()
+		}
+		return nil
+	}
+
+	switch {
+	case msg.err != nil:
+		w.previewErr = msg.err.Error()
+		return nil
+	case msg.updates != nil:
+		w.previewUpdates = msg.updates
+		w.previewCancel = msg.cancel
+	case msg.closed:
+		w.previewUpdates = nil
+		return nil
+	default:
+		w.preview = msg.content
+		w.previewErr = ""
+	}
+	return waitForPreview(msg.seq, w.previewUpdates)
+}
+
+// waitForPreview waits for the next content of a preview stream
+func waitForPreview(seq int, updates <-chan string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		content, ok := <-updates
+		return WindowPreviewMsg{seq: seq, content: content, closed: !ok}
+	})
+}
+
+// renderPreview renders the bottom of the highlighted window's pane
+func (w *Windows) renderPreview(width int) string {
+	title := "Preview"
+	if w.previewTarget != "" {
+		title += " · " + w.previewTarget
+	}
+
+	innerWidth := width - 4
+	innerHeight := max(3, w.height-5)
+
+	var body string
+	switch {
+	case w.previewErr != "":
+		body = w.inactiveStyle.Render(w.previewErr)
+	case w.previewTarget == "":
+		body = w.inactiveStyle.Render("No window selected")
+	case w.preview == "":
+		body = w.inactiveStyle.Render("Waiting for content...")
+	default:
+		// Show the last lines that fit, cut to width with the colors reset on
+		// every line so they do not bleed into the border
+		lines := strings.Split(strings.TrimRight(w.preview, " \n"), "\n")
+		if len(lines) > innerHeight {
+			lines = lines[len(lines)-innerHeight:]
+		}
+		lineStyle := lipgloss.NewStyle().MaxWidth(innerWidth)
+		for i, line := range lines {
+			lines[i] = lineStyle.Render(line) + "\x1b[0m"
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	box := w.previewStyle.
+		Width(width - 2).
+		Height(innerHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		w.titleStyle.Render(w.inactiveStyle.Render("  "+title)),
+		box.Render(body))
+}
+
+// jumpToWindow shows a window in tmux: the client running the TUI switches to
+// it, or outside tmux the terminal attaches to it until you detach
+func (w *Windows) jumpToWindow(target string) tea.Cmd {
+	if !tmux.InsideTmux() {
+		return tea.ExecProcess(tmux.AttachCommand(target), func(err error) tea.Msg {
+			if err != nil {
+				return types.ErrorMsg{Title: "Jump Failed", Message: fmt.Sprintf("Could not attach to %s: %v", target, err)}
+			}
+			return types.RefreshDataMsg{Type: "windows"}
+		})
+	}
+
+	previewer, ok := w.tmuxClient.(WindowPreviewer)
+	if !ok {
+		return nil
+	}
+	return tea.Cmd(func() tea.Msg {
+		if err := previewer.SwitchClient(target); err != nil {
+			return types.ErrorMsg{Title: "Jump Failed", Message: err.Error()}
+		}
+		return types.SuccessMsg{Title: "Switched Window", Message: fmt.Sprintf("Switched to %s", target)}
+	})
+}
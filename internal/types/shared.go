@@ -84,8 +84,9 @@ type TmuxWindowInfo struct {
 
 // SchedulerStats represents scheduler statistics for display
 type SchedulerStats struct {
-	SmartSchedulerEnabled bool          `json:"smart_scheduler_enabled"`
+	Policy                string        `json:"policy"`
 	CronSchedulerEnabled  bool          `json:"cron_scheduler_enabled"`
+	Hold                  string        `json:"hold,omitempty"` // pause or quiet hours in effect
 	PendingMessages       int           `json:"pending_messages"`
 	ProcessingMessages    int           `json:"processing_messages"`
 	SentMessages          int           `json:"sent_messages"`
@@ -156,8 +157,10 @@ type StatusMsg struct {
 // Constants for message statuses
 const (
 	MessageStatusPending = "pending"
+	MessageStatusSending = "sending"
 	MessageStatusSent    = "sent"
 	MessageStatusFailed  = "failed"
+	MessageStatusExpired = "expired"
 
 	// Session statuses
 	SessionStatusActive   = "active"
@@ -46,6 +46,9 @@ func init() {
 
 // IsClaudeWindow checks if window content indicates a Claude session
 // Optimized version with pre-computed patterns and early exit strategies
+//
+// Deprecated: substring matching gives false positives in shells that merely mention
+// Claude. Use ConfiguredRuleSet().Evaluate instead.
 func IsClaudeWindow(content string) bool {
 	if content == "" {
 		return false
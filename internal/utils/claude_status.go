@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Claude Code permission modes, using the names accepted by --permission-mode
+const (
+	PermissionModeDefault     = "default"
+	PermissionModeAcceptEdits = "acceptEdits"
+	PermissionModePlan        = "plan"
+	PermissionModeBypass      = "bypassPermissions"
+)
+
+// ClaudeStatus describes how a Claude Code session is running
+type ClaudeStatus struct {
+	Model          string `json:"model"`           // e.g. "opus", "claude-sonnet-4-5" or "Opus 4.1"
+	PermissionMode string `json:"permission_mode"` // default, acceptEdits, plan, bypassPermissions
+	Version        string `json:"version"`         // Claude Code version, e.g. "1.0.88"
+	Busy           bool   `json:"busy"`            // Claude is working on a response
+}
+
+var (
+	// Model shown in the welcome banner, e.g. "Opus 4.1 · Claude Max"
+	screenModelPattern = regexp.MustCompile(`(?i)\b(opus|sonnet|haiku)\s+(\d+(?:\.\d+)?)\b`)
+	// Full model IDs, e.g. shown by /model or /status
+	modelIDPattern = regexp.MustCompile(`\bclaude-(?:opus|sonnet|haiku)-[\w.-]+|\bclaude-\d[\w.-]*-(?:opus|sonnet|haiku)[\w.-]*`)
+	// Version in the banner, e.g. "Claude Code v2.0.14"
+	screenVersionPattern = regexp.MustCompile(`(?i)claude code v?(\d+\.\d+\.\d+)`)
+	// Version in an install path, e.g. ".../claude/versions/1.0.88" or "claude-code@1.0.88"
+	argVersionPattern = regexp.MustCompile(`(?:/versions/|claude-code@)(\d+\.\d+\.\d+)`)
+
+	busyPattern = regexp.MustCompile(`(?i)esc to interrupt`)
+
+	// Placeholder shown in an empty prompt, e.g. `Try "fix lint errors"`
+	inputPlaceholderPattern = regexp.MustCompile(`^Try "[^"]*"$`)
+)
+
+// Status line indicators, checked in order
+var permissionModeIndicators = []struct {
+	pattern *regexp.Regexp
+	mode    string
+}{
+	{regexp.MustCompile(`(?i)bypass(ing)? permissions on`), PermissionModeBypass},
+	{regexp.MustCompile(`(?i)plan mode on`), PermissionModePlan},
+	{regexp.MustCompile(`(?i)accept edits on`), PermissionModeAcceptEdits},
+	{regexp.MustCompile(`\? for shortcuts`), PermissionModeDefault},
+}
+
+// ParseClaudeArgs extracts the model, permission mode and version from Claude's argv
+func ParseClaudeArgs(args []string) ClaudeStatus {
+	var status ClaudeStatus
+
+	for i, arg := range args {
+		if status.Version == "" {
+			if match := argVersionPattern.FindStringSubmatch(arg); match != nil {
+				status.Version = match[1]
+			}
+		}
+
+		value := ""
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+
+		switch {
+		case arg == "--model":
+			status.Model = value
+		case strings.HasPrefix(arg, "--model="):
+			status.Model = strings.TrimPrefix(arg, "--model=")
+		case arg == "--permission-mode":
+			status.PermissionMode = value
+		case strings.HasPrefix(arg, "--permission-mode="):
+			status.PermissionMode = strings.TrimPrefix(arg, "--permission-mode=")
+		case arg == "--dangerously-skip-permissions":
+			status.PermissionMode = PermissionModeBypass
+		}
+	}
+
+	return status
+}
+
+// ParseClaudeScreen extracts the model, permission mode, version and busy state
+// from captured pane content. Fields that are not visible are left empty.
+func ParseClaudeScreen(content string) ClaudeStatus {
+	var status ClaudeStatus
+
+	// The most recent mention wins, since /model can switch models mid-session
+	if matches := modelIDPattern.FindAllString(content, -1); len(matches) > 0 {
+		status.Model = matches[len(matches)-1]
+	} else if matches := screenModelPattern.FindAllString(content, -1); len(matches) > 0 {
+		status.Model = matches[len(matches)-1]
+	}
+
+	if match := screenVersionPattern.FindStringSubmatch(content); match != nil {
+		status.Version = match[1]
+	}
+
+	// The status line is at the bottom of the pane
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > 5 {
+		lines = lines[len(lines)-5:]
+	}
+	statusArea := strings.Join(lines, "\n")
+
+	for _, indicator := range permissionModeIndicators {
+		if indicator.pattern.MatchString(statusArea) {
+			status.PermissionMode = indicator.mode
+			break
+		}
+	}
+
+	status.Busy = busyPattern.MatchString(statusArea)
+	return status
+}
+
+// ParseClaudeInput returns the text typed into Claude's prompt but not yet
+// submitted, or "" when the prompt is empty or not visible. Lines of a
+// multi-line input are joined with newlines.
+func ParseClaudeInput(content string) string {
+	// The input box is at the bottom of the pane, above the status line
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > 12 {
+		lines = lines[len(lines)-12:]
+	}
+
+	prompt := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if text := inputBoxText(lines[i]); strings.HasPrefix(text, ">") || strings.HasPrefix(text, "❯") {
+			prompt = i
+			break
+		}
+	}
+	if prompt < 0 {
+		return ""
+	}
+
+	first := strings.TrimSpace(strings.TrimLeft(inputBoxText(lines[prompt]), ">❯"))
+	if inputPlaceholderPattern.MatchString(first) {
+		return ""
+	}
+	input := []string{first}
+	for _, line := range lines[prompt+1:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "╰") || strings.HasPrefix(trimmed, "─") {
+			break
+		}
+		input = append(input, inputBoxText(line))
+	}
+	return strings.TrimSpace(strings.Join(input, "\n"))
+}
+
+// inputBoxText strips the borders of the input box from a line
+func inputBoxText(line string) string {
+	text := strings.TrimSpace(line)
+	text = strings.TrimPrefix(text, "│")
+	text = strings.TrimSuffix(text, "│")
+	return strings.TrimSpace(text)
+}
+
+// MergeClaudeStatus combines the status from argv with what is visible on screen.
+// The screen wins since the model and mode can be changed after startup.
+func MergeClaudeStatus(fromArgs, fromScreen ClaudeStatus) ClaudeStatus {
+	merged := fromArgs
+	if fromScreen.Model != "" {
+		merged.Model = fromScreen.Model
+	}
+	if fromScreen.PermissionMode != "" {
+		merged.PermissionMode = fromScreen.PermissionMode
+	}
+	if fromScreen.Version != "" {
+		merged.Version = fromScreen.Version
+	}
+	merged.Busy = fromScreen.Busy
+	return merged
+}
+
+// ModelFamily returns "opus", "sonnet" or "haiku" for a model name, or "" if unknown
+func ModelFamily(model string) string {
+	lower := strings.ToLower(model)
+	for _, family := range []string{"opus", "sonnet", "haiku"} {
+		if strings.Contains(lower, family) {
+			return family
+		}
+	}
+	return ""
+}
+
+// ModelMatches reports whether a model satisfies a requested model, either by
+// family ("opus") or by a case-insensitive substring of the full name
+func ModelMatches(model, want string) bool {
+	if model == "" || want == "" {
+		return false
+	}
+	want = strings.ToLower(want)
+	if family := ModelFamily(model); family != "" && family == want {
+		return true
+	}
+	return strings.Contains(strings.ToLower(model), want)
+}
+
+// NormalizePermissionMode maps a permission mode or a common alias
+// ("auto-accept", "bypass") to its canonical name, or "" if unknown
+func NormalizePermissionMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "default", "normal":
+		return PermissionModeDefault
+	case "acceptedits", "accept-edits", "accept", "auto", "auto-accept":
+		return PermissionModeAcceptEdits
+	case "plan":
+		return PermissionModePlan
+	case "bypasspermissions", "bypass-permissions", "bypass":
+		return PermissionModeBypass
+	}
+	return ""
+}
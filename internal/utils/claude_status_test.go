@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseClaudeArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected ClaudeStatus
+	}{
+		{
+			name:     "model and permission mode",
+			args:     []string{"claude", "--model", "opus", "--permission-mode", "plan"},
+			expected: ClaudeStatus{Model: "opus", PermissionMode: PermissionModePlan},
+		},
+		{
+			name:     "equals form and version from install path",
+			args:     []string{"/home/me/.local/share/claude/versions/1.0.88", "--model=claude-sonnet-4-5"},
+			expected: ClaudeStatus{Model: "claude-sonnet-4-5", Version: "1.0.88"},
+		},
+		{
+			name:     "skip permissions",
+			args:     []string{"node", "/usr/lib/node_modules/@anthropic-ai/claude-code/cli.js", "--dangerously-skip-permissions", "--resume"},
+			expected: ClaudeStatus{PermissionMode: PermissionModeBypass},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseClaudeArgs(tt.args); got != tt.expected {
+				t.Errorf("ParseClaudeArgs(%q) = %+v, expected %+v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseClaudeScreen(t *testing.T) {
+	content := "╭───────────────────────────╮\n" +
+		"│ ✻ Welcome to Claude Code! │\n" +
+		"╰───────────────────────────╯\n" +
+		" Claude Code v2.0.14\n" +
+		" Sonnet 4.5 · Claude Max\n" +
+		"\n" +
+		"> /model\n" +
+		"  ⎿  Set model to opus (claude-opus-4-1-20250805)\n" +
+		"\n" +
+		"✶ Thinking… (esc to interrupt)\n" +
+		"╭───────────────────────────╮\n" +
+		"│ >                         │\n" +
+		"╰───────────────────────────╯\n" +
+		"  ⏸ plan mode on (shift+tab to cycle)\n"
+
+	status := ParseClaudeScreen(content)
+	expected := ClaudeStatus{
+		Model:          "claude-opus-4-1-20250805",
+		PermissionMode: PermissionModePlan,
+		Version:        "2.0.14",
+		Busy:           true,
+	}
+	if status != expected {
+		t.Errorf("ParseClaudeScreen() = %+v, expected %+v", status, expected)
+	}
+
+	// The screen overrides argv, except for fields that are not visible
+	merged := MergeClaudeStatus(ClaudeStatus{Model: "sonnet", PermissionMode: PermissionModeAcceptEdits},
+		ClaudeStatus{PermissionMode: PermissionModeDefault})
+	if merged.Model != "sonnet" || merged.PermissionMode != PermissionModeDefault {
+		t.Errorf("MergeClaudeStatus() = %+v", merged)
+	}
+}
+
+func TestParseClaudeInput(t *testing.T) {
+	box := func(lines ...string) string {
+		content := "> /model\n  ⎿  Set model to opus\n\n╭───────────────────────────╮\n"
+		for _, line := range lines {
+			content += "│ " + line + " │\n"
+		}
+		return content + "╰───────────────────────────╯\n  ? for shortcuts\n"
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"empty prompt", box(">                         "), ""},
+		{"placeholder", box(`> Try "fix lint errors"    `), ""},
+		{"typed text", box("> refactor the parser     "), "refactor the parser"},
+		{"multi-line input", box("> first line              ", "  second line             "), "first line\nsecond line"},
+		{"borderless prompt", "──────────\n> draft\n──────────\n  ? for shortcuts\n", "draft"},
+		{"no prompt", "$ ls\nREADME.md\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseClaudeInput(tt.content); got != tt.expected {
+				t.Errorf("ParseClaudeInput() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelMatches(t *testing.T) {
+	tests := []struct {
+		model    string
+		want     string
+		expected bool
+	}{
+		{"Opus 4.1", "opus", true},
+		{"claude-opus-4-1-20250805", "opus", true},
+		{"claude-sonnet-4-5", "opus", false},
+		{"claude-sonnet-4-5", "sonnet-4-5", true},
+		{"", "opus", false},
+	}
+
+	for _, tt := range tests {
+		if got := ModelMatches(tt.model, tt.want); got != tt.expected {
+			t.Errorf("ModelMatches(%q, %q) = %v, expected %v", tt.model, tt.want, got, tt.expected)
+		}
+	}
+}
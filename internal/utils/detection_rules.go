@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/derekxwang/tcs/internal/config"
+)
+
+// DefaultDetectionThreshold is the score at which a window is considered a Claude window
+const DefaultDetectionThreshold = 1.0
+
+// DetectionRule is a weighted rule used to score whether a window runs Claude.
+// A rule fires when all of its patterns match; rules with a negative weight
+// count against Claude.
+type DetectionRule struct {
+	Name        string  `json:"name"`
+	Pattern     string  `json:"pattern,omitempty"`      // regex matched against pane content
+	PaneCommand string  `json:"pane_command,omitempty"` // regex matched against #{pane_current_command}
+	Weight      float64 `json:"weight"`
+}
+
+// RuleMatch describes a rule that fired during evaluation
+type RuleMatch struct {
+	Rule   DetectionRule `json:"rule"`
+	Source string        `json:"source"` // "content", "pane_command" or "both"
+	Match  string        `json:"match"`  // matched text (first match)
+}
+
+// DetectionResult is the outcome of evaluating a rule set against a window
+type DetectionResult struct {
+	Score       float64     `json:"score"`
+	Threshold   float64     `json:"threshold"`
+	IsClaude    bool        `json:"is_claude"`
+	PaneCommand string      `json:"pane_command"`
+	Matches     []RuleMatch `json:"matches"`
+}
+
+// RuleSet is a compiled set of detection rules
+type RuleSet struct {
+	rules     []compiledRule
+	threshold float64
+}
+
+// compiledRule holds a rule with its compiled patterns
+type compiledRule struct {
+	rule        DetectionRule
+	pattern     *regexp.Regexp
+	paneCommand *regexp.Regexp
+}
+
+// defaultDetectionRules are used when no rules are configured.
+// Generic words like "claude" or model names only add a little to the score so
+// that shells where someone grepped a log are not mistaken for Claude.
+var defaultDetectionRules = []DetectionRule{
+	// Pane command
+	{Name: "pane-command-claude", PaneCommand: `^(claude|claude-code|claude_code)$`, Weight: 1.0},
+	{Name: "pane-command-node", PaneCommand: `^node$`, Weight: 0.3},
+	{Name: "pane-command-shell", PaneCommand: `^-?(bash|zsh|fish|sh|dash|ksh|tcsh)$`, Weight: -0.5},
+
+	// Claude Code interface
+	{Name: "welcome-banner", Pattern: `(?i)welcome to claude code`, Weight: 1.0},
+	{Name: "shortcuts-hint", Pattern: `\? for shortcuts`, Weight: 1.0},
+	{Name: "mode-cycle-hint", Pattern: `\(shift\+tab to cycle\)`, Weight: 1.0},
+	{Name: "interrupt-hint", Pattern: `(?i)esc to interrupt`, Weight: 0.7},
+	{Name: "self-identification", Pattern: `(?i)\bI'm Claude\b`, Weight: 1.0},
+
+	// Weak indicators
+	{Name: "claude-command", Pattern: `(?m)^\s*[$>#%]\s*claude(\s|$)`, Weight: 0.4},
+	{Name: "transcript-roles", Pattern: `(?m)^\s*(Human|Assistant):`, Weight: 0.4},
+	{Name: "claude-mention", Pattern: `(?i)\bclaude\b`, Weight: 0.3},
+	{Name: "anthropic-mention", Pattern: `(?i)\banthropic\b`, Weight: 0.2},
+	{Name: "model-name", Pattern: `(?i)\b(opus|sonnet|haiku)\b`, Weight: 0.2},
+
+	// Negative patterns
+	{Name: "grep-output", Pattern: `(?m)^[\w./-]+\.\w+:\d+:`, Weight: -0.5},
+	{Name: "log-search", Pattern: `(?im)\b(grep|rg|ag|less|cat|tail|journalctl)\b[^\n]*claude`, Weight: -0.5},
+}
+
+// DefaultDetectionRules returns a copy of the built-in detection rules
+func DefaultDetectionRules() []DetectionRule {
+	rules := make([]DetectionRule, len(defaultDetectionRules))
+	copy(rules, defaultDetectionRules)
+	return rules
+}
+
+// NewRuleSet compiles detection rules into a rule set
+func NewRuleSet(rules []DetectionRule, threshold float64) (*RuleSet, error) {
+	if threshold <= 0 {
+		threshold = DefaultDetectionThreshold
+	}
+
+	rs := &RuleSet{threshold: threshold}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Pattern == "" && rule.PaneCommand == "" {
+			return nil, fmt.Errorf("detection rule '%s' needs a pattern or pane_command", rule.Name)
+		}
+
+		compiled := compiledRule{rule: rule}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern in detection rule '%s': %w", rule.Name, err)
+			}
+			compiled.pattern = re
+		}
+		if rule.PaneCommand != "" {
+			re, err := regexp.Compile(rule.PaneCommand)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pane_command in detection rule '%s': %w", rule.Name, err)
+			}
+			compiled.paneCommand = re
+		}
+		rs.rules = append(rs.rules, compiled)
+	}
+
+	return rs, nil
+}
+
+// DefaultRuleSet returns the rule set built from the default rules
+func DefaultRuleSet() *RuleSet {
+	rs, err := NewRuleSet(defaultDetectionRules, DefaultDetectionThreshold)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default detection rules: %v", err))
+	}
+	return rs
+}
+
+// Threshold returns the score needed to classify a window as Claude
+func (rs *RuleSet) Threshold() float64 {
+	return rs.threshold
+}
+
+// Rules returns the rules in the set
+func (rs *RuleSet) Rules() []DetectionRule {
+	rules := make([]DetectionRule, len(rs.rules))
+	for i, compiled := range rs.rules {
+		rules[i] = compiled.rule
+	}
+	return rules
+}
+
+// Evaluate scores pane content and the pane's current command against the rules.
+// An empty pane command skips rules that only match on the command.
+func (rs *RuleSet) Evaluate(content, paneCommand string) *DetectionResult {
+	result := &DetectionResult{
+		Threshold:   rs.threshold,
+		PaneCommand: paneCommand,
+	}
+
+	for _, compiled := range rs.rules {
+		match, source, ok := compiled.match(content, paneCommand)
+		if !ok {
+			continue
+		}
+
+		result.Score += compiled.rule.Weight
+		result.Matches = append(result.Matches, RuleMatch{
+			Rule:   compiled.rule,
+			Source: source,
+			Match:  match,
+		})
+	}
+
+	// Strongest evidence first
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		return result.Matches[i].Rule.Weight > result.Matches[j].Rule.Weight
+	})
+
+	// Allow for floating point error when weights add up to the threshold exactly
+	result.IsClaude = result.Score >= rs.threshold-1e-9
+	return result
+}
+
+// match reports whether all patterns of a rule match
+func (cr compiledRule) match(content, paneCommand string) (string, string, bool) {
+	var matched []string
+	var sources []string
+
+	if cr.paneCommand != nil {
+		command := strings.TrimSpace(paneCommand)
+		if command == "" || !cr.paneCommand.MatchString(command) {
+			return "", "", false
+		}
+		matched = append(matched, command)
+		sources = append(sources, "pane_command")
+	}
+
+	if cr.pattern != nil {
+		found := cr.pattern.FindString(content)
+		if found == "" && !cr.pattern.MatchString(content) {
+			return "", "", false
+		}
+		matched = append(matched, strings.TrimSpace(found))
+		sources = append(sources, "content")
+	}
+
+	source := sources[0]
+	if len(sources) > 1 {
+		source = "both"
+	}
+	return strings.Join(matched, " / "), source, true
+}
+
+// Configured rule set cache
+var (
+	configuredRuleSet     *RuleSet
+	configuredRuleSetOnce sync.Once
+)
+
+// ConfiguredRuleSet returns the rule set from the tmux configuration
+// (tmux.detection_rules and tmux.detection_threshold), falling back to the
+// default rules when none are configured or they are invalid
+func ConfiguredRuleSet() *RuleSet {
+	configuredRuleSetOnce.Do(func() {
+		cfg := config.GetTmuxConfig()
+
+		if len(cfg.DetectionRules) == 0 {
+			rs, err := NewRuleSet(defaultDetectionRules, cfg.DetectionThreshold)
+			if err != nil {
+				log.Printf("Warning: invalid detection threshold, using defaults: %v", err)
+				rs = DefaultRuleSet()
+			}
+			configuredRuleSet = rs
+			return
+		}
+
+		rules := make([]DetectionRule, len(cfg.DetectionRules))
+		for i, rule := range cfg.DetectionRules {
+			rules[i] = DetectionRule{
+				Name:        rule.Name,
+				Pattern:     rule.Pattern,
+				PaneCommand: rule.PaneCommand,
+				Weight:      rule.Weight,
+			}
+		}
+
+		rs, err := NewRuleSet(rules, cfg.DetectionThreshold)
+		if err != nil {
+			log.Printf("Warning: invalid detection rules in config, using defaults: %v", err)
+			rs = DefaultRuleSet()
+		}
+		configuredRuleSet = rs
+	})
+
+	return configuredRuleSet
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestDefaultRuleSetEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		paneCommand string
+		expected    bool
+	}{
+		{
+			name:     "empty content",
+			content:  "",
+			expected: false,
+		},
+		{
+			name:     "welcome banner",
+			content:  "✻ Welcome to Claude Code!\n\n  /help for help",
+			expected: true,
+		},
+		{
+			name:     "prompt box",
+			content:  "╭────────╮\n│ >      │\n╰────────╯\n  ? for shortcuts",
+			expected: true,
+		},
+		{
+			name:     "accept edits status line",
+			content:  "  ⏵⏵ accept edits on (shift+tab to cycle)",
+			expected: true,
+		},
+		{
+			name:        "claude pane command",
+			content:     "",
+			paneCommand: "claude",
+			expected:    true,
+		},
+		{
+			name:        "grepped log in a shell",
+			content:     "$ grep -rn claude logs/\nlogs/app.log:12: calling claude sonnet",
+			paneCommand: "bash",
+			expected:    false,
+		},
+		{
+			name:        "generic mention",
+			content:     "deploying the Claude integration for Anthropic",
+			paneCommand: "zsh",
+			expected:    false,
+		},
+		{
+			name:        "interrupt hint under node",
+			content:     "✶ Thinking… (esc to interrupt)",
+			paneCommand: "node",
+			expected:    true,
+		},
+		{
+			name:        "timestamps are not grep output",
+			content:     "12:30: build started\n12:31: tests passed\n✶ Thinking… (esc to interrupt)",
+			paneCommand: "node",
+			expected:    true,
+		},
+	}
+
+	rules := DefaultRuleSet()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rules.Evaluate(tt.content, tt.paneCommand)
+			if result.IsClaude != tt.expected {
+				t.Errorf("Evaluate(%q, %q) = %v (score %.2f), expected %v",
+					tt.content, tt.paneCommand, result.IsClaude, result.Score, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRuleSetMatches(t *testing.T) {
+	rules, err := NewRuleSet([]DetectionRule{
+		{Name: "banner", Pattern: `Claude Code`, Weight: 1.0},
+		{Name: "node-with-prompt", Pattern: `>`, PaneCommand: `^node$`, Weight: 0.5},
+		{Name: "shell", PaneCommand: `^bash$`, Weight: -2.0},
+	}, 1.5)
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	result := rules.Evaluate("Claude Code\n> ", "node")
+	if !result.IsClaude || result.Score != 1.5 {
+		t.Errorf("expected score 1.5 and Claude, got %.2f (%v)", result.Score, result.IsClaude)
+	}
+	if len(result.Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(result.Matches))
+	}
+	if result.Matches[0].Rule.Name != "banner" || result.Matches[1].Source != "both" {
+		t.Errorf("unexpected matches: %+v", result.Matches)
+	}
+
+	// Rules requiring a pane command do not fire without one
+	result = rules.Evaluate("Claude Code\n> ", "")
+	if result.IsClaude || len(result.Matches) != 1 {
+		t.Errorf("expected only the content rule to fire, got %+v", result.Matches)
+	}
+
+	// Negative rules count against Claude
+	result = rules.Evaluate("Claude Code", "bash")
+	if result.IsClaude || result.Score != -1.0 {
+		t.Errorf("expected score -1.0, got %.2f", result.Score)
+	}
+}
+
+func TestNewRuleSetValidation(t *testing.T) {
+	if _, err := NewRuleSet([]DetectionRule{{Name: "empty", Weight: 1}}, 1); err == nil {
+		t.Error("expected error for rule without patterns")
+	}
+	if _, err := NewRuleSet([]DetectionRule{{Name: "bad", Pattern: `(`, Weight: 1}}, 1); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+
+	rules, err := NewRuleSet(nil, 0)
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+	if rules.Threshold() != DefaultDetectionThreshold {
+		t.Errorf("expected default threshold, got %.2f", rules.Threshold())
+	}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"unicode"
+)
+
+// FuzzyMatch reports whether the characters of pattern appear in text in
+// order, ignoring case, and scores the match. Consecutive characters and
+// characters at the start of words score higher, so "pa0" ranks "proj:api.0"
+// above "pending approvals 10". An empty pattern matches everything with 0.
+func FuzzyMatch(pattern, text string) (int, bool) {
+	needle := []rune(toLowerRunes(pattern))
+	if len(needle) == 0 {
+		return 0, true
+	}
+	haystack := []rune(text)
+	lower := []rune(toLowerRunes(text))
+
+	best, found := 0, false
+	// Try every occurrence of the first character and keep the best greedy match
+	for start := range lower {
+		if lower[start] != needle[0] {
+			continue
+		}
+		score, ok := fuzzyScoreFrom(needle, haystack, lower, start)
+		if ok && (!found || score > best) {
+			best, found = score, true
+		}
+		if !ok {
+			break // later starts can't match either
+		}
+	}
+	return best, found
+}
+
+// fuzzyScoreFrom greedily matches needle in lower from start and scores the match
+func fuzzyScoreFrom(needle, haystack, lower []rune, start int) (int, bool) {
+	score := -min(start, 10) / 2 // prefer matches near the beginning
+	matched, last := 0, start-1
+	for i := start; i < len(lower) && matched < len(needle); i++ {
+		if lower[i] != needle[matched] {
+			continue
+		}
+		score++
+		if i == last+1 {
+			if matched > 0 {
+				score += 5
+			}
+		} else {
+			score -= min(i-last-1, 3) // penalize gaps between matched characters
+		}
+		if isWordStart(haystack, i) {
+			score += 8
+		}
+		last = i
+		matched++
+	}
+	return score, matched == len(needle)
+}
+
+// isWordStart checks if the rune at i starts a word: the first rune, one
+// after a separator, or an upper case letter after a lower case one
+func isWordStart(text []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := text[i-1], text[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// toLowerRunes lower cases text rune by rune, so indexes match the original runes
+func toLowerRunes(text string) string {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	matches := []struct {
+		pattern, text string
+	}{
+		{"", "anything"},
+		{"api", "proj:api.0"},
+		{"pa0", "proj:api.0"},
+		{"PROJ", "proj:api.0"},
+		{"fix tests", "Please fix the failing tests"},
+	}
+	for _, tt := range matches {
+		if _, ok := FuzzyMatch(tt.pattern, tt.text); !ok {
+			t.Errorf("FuzzyMatch(%q, %q) did not match", tt.pattern, tt.text)
+		}
+	}
+
+	for _, tt := range []struct{ pattern, text string }{
+		{"xyz", "proj:api.0"},
+		{"ipa", "api"},
+		{"apii", "api"},
+	} {
+		if _, ok := FuzzyMatch(tt.pattern, tt.text); ok {
+			t.Errorf("FuzzyMatch(%q, %q) matched", tt.pattern, tt.text)
+		}
+	}
+
+	// Consecutive and word start matches rank higher
+	ranked := []struct {
+		pattern, better, worse string
+	}{
+		{"pa0", "proj:api.0", "pending approvals 10"},
+		{"api", "api:0", "a pair of items"},
+		{"dash", "Go to Dashboard", "dead sheep"},
+		{"sched", "Scheduler", "show the checked"},
+	}
+	for _, tt := range ranked {
+		better, _ := FuzzyMatch(tt.pattern, tt.better)
+		worse, _ := FuzzyMatch(tt.pattern, tt.worse)
+		if better <= worse {
+			t.Errorf("FuzzyMatch(%q): %q scored %d, not above %q with %d", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseScheduleTime resolves when a message should be sent, relative to now.
+// Supports: "now", "+duration", "HH:MM" (the next occurrence),
+// "YYYY-MM-DD HH:MM" and "YYYY-MM-DD" (9 AM that day), all in the location
+// of now.
+func ParseScheduleTime(when string, now time.Time) (time.Time, error) {
+	if when == "" {
+		return time.Time{}, fmt.Errorf("time cannot be empty")
+	}
+
+	switch when {
+	case "now":
+		return now, nil
+	default:
+		// Handle relative time (+duration)
+		if len(when) > 1 && when[0] == '+' {
+			duration, err := time.ParseDuration(when[1:])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid duration format '%s': %w (use formats like +1h, +30m, +5s)", when, err)
+			}
+
+			// Validate reasonable duration limits
+			if duration < 0 {
+				return time.Time{}, fmt.Errorf("duration cannot be negative: %s", when)
+			}
+			if duration > 30*24*time.Hour { // 30 days
+				return time.Time{}, fmt.Errorf("duration too large (max 30 days): %s", when)
+			}
+
+			return now.Add(duration), nil
+		}
+
+		// Try parsing as time in different formats
+		// Try HH:MM format first (most common)
+		if t, err := time.Parse("15:04", when); err == nil {
+			// Combine with today's date
+			scheduledTime := time.Date(now.Year(), now.Month(), now.Day(),
+				t.Hour(), t.Minute(), 0, 0, now.Location())
+
+			// If time is in the past, schedule for tomorrow
+			if scheduledTime.Before(now) {
+				scheduledTime = scheduledTime.AddDate(0, 0, 1)
+			}
+			return scheduledTime, nil
+		}
+
+		// Try full datetime format: YYYY-MM-DD HH:MM
+		if t, err := time.ParseInLocation("2006-01-02 15:04", when, now.Location()); err == nil {
+			if t.Before(now) {
+				return time.Time{}, fmt.Errorf("scheduled time cannot be in the past: %s", when)
+			}
+			return t, nil
+		}
+
+		// Try date only format: YYYY-MM-DD (schedule for 9 AM)
+		if t, err := time.Parse("2006-01-02", when); err == nil {
+			scheduledTime := time.Date(t.Year(), t.Month(), t.Day(), 9, 0, 0, 0, now.Location())
+			if scheduledTime.Before(now) {
+				return time.Time{}, fmt.Errorf("scheduled date cannot be in the past: %s", when)
+			}
+			return scheduledTime, nil
+		}
+
+		return time.Time{}, fmt.Errorf("invalid time format '%s'. Supported formats: 'now', '+duration', 'HH:MM', 'YYYY-MM-DD HH:MM', 'YYYY-MM-DD'", when)
+	}
+}
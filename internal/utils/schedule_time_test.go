@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		when     string
+		expected time.Time
+	}{
+		{"now", now},
+		{"+90m", now.Add(90 * time.Minute)},
+		{"16:30", time.Date(2025, 3, 10, 16, 30, 0, 0, time.Local)},
+		{"09:00", time.Date(2025, 3, 11, 9, 0, 0, 0, time.Local)}, // already past today
+		{"2025-03-12 08:15", time.Date(2025, 3, 12, 8, 15, 0, 0, time.Local)},
+		{"2025-03-12", time.Date(2025, 3, 12, 9, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScheduleTime(tt.when, now)
+		if err != nil {
+			t.Errorf("ParseScheduleTime(%q) error: %v", tt.when, err)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("ParseScheduleTime(%q) = %v, expected %v", tt.when, got, tt.expected)
+		}
+	}
+
+	// Dates with a time are in the location of now, not UTC
+	berlin := time.FixedZone("CET", 3600)
+	got, err := ParseScheduleTime("2025-03-12 08:15", now.In(berlin))
+	if err != nil || !got.Equal(time.Date(2025, 3, 12, 8, 15, 0, 0, berlin)) {
+		t.Errorf("ParseScheduleTime in CET = %v, %v", got, err)
+	}
+
+	for _, when := range []string{"", "+-5m", "+800h", "2025-03-01 10:00", "tomorrow"} {
+		if _, err := ParseScheduleTime(when, now); err == nil {
+			t.Errorf("ParseScheduleTime(%q) accepted an invalid time", when)
+		}
+	}
+}
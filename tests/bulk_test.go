@@ -0,0 +1,323 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// TestBulkMessageActions tests bulk actions on messages in one transaction
+func TestBulkMessageActions(t *testing.T) {
+	db := setupTestDB(t)
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	var windows []*database.TmuxWindow
+	for _, target := range []string{"proj:0", "proj:1"} {
+		window := &database.TmuxWindow{SessionName: "proj", Target: target, Active: true}
+		require.NoError(t, db.Create(window).Error)
+		_, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+		require.NoError(t, err)
+		windows = append(windows, window)
+	}
+
+	base := time.Now().Add(time.Hour).Truncate(time.Second)
+	var ids []uint
+	for i, status := range []string{"pending", "pending", "pending", "sending", "sent"} {
+		message := &database.Message{
+			WindowID:      windows[0].ID,
+			Content:       fmt.Sprintf("message %d", i),
+			ScheduledTime: base.Add(time.Duration(i) * time.Minute),
+			Priority:      5,
+			Status:        status,
+		}
+		require.NoError(t, db.Create(message).Error)
+		ids = append(ids, message.ID)
+	}
+	require.NoError(t, database.SyncQueueMessageCounts(db))
+
+	apply := func(action database.BulkMessageAction, ids ...uint) (int64, error) {
+		var affected int64
+		err := database.Transaction(func(tx *gorm.DB) error {
+			var err error
+			affected, err = database.ApplyBulkMessageAction(tx, ids, action)
+			return err
+		})
+		return affected, err
+	}
+	load := func(id uint) database.Message {
+		var message database.Message
+		require.NoError(t, db.Unscoped().First(&message, id).Error)
+		return message
+	}
+	queueCount := func(window *database.TmuxWindow) int {
+		var queue database.WindowMessageQueue
+		require.NoError(t, db.Where("window_id = ?", window.ID).First(&queue).Error)
+		return queue.MessageCount
+	}
+
+	// Only pending messages get a new priority
+	affected, err := apply(database.BulkMessageAction{Kind: database.BulkPriority, Priority: 9}, ids...)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	assert.Equal(t, 9, load(ids[0]).Priority)
+	assert.Equal(t, 5, load(ids[3]).Priority)
+
+	_, err = apply(database.BulkMessageAction{Kind: database.BulkPriority, Priority: 11}, ids...)
+	assert.Error(t, err)
+
+	// Shifting keeps the spacing between messages, a time replaces it
+	affected, err = apply(database.BulkMessageAction{Kind: database.BulkReschedule, Shift: -30 * time.Minute}, ids[0], ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+	assert.True(t, base.Add(-30*time.Minute).Equal(load(ids[0]).ScheduledTime))
+	assert.True(t, base.Add(-29*time.Minute).Equal(load(ids[1]).ScheduledTime))
+	assert.True(t, base.Add(2*time.Minute).Equal(load(ids[2]).ScheduledTime))
+
+	at := base.Add(24 * time.Hour)
+	_, err = apply(database.BulkMessageAction{Kind: database.BulkReschedule, ScheduleAt: &at}, ids[0], ids[1])
+	require.NoError(t, err)
+	assert.True(t, at.Equal(load(ids[0]).ScheduledTime))
+	assert.True(t, at.Equal(load(ids[1]).ScheduledTime))
+
+	// Retargeting moves the messages and their queue counts
+	assert.Equal(t, 3, queueCount(windows[0]))
+	affected, err = apply(database.BulkMessageAction{Kind: database.BulkRetarget, Target: "proj:1"}, ids[0], ids[1], ids[3])
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+	assert.Equal(t, windows[1].ID, load(ids[0]).WindowID)
+	assert.Equal(t, windows[0].ID, load(ids[3]).WindowID)
+	assert.Equal(t, 1, queueCount(windows[0]))
+	assert.Equal(t, 2, queueCount(windows[1]))
+
+	// A failed action changes nothing, even when it fails after the messages were written
+	_, err = apply(database.BulkMessageAction{Kind: database.BulkRetarget, Target: "proj:9"}, ids[0])
+	assert.Error(t, err)
+	assert.Equal(t, windows[1].ID, load(ids[0]).WindowID)
+
+	require.NoError(t, db.Exec(`CREATE TRIGGER fail_queue_sync BEFORE UPDATE ON window_message_queues
+		BEGIN SELECT RAISE(ABORT, 'queue sync failed'); END`).Error)
+	_, err = apply(database.BulkMessageAction{Kind: database.BulkRetarget, Target: "proj:0"}, ids[0], ids[1])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "queue sync failed")
+	_, err = apply(database.BulkMessageAction{Kind: database.BulkPriority, Priority: 2}, ids[0])
+	require.Error(t, err)
+	require.NoError(t, db.Exec("DROP TRIGGER fail_queue_sync").Error)
+	assert.Equal(t, windows[1].ID, load(ids[0]).WindowID)
+	assert.Equal(t, windows[1].ID, load(ids[1]).WindowID)
+	assert.Equal(t, 9, load(ids[0]).Priority)
+	assert.Equal(t, 1, queueCount(windows[0]))
+	assert.Equal(t, 2, queueCount(windows[1]))
+
+	// Deleting skips messages being sent
+	affected, err = apply(database.BulkMessageAction{Kind: database.BulkDelete}, ids...)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), affected)
+	var left []database.Message
+	require.NoError(t, db.Find(&left).Error)
+	require.Len(t, left, 1)
+	assert.Equal(t, ids[3], left[0].ID)
+	assert.Equal(t, 0, queueCount(windows[1]))
+}
+
+// TestMessageTableSelection tests selecting messages and choosing bulk actions
+func TestMessageTableSelection(t *testing.T) {
+	mt := components.NewMessageTable(components.MessageTableOptions{Width: 100, Height: 10, Focused: true})
+	var messages []types.MessageDisplayInfo
+	for i := 1; i <= 6; i++ {
+		session := "api"
+		if i > 4 {
+			session = "web"
+		}
+		messages = append(messages, types.MessageDisplayInfo{
+			ID:            uint(i),
+			SessionName:   session + ":0",
+			Content:       fmt.Sprintf("task %d", i),
+			Priority:      5,
+			Status:        "pending",
+			ScheduledTime: time.Now().Add(time.Hour),
+		})
+	}
+	mt.SetMessages(messages)
+
+	press := func(keys ...string) tea.Cmd {
+		var cmd tea.Cmd
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "down":
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			case " ":
+				msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+			}
+			mt, cmd = mt.Update(msg)
+		}
+		return cmd
+	}
+
+	// Without a selection actions apply to the cursor row
+	assert.Empty(t, mt.SelectedIDs())
+	assert.Equal(t, []uint{1}, mt.TargetIDs())
+
+	// Space toggles a row, V selects a range up to the cursor
+	press(" ", "down", "down", "V", "down", "down")
+	assert.Equal(t, []uint{1, 3, 4, 5}, mt.SelectedIDs(), "an open range follows the cursor")
+	press("V", "down")
+	assert.Equal(t, []uint{1, 3, 4, 5}, mt.SelectedIDs())
+	assert.Contains(t, mt.View(), "4 messages selected")
+
+	// The selection survives a refresh, except for messages that are gone
+	mt.SetMessages(messages[1:])
+	assert.Equal(t, []uint{3, 4, 5}, mt.SelectedIDs())
+	assert.Equal(t, uint(6), mt.GetSelectedMessage().ID)
+
+	press("esc")
+	assert.Empty(t, mt.SelectedIDs())
+
+	// Select by filter
+	press("/")
+	assert.True(t, mt.IsPromptActive())
+	press("w", "e", "b", "enter")
+	assert.False(t, mt.IsPromptActive())
+	assert.Equal(t, []uint{5, 6}, mt.SelectedIDs())
+
+	// Invalid values keep the prompt open with an error
+	press("=", "1", "2", "enter")
+	assert.True(t, mt.IsPromptActive())
+	assert.Contains(t, mt.View(), "1 to 10")
+
+	// A valid value sends the action for the selection and clears it
+	mt.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	mt.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	cmd := press("8", "enter")
+	require.NotNil(t, cmd)
+	bulk, ok := cmd().(components.MessageBulkMsg)
+	require.True(t, ok)
+	assert.Equal(t, []uint{5, 6}, bulk.IDs)
+	assert.Equal(t, database.BulkPriority, bulk.Action.Kind)
+	assert.Equal(t, 8, bulk.Action.Priority)
+	assert.Empty(t, mt.SelectedIDs())
+
+	// Reschedules shift by a signed duration or move to a time
+	cmd = press(" ", "t", "-", "1", "5", "m", "enter")
+	assert.Empty(t, mt.SelectedIDs())
+	bulk = cmd().(components.MessageBulkMsg)
+	assert.Equal(t, -15*time.Minute, bulk.Action.Shift)
+	assert.Nil(t, bulk.Action.ScheduleAt)
+
+	cmd = press("t", "n", "o", "w", "enter")
+	bulk = cmd().(components.MessageBulkMsg)
+	require.NotNil(t, bulk.Action.ScheduleAt)
+
+	cmd = press("w", "w", "e", "b", ":", "1", "enter")
+	bulk = cmd().(components.MessageBulkMsg)
+	assert.Equal(t, database.BulkRetarget, bulk.Action.Kind)
+	assert.Equal(t, "web:1", bulk.Action.Target)
+	assert.Len(t, bulk.IDs, 1)
+
+	// Esc closes the prompt without an action
+	assert.Nil(t, press("=", "esc"))
+	assert.False(t, mt.IsPromptActive())
+}
+
+// TestMessagesViewBulkActions tests selecting messages and running bulk actions in the Messages view
+func TestMessagesViewBulkActions(t *testing.T) {
+	db := setupTestDB(t)
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	window := &database.TmuxWindow{SessionName: "proj", Target: "proj:0", Active: true}
+	require.NoError(t, db.Create(window).Error)
+	_, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+	require.NoError(t, err)
+
+	// The view lists the latest messages first
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		message := &database.Message{WindowID: window.ID, Content: fmt.Sprintf("message %d", i), Priority: 5,
+			ScheduledTime: time.Now().Add(time.Duration(3-i) * time.Hour), Status: database.MessageStatusPending}
+		require.NoError(t, db.Create(message).Error)
+		ids = append(ids, message.ID)
+	}
+
+	m := views.NewMessages(db, nil, nil)
+	m.SetSize(120, 40)
+	// run feeds everything a command produces to the view, and so on
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				run(c)
+			}
+			return
+		}
+		if msg != nil {
+			m, cmd = m.Update(msg)
+			run(cmd)
+		}
+	}
+	update := func(msg tea.Msg) {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		run(cmd)
+	}
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "down":
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case " ":
+				msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+			}
+			update(msg)
+		}
+	}
+	priority := func(id uint) int {
+		var message database.Message
+		require.NoError(t, db.First(&message, id).Error)
+		return message.Priority
+	}
+
+	run(m.Refresh())
+	require.NotNil(t, m.SelectedMessage())
+	assert.Equal(t, ids[0], m.SelectedMessage().ID)
+
+	// The prompt takes the keys like a form
+	press(" ", "down", " ", "=")
+	assert.True(t, m.IsFormActive())
+	press("3", "enter")
+	assert.False(t, m.IsFormActive())
+	assert.Equal(t, 3, priority(ids[0]))
+	assert.Equal(t, 3, priority(ids[1]))
+	assert.Equal(t, 5, priority(ids[2]))
+
+	// Delete removes the selection
+	press(" ", "down", " ", "d")
+	var left []database.Message
+	require.NoError(t, db.Find(&left).Error)
+	require.Len(t, left, 1)
+	assert.Equal(t, ids[0], left[0].ID)
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/derekxwang/tcs/internal/claude"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// TestCharts tests the sparkline, bar chart and burn rate components
+func TestCharts(t *testing.T) {
+	assert.Equal(t, " ▄█", components.Sparkline([]int{0, 50, 100}, 10, false))
+	assert.Equal(t, " =@", components.Sparkline([]int{0, 50, 100}, 10, true))
+	assert.Equal(t, "▁█", components.Sparkline([]int{7, 1, 100}, 2, false), "only the most recent values fit")
+
+	chart := components.BarChart([]components.ChartBar{
+		{Label: "09:00", Value: 2000},
+		{Label: "10:00", Value: 500},
+	}, 20, true)
+	assert.Equal(t, "09:00 ######### 2.0k\n10:00 ##.......  500", chart)
+
+	burn := components.BurnRate{Used: 30000, Limit: 100000, Elapsed: time.Hour, Remaining: 4 * time.Hour}
+	assert.Equal(t, 500.0, burn.PerMinute())
+	assert.Equal(t, 150000, burn.Projected())
+
+	highContrast, _ := theme.Get(theme.HighContrast)
+	line := components.BurnRateLine(burn, 60, highContrast)
+	assert.Equal(t, 60, lipgloss.Width(line))
+	assert.True(t, strings.HasPrefix(line, "Burn 500/min ["), line)
+	assert.True(t, strings.HasSuffix(line, "] 150k of 100k P90 !"), line)
+	assert.Contains(t, line, "|")
+	assert.NotContains(t, line, "█")
+}
+
+// TestDashboardUsageHistory tests that the usage history fits an 80 column terminal
+func TestDashboardUsageHistory(t *testing.T) {
+	now := time.Now()
+	dashboard := views.NewDashboard(nil, nil, nil, nil)
+	dashboard.SetSize(80, 40)
+	dashboard.SetTheme("high_contrast")
+
+	dashboard, _ = dashboard.Update(types.RefreshDataMsg{Type: "dashboard", Data: map[string]interface{}{
+		"usage": types.UsageStats{
+			TokensUsed:      40000,
+			TokensLimit:     120000,
+			WindowStartTime: now.Add(-2 * time.Hour),
+			WindowEndTime:   now.Add(3 * time.Hour),
+			TimeRemaining:   3 * time.Hour,
+		},
+		"history": &claude.UsageHistory{
+			WindowStart:  now.Add(-2 * time.Hour),
+			WindowEnd:    now.Add(3 * time.Hour),
+			HourlyTokens: []int{25000, 15000, 0, 0, 0},
+			Sessions: []claude.SessionSummary{
+				{TotalTokens: 90000}, {TotalTokens: 120000}, {TotalTokens: 40000},
+			},
+		},
+	}})
+
+	view := dashboard.View()
+	_, charts, found := strings.Cut(view, "Usage History")
+	assert.True(t, found)
+	charts, _, _ = strings.Cut(charts, "System Status")
+	assert.Contains(t, charts, "Tokens per hour")
+	assert.Contains(t, charts, "3 sessions | peak 120k | avg 83k")
+	assert.Contains(t, charts, "Burn 333/min [")
+	assert.Contains(t, charts, "] 100k of 120k P90")
+	assert.NotContains(t, charts, "█", "high contrast charts use ASCII")
+	assert.NotContains(t, charts, "•")
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 80, line)
+	}
+}
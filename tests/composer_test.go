@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/components"
+)
+
+// TestComposer tests writing and submitting a message in the composer
+func TestComposer(t *testing.T) {
+	composer := components.NewComposer([]config.MessageTemplate{
+		{Name: "tests", Content: "Run the tests."},
+		{Name: "lint", Content: "Fix the lint errors."},
+	})
+	composer.SetSize(100, 40)
+	composer.Open()
+	composer.SetWindows([]database.TmuxWindow{
+		{Target: "dev:0", WindowName: "api"},
+		{Target: "dev:1", WindowName: "web"},
+	})
+
+	press := func(keys ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, key := range keys {
+			composer, cmd = composer.Update(key)
+		}
+		return cmd
+	}
+	typeText := func(text string) {
+		for _, r := range text {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	ctrlS := tea.KeyMsg{Type: tea.KeyCtrlS}
+
+	// An empty message is refused
+	assert.Nil(t, press(ctrlS))
+	assert.Contains(t, composer.View(), "message cannot be empty")
+
+	// Multi-line content with a template inserted at the cursor
+	typeText("First line")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(tea.KeyMsg{Type: tea.KeyCtrlT}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Window picker, priority slider and schedule field
+	press(tea.KeyMsg{Type: tea.KeyShiftTab}, tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyTab})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'8'}}, tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("+30m")
+	assert.Contains(t, composer.View(), "(in 30m)")
+
+	before := time.Now()
+	cmd := press(ctrlS)
+	require.NotNil(t, cmd)
+	submit, ok := cmd().(components.ComposerSubmitMsg)
+	require.True(t, ok)
+	assert.Equal(t, "dev:1", submit.Message.Target)
+	assert.Equal(t, "First line\nFix the lint errors.", submit.Message.Content)
+	assert.Equal(t, 9, submit.Message.Priority)
+	assert.WithinDuration(t, before.Add(30*time.Minute), submit.Message.ScheduledTime, 5*time.Second)
+
+	// Times the parser rejects are shown instead of submitted
+	press(tea.KeyMsg{Type: tea.KeyCtrlU})
+	typeText("someday")
+	assert.Nil(t, press(ctrlS))
+	assert.Contains(t, composer.View(), "invalid time format")
+
+	// Escape cancels
+	cmd = press(tea.KeyMsg{Type: tea.KeyEsc})
+	require.NotNil(t, cmd)
+	assert.IsType(t, components.ComposerCancelMsg{}, cmd())
+}
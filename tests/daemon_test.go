@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/daemon"
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// setupDaemonTestDB creates a test database that the daemon's goroutines share
+// with the test. Each connection to an in-memory database opens its own, so
+// the pool is kept to one.
+func setupDaemonTestDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+// TestDaemonServer tests finding the daemon and running the requests left for it
+func TestDaemonServer(t *testing.T) {
+	db := setupDaemonTestDB(t)
+
+	info, err := daemon.Find(db)
+	require.NoError(t, err)
+	assert.Nil(t, info, "no daemon ran yet")
+
+	// A started daemon can be found until it stops
+	server := daemon.NewServer(db)
+	require.NoError(t, server.Start())
+	info, err = daemon.Find(db)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, database.LeaseOwnerID(), info.Owner)
+	require.NoError(t, server.Stop())
+	info, err = daemon.Find(db)
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	// A missed heartbeat means the daemon is gone, the next one brings it back
+	require.NoError(t, server.Heartbeat())
+	client := daemon.NewClient(db, &daemon.Info{Owner: database.LeaseOwnerID()})
+	assert.True(t, client.Alive())
+	stale := time.Now().Add(-4 * daemon.HeartbeatInterval)
+	require.NoError(t, db.Model(&database.SchedulerState{}).Where("name = ?", database.SchedulerDaemon).Update("last_run", &stale).Error)
+	assert.False(t, client.Alive())
+	require.NoError(t, server.Heartbeat())
+	assert.True(t, client.Alive())
+
+	// Requests run once per kind and are removed
+	processed, rescans := 0, 0
+	server.Handle(daemon.RequestProcess, func() error {
+		processed++
+		return nil
+	})
+	server.Handle(daemon.RequestRescan, func() error {
+		rescans++
+		return errors.New("tmux is gone")
+	})
+	require.NoError(t, client.Request(daemon.RequestProcess))
+	require.NoError(t, client.Request(daemon.RequestProcess))
+	require.NoError(t, client.Request(daemon.RequestRescan))
+	require.NoError(t, client.Request("unknown"))
+
+	err = server.RunPending()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tmux is gone")
+	assert.Contains(t, err.Error(), `unknown daemon request "unknown"`)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, 1, rescans)
+
+	var left int64
+	require.NoError(t, db.Model(&database.DaemonRequest{}).Count(&left).Error)
+	assert.Zero(t, left)
+	require.NoError(t, server.RunPending())
+	assert.Equal(t, 1, processed)
+
+	// Another live daemon can't be started next to this one
+	require.NoError(t, db.Model(&database.SchedulerState{}).Where("name = ?", database.SchedulerDaemon).Update("owner", "elsewhere:1").Error)
+	err = daemon.NewServer(db).Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "elsewhere:1")
+}
+
+// TestAttachedViews tests that attached views forward actions to the daemon
+func TestAttachedViews(t *testing.T) {
+	db := setupDaemonTestDB(t)
+	client := daemon.NewClient(db, &daemon.Info{Owner: "host:42"})
+	requests := func(kind string) int64 {
+		var count int64
+		require.NoError(t, db.Model(&database.DaemonRequest{}).Where("kind = ?", kind).Count(&count).Error)
+		return count
+	}
+
+	s := views.NewScheduler(db, nil, nil)
+	s.SetRemote(client)
+	msg := s.ResumeSending()()
+	assert.IsType(t, types.SuccessMsg{}, msg)
+	assert.Equal(t, int64(1), requests(daemon.RequestProcess))
+
+	// The rescan waits for the daemon to run it
+	server := daemon.NewServer(db)
+	rescans := 0
+	server.Handle(daemon.RequestRescan, func() error {
+		rescans++
+		return nil
+	})
+	require.NoError(t, server.Start())
+	defer server.Stop()
+	info, err := daemon.Find(db)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+
+	w := views.NewWindows(db, nil, nil)
+	w.SetRemote(daemon.NewClient(db, info))
+	msg = w.ForceRescan()()
+	success, ok := msg.(types.SuccessMsg)
+	require.True(t, ok, "the rescan doesn't need tmux here: %v", msg)
+	assert.Contains(t, success.Message, info.Owner)
+	assert.Equal(t, 1, rescans)
+	assert.Zero(t, requests(daemon.RequestRescan))
+
+	// A daemon that stopped fails the rescan instead of leaving it waiting
+	require.NoError(t, server.Stop())
+	msg = w.ForceRescan()()
+	failure, ok := msg.(types.ErrorMsg)
+	require.True(t, ok, "%v", msg)
+	assert.Contains(t, failure.Message, "stopped")
+}
+
+// TestAttachedSendNow tests that sending a message now moves it up and asks the daemon to process it
+func TestAttachedSendNow(t *testing.T) {
+	db := setupTestDB(t)
+	client := daemon.NewClient(db, &daemon.Info{Owner: "host:42"})
+
+	window := &database.TmuxWindow{SessionName: "proj", Target: "proj:0", Active: true}
+	require.NoError(t, db.Create(window).Error)
+	message := &database.Message{WindowID: window.ID, Content: "later", Priority: 5,
+		ScheduledTime: time.Now().Add(time.Hour), Status: database.MessageStatusPending}
+	require.NoError(t, db.Create(message).Error)
+
+	s := views.NewScheduler(db, nil, nil)
+	s.SetRemote(client)
+	s.SetSize(120, 40)
+	for _, cmd := range s.Refresh()().(tea.BatchMsg) {
+		s, _ = s.Update(cmd())
+	}
+	s, cmd := s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	require.NotNil(t, cmd)
+	assert.IsType(t, types.SuccessMsg{}, cmd())
+
+	var saved database.Message
+	require.NoError(t, db.First(&saved, message.ID).Error)
+	assert.False(t, saved.ScheduledTime.After(time.Now()))
+	var requests int64
+	require.NoError(t, db.Model(&database.DaemonRequest{}).Where("kind = ?", daemon.RequestProcess).Count(&requests).Error)
+	assert.Equal(t, int64(1), requests)
+}
+
+// TestAppAttachHeader tests the header showing how the TUI runs next to the daemon
+func TestAppAttachHeader(t *testing.T) {
+	header := func(app *tui.App) string {
+		model, _ := app.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+		return strings.Split(model.View(), "\n")[0]
+	}
+
+	app := tui.NewApp(nil, nil, nil, nil, nil)
+	app.SetStatus("standalone: no daemon found")
+	assert.Contains(t, header(app), "standalone: no daemon found")
+
+	app.Attach(daemon.NewClient(setupTestDB(t), &daemon.Info{Owner: "host:42"}))
+	assert.Contains(t, header(app), "attached to host:42")
+	assert.NotContains(t, header(app), "standalone")
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/events"
+	"github.com/derekxwang/tcs/internal/monitor"
+	"github.com/derekxwang/tcs/internal/scheduler"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// TestEventRecorder tests recording message, window, usage and error events
+func TestEventRecorder(t *testing.T) {
+	db := setupTestDB(t)
+	recorder := events.NewRecorder(db)
+
+	window := database.TmuxWindow{SessionName: "dev", WindowIndex: 0, WindowName: "api", Target: "dev:0", HasClaude: true}
+	message := &database.Message{Content: "Run the tests\nand fix them", Priority: 7, Window: window}
+	message.ID = 42
+
+	recorder.RecordMessageEvent(&scheduler.MessageEvent{Type: "queued", Message: message, Window: &message.Window})
+	recorder.RecordMessageEvent(&scheduler.MessageEvent{Type: "retrying", Message: message, Window: &message.Window,
+		Error: errors.New("pane busy"), Attempt: 1})
+	routed := &database.Message{Content: "Summarize", Route: "any:idle", Window: window}
+	recorder.RecordMessageEvent(&scheduler.MessageEvent{Type: "queued", Message: routed, Window: &routed.Window})
+	recorder.RecordWindowEvent("discovered", &window)
+	recorder.RecordError("discovery", errors.New("tmux server is not running"))
+
+	// The first usage update is the baseline; only the highest threshold crossed is recorded
+	recorder.RecordUsage(&monitor.UsageStats{UsagePercentage: 0.6})
+	recorder.RecordUsage(&monitor.UsageStats{UsagePercentage: 0.7})
+	recorder.RecordUsage(&monitor.UsageStats{UsagePercentage: 0.95, TokensUsed: 95, TokenLimit: 100})
+
+	recent, err := database.GetRecentEvents(db, "", 10)
+	require.NoError(t, err)
+	require.Len(t, recent, 6)
+
+	assert.Equal(t, database.EventKindMessage, recent[0].Kind)
+	assert.Equal(t, "dev:0", recent[0].Target)
+	assert.Equal(t, uint(42), recent[0].MessageID)
+	assert.Equal(t, "Queued with priority 7: Run the tests ...", recent[0].Summary)
+	assert.Equal(t, "Retrying after attempt 1: pane busy", recent[1].Summary)
+	assert.Equal(t, "any:idle", recent[2].Target, "routed messages show their route until dispatch")
+	assert.Equal(t, "Window api discovered with Claude", recent[3].Summary)
+	assert.Equal(t, database.EventKindError, recent[4].Kind)
+	assert.Equal(t, "discovery", recent[4].Type)
+	assert.Equal(t, database.EventKindUsage, recent[5].Kind)
+	assert.Contains(t, recent[5].Summary, "Usage crossed 90%: ")
+
+	// Filtering by kind and loading only newer events
+	windowEvents, err := database.GetRecentEvents(db, database.EventKindWindow, 10)
+	require.NoError(t, err)
+	require.Len(t, windowEvents, 1)
+
+	newer, err := database.GetEventsAfter(db, recent[3].ID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, newer, 2)
+	assert.Equal(t, recent[4].ID, newer[0].ID)
+
+	latest, err := database.GetRecentEvents(db, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{recent[4].ID, recent[5].ID}, []uint{latest[0].ID, latest[1].ID}, "oldest first")
+}
+
+// TestEventsView tests loading, filtering and scrolling in the events view
+func TestEventsView(t *testing.T) {
+	db := setupTestDB(t)
+	recorder := events.NewRecorder(db)
+	for _, target := range []string{"dev:0", "dev:1", "web:0"} {
+		recorder.RecordWindowEvent("discovered", &database.TmuxWindow{WindowName: target, Target: target})
+	}
+	recorder.RecordError("scheduler", errors.New("database health check failed"))
+
+	view := views.NewEvents(db)
+	view.SetSize(100, 20)
+	load := func() {
+		msg := view.Refresh()()
+		refresh, ok := msg.(types.RefreshDataMsg)
+		require.True(t, ok, "unexpected %T", msg)
+		view, _ = view.Update(refresh)
+	}
+	press := func(keys ...string) {
+		for _, k := range keys {
+			var msg tea.KeyMsg
+			switch k {
+			case "tab":
+				msg = tea.KeyMsg{Type: tea.KeyTab}
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			default:
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			}
+			view, _ = view.Update(msg)
+		}
+	}
+
+	load()
+	output := view.View()
+	assert.Contains(t, output, "Events (all) • 4 shown • following")
+	assert.Contains(t, output, "database health check failed")
+
+	// Later refreshes only add new events
+	recorder.RecordWindowEvent("lost", &database.TmuxWindow{WindowName: "web", Target: "web:0"})
+	load()
+	load()
+	assert.Contains(t, view.View(), "5 shown")
+
+	// Text filter; the view counts as a form while typing
+	press("/")
+	assert.True(t, view.IsFormActive())
+	press("w", "e", "b", "enter")
+	assert.False(t, view.IsFormActive())
+	output = view.View()
+	assert.Contains(t, output, "2 shown")
+	assert.NotContains(t, output, "dev:1")
+
+	// Kind filter
+	press("esc", "tab", "tab", "tab", "tab")
+	output = view.View()
+	assert.Contains(t, output, "Events (error) • 1 shown")
+	assert.NotContains(t, output, "dev:0")
+
+	// Scrolling back stops following new events
+	press("tab")
+	view.SetSize(100, 6)
+	press("k")
+	assert.Contains(t, view.View(), "1 newer")
+	press("G")
+	assert.Contains(t, view.View(), "following")
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/tui"
+	"github.com/derekxwang/tcs/internal/tui/keys"
+	"github.com/derekxwang/tcs/internal/tui/views"
+)
+
+// TestKeyBindings tests remapping and validating key bindings
+func TestKeyBindings(t *testing.T) {
+	newSections := func() []keys.Section {
+		global := tui.DefaultKeyMap()
+		windows := views.NewWindows(nil, nil, nil)
+		messages := views.NewMessages(nil, nil, nil)
+		sections := []keys.Section{{Name: keys.Global, Title: "Global", Actions: global.Actions()}}
+		sections = append(sections, windows.KeySections()...)
+		return append(sections, messages.KeySections()...)
+	}
+
+	// The defaults don't conflict
+	assert.NoError(t, keys.Validate(newSections()))
+
+	// Overrides replace the keys and the help
+	sections := newSections()
+	require.NoError(t, keys.Apply(sections, map[string]map[string][]string{
+		"windows":  {"scan": {"S", "ctrl+s"}},
+		"messages": {"toggle_mark": {"space", "x"}},
+	}))
+	scan := sections[1].Actions[0].Binding
+	assert.Equal(t, []string{"S", "ctrl+s"}, scan.Keys())
+	assert.Equal(t, "S/ctrl+s", scan.Help().Key)
+	assert.True(t, key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}}, *scan))
+	assert.NoError(t, keys.Validate(sections))
+
+	var toggle *key.Binding
+	for _, action := range sections[2].Actions {
+		if action.Name == "toggle_mark" {
+			toggle = action.Binding
+		}
+	}
+	require.NotNil(t, toggle)
+	assert.Equal(t, []string{" ", "x"}, toggle.Keys())
+	assert.Equal(t, "space/x", toggle.Help().Key)
+
+	// Unknown views, unknown actions and empty keys are reported together
+	err := keys.Apply(newSections(), map[string]map[string][]string{
+		"nowhere":  {"scan": {"s"}},
+		"windows":  {"explode": {"x"}, "jump": {}},
+		"messages": {"edit": {"E"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown view "nowhere"`)
+	assert.Contains(t, err.Error(), `windows: unknown action "explode"`)
+	assert.Contains(t, err.Error(), "windows.jump")
+
+	// Keys shared within a view conflict, including with the global keys
+	sections = newSections()
+	require.NoError(t, keys.Apply(sections, map[string]map[string][]string{
+		"windows": {"scan": {"a"}, "jump": {"r"}},
+	}))
+	err = keys.Validate(sections)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `windows: "a" is bound to both scan and toggle_active`)
+	assert.Contains(t, err.Error(), `windows: "r" is bound to both global.refresh and jump`)
+
+	// Form keys are only active in the form, so they may reuse table keys
+	sections = newSections()
+	require.NoError(t, keys.Apply(sections, map[string]map[string][]string{
+		"messages": {"submit_form": {"n"}},
+	}))
+	assert.NoError(t, keys.Validate(sections))
+
+	// The same key may be used in different views
+	sections = newSections()
+	require.NoError(t, keys.Apply(sections, map[string]map[string][]string{
+		"windows":  {"scan": {"x"}},
+		"messages": {"requeue": {"x"}},
+	}))
+	assert.NoError(t, keys.Validate(sections))
+
+	assert.Equal(t, "S/ctrl+s: scan windows  tab: switch table",
+		keys.Hint(*scan, *sections[1].Actions[1].Binding))
+}
+
+// TestKeyBindingsConfig tests loading key bindings from the tui.keys config section
+func TestKeyBindingsConfig(t *testing.T) {
+	t.Cleanup(func() { _, _ = config.Load("") })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "tui:\n  keys:\n    global:\n      refresh: [\"ctrl+r\"]\n    windows:\n      scan: [\"S\"]\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	_, err := config.Load(path)
+	require.NoError(t, err)
+	overrides := config.GetTUIConfig().Keys
+	assert.Equal(t, []string{"S"}, overrides["windows"]["scan"])
+
+	app := tui.NewApp(nil, nil, nil, nil, nil)
+	require.NoError(t, app.ApplyKeys(overrides))
+
+	// The footer and help are generated from the active bindings
+	model, _ := app.Update(tea.WindowSizeMsg{Width: 140, Height: 50})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	view := model.View()
+	assert.Contains(t, view, "ctrl+r: refresh")
+	assert.NotContains(t, view, " r: refresh")
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	view = model.View()
+	assert.Contains(t, view, "tui.keys.windows")
+	assert.Contains(t, view, "scan windows")
+	assert.Contains(t, view, "toggle_active")
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.NotContains(t, model.View(), "tui.keys.windows")
+
+	// Conflicting bindings are rejected
+	app = tui.NewApp(nil, nil, nil, nil, nil)
+	err = app.ApplyKeys(map[string]map[string][]string{"scheduler": {"send_now": {"q"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "global.quit")
+}
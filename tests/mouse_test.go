@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/config"
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// lineOf returns the first line of view containing text, or -1
+func lineOf(view, text string) int {
+	for i, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, text) {
+			return i
+		}
+	}
+	return -1
+}
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+}
+
+// TestTableMouse tests locating clicked rows and wheel scrolling in tables
+func TestTableMouse(t *testing.T) {
+	var rows []table.Row
+	for i := 0; i < 20; i++ {
+		rows = append(rows, table.Row{fmt.Sprintf("row-%02d", i)})
+	}
+	tbl := table.New(table.WithColumns([]table.Column{{Title: "Name", Width: 10}}), table.WithRows(rows), table.WithHeight(5))
+
+	// The header takes the first lines, then the rows follow
+	header := lineOf(tbl.View(), "row-00")
+	require.Greater(t, header, 0)
+	row, ok := components.TableRowAt(tbl, header+2)
+	require.True(t, ok)
+	assert.Equal(t, 2, row)
+	_, ok = components.TableRowAt(tbl, 0)
+	assert.False(t, ok, "the header isn't a row")
+	_, ok = components.TableRowAt(tbl, 100)
+	assert.False(t, ok)
+
+	// Rows are found after the table scrolls
+	assert.True(t, components.ScrollTable(&tbl, tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown}))
+	assert.True(t, components.ScrollTable(&tbl, tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown}))
+	assert.True(t, components.ScrollTable(&tbl, tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown}))
+	assert.Equal(t, 9, tbl.Cursor())
+	view := tbl.View()
+	row, ok = components.TableRowAt(tbl, lineOf(view, "row-08"))
+	require.True(t, ok)
+	assert.Equal(t, 8, row)
+
+	assert.False(t, components.ScrollTable(&tbl, click(0, 0)))
+	assert.True(t, components.IsLeftClick(click(0, 0)))
+	assert.False(t, components.IsLeftClick(tea.MouseMsg{Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft}))
+}
+
+// TestWindowsMouse tests clicking windows and dragging the split between the tables
+func TestWindowsMouse(t *testing.T) {
+	t.Cleanup(func() { _, _ = config.Load("") })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("# my settings\ntui:\n  theme: dark # keep\n"), 0644))
+	_, err := config.Load(path)
+	require.NoError(t, err)
+
+	db := setupTestDB(t)
+	w := views.NewWindows(db, nil, nil)
+	w, _ = w.Update(tea.WindowSizeMsg{Width: 90, Height: 30})
+
+	var windows []database.TmuxWindow
+	for i := 0; i < 6; i++ {
+		windows = append(windows, database.TmuxWindow{
+			SessionName: "proj",
+			WindowIndex: i,
+			WindowName:  fmt.Sprintf("w%d", i),
+			Target:      fmt.Sprintf("proj:w%d", i),
+			LastSeen:    time.Now(),
+		})
+	}
+	w, _ = w.Update(types.RefreshDataMsg{Type: "windows", Data: windows})
+
+	// Clicking a row selects its window
+	y := lineOf(w.View(), "proj:w3")
+	require.GreaterOrEqual(t, y, 0)
+	w, _ = w.Update(click(5, y))
+	require.NotNil(t, w.SelectedWindow())
+	assert.Equal(t, "proj:w3", w.SelectedWindow().Target)
+
+	// Dragging the title of the queue table up shrinks the windows table
+	handle := lineOf(w.View(), "Message Queues")
+	require.Greater(t, handle, 0)
+	w, _ = w.Update(click(5, handle))
+	w, _ = w.Update(tea.MouseMsg{X: 5, Y: handle - 3, Action: tea.MouseActionMotion, Button: tea.MouseButtonLeft})
+	w, cmd := w.Update(tea.MouseMsg{X: 5, Y: handle - 3, Action: tea.MouseActionRelease})
+	assert.Equal(t, handle-3, lineOf(w.View(), "Message Queues"))
+
+	// The new split is saved to the config, keeping the rest of the file
+	require.NotNil(t, cmd)
+	assert.Nil(t, cmd())
+	split := config.GetTUIConfig().Layout.WindowsSplit
+	assert.Less(t, split, config.DefaultWindowsSplit)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# my settings")
+	assert.Contains(t, string(data), "theme: dark # keep")
+	assert.Contains(t, string(data), fmt.Sprintf("windows_split: %.2f", split))
+
+	_, err = config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, split, config.GetTUIConfig().Layout.WindowsSplit)
+
+	// A new view starts with the saved split
+	w = views.NewWindows(db, nil, nil)
+	w, _ = w.Update(tea.WindowSizeMsg{Width: 90, Height: 30})
+	assert.Equal(t, handle-3, lineOf(w.View(), "Message Queues"))
+}
+
+// TestMessagesMouse tests switching the messages tabs with a click
+func TestMessagesMouse(t *testing.T) {
+	m := views.NewMessages(nil, nil, nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	assert.Contains(t, m.View(), "Scheduled Messages")
+
+	tabs := strings.Split(m.View(), "\n")[0]
+	require.Contains(t, tabs, "Failed (0)")
+	m, _ = m.Update(click(runeIndex(tabs, "Failed"), 0))
+	assert.Contains(t, m.View(), "Failed Messages (0)")
+
+	m, _ = m.Update(click(1, 0))
+	assert.Contains(t, m.View(), "Scheduled Messages")
+}
+
+// TestAppMouse tests switching views from the header tabs
+func TestAppMouse(t *testing.T) {
+	app := tui.NewApp(nil, nil, nil, nil, nil)
+	model, _ := app.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+
+	header := strings.Split(model.View(), "\n")[0]
+	require.Contains(t, header, "Scheduler")
+	model, _ = model.Update(click(runeIndex(header, " Scheduler ")+1, 0))
+	assert.Contains(t, model.View(), "Processing Queue")
+
+	header = strings.Split(model.View(), "\n")[0]
+	model, _ = model.Update(click(runeIndex(header, "Windows"), 0))
+	assert.Contains(t, model.View(), "Discovered Windows")
+
+	// Clicks outside the tabs change nothing
+	model, _ = model.Update(click(2, 0))
+	assert.Contains(t, model.View(), "Discovered Windows")
+}
+
+// runeIndex returns the column of text in a line that may contain styling
+func runeIndex(line, text string) int {
+	plain := ansi.Strip(line)
+	return ansi.StringWidth(plain[:strings.Index(plain, text)])
+}
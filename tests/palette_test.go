@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// TestPalette tests fuzzy matching and picking items in the command palette
+func TestPalette(t *testing.T) {
+	palette := components.NewPalette()
+	palette.SetSize(100, 30)
+	palette.Open()
+	palette.SetItems([]components.PaletteItem{
+		{Kind: components.PaletteAction, Title: "Go to Dashboard", Detail: "1", Value: "dashboard"},
+		{Kind: components.PaletteAction, Title: "Pause sending", Value: "pause"},
+		{Kind: components.PaletteWindow, Title: "proj:api.0", Detail: "api • Claude", Value: "proj:api.0"},
+		{Kind: components.PaletteMessage, Title: "Please add api docs for pagination", Detail: "#7 proj:web.1 pending", ID: 7},
+	})
+
+	press := func(msgs ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, msg := range msgs {
+			palette, cmd = palette.Update(msg)
+		}
+		return cmd
+	}
+	typeText := func(text string) {
+		for _, r := range text {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	// Without a query every item is listed in order
+	require.Len(t, palette.Matches(), 4)
+	assert.Equal(t, "dashboard", palette.Matches()[0].Value)
+
+	// Fuzzy matches rank word starts and runs above scattered letters
+	typeText("pa0")
+	require.NotEmpty(t, palette.Matches())
+	assert.Equal(t, "proj:api.0", palette.Matches()[0].Value)
+
+	// Matching only the detail ranks below matching the title
+	press(tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace})
+	typeText("web")
+	require.Len(t, palette.Matches(), 1)
+	assert.Equal(t, uint(7), palette.Matches()[0].ID)
+	assert.Contains(t, palette.View(), "Please add api docs")
+
+	// Picking the highlighted item
+	cmd := press(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	pick, ok := cmd().(components.PalettePickMsg)
+	require.True(t, ok)
+	assert.Equal(t, components.PaletteMessage, pick.Item.Kind)
+
+	// Moving down and closing
+	palette.Open()
+	assert.Len(t, palette.Matches(), 4)
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	cmd = press(tea.KeyMsg{Type: tea.KeyEnter})
+	pick = cmd().(components.PalettePickMsg)
+	assert.Equal(t, "pause", pick.Item.Value)
+
+	cmd = press(tea.KeyMsg{Type: tea.KeyEsc})
+	_, ok = cmd().(components.PaletteCloseMsg)
+	assert.True(t, ok)
+
+	palette.Open()
+	typeText("zzz")
+	assert.Empty(t, palette.Matches())
+	assert.Contains(t, palette.View(), "No matches")
+}
+
+// TestPaletteSelection tests selecting windows and messages picked in the palette
+func TestPaletteSelection(t *testing.T) {
+	db := setupTestDB(t)
+
+	var windows []database.TmuxWindow
+	for i := 0; i < 4; i++ {
+		window := database.TmuxWindow{SessionName: "dev", WindowIndex: i, WindowName: fmt.Sprintf("w%d", i),
+			Target: fmt.Sprintf("dev:%d", i), Active: true, HasClaude: true}
+		require.NoError(t, db.Create(&window).Error)
+		windows = append(windows, window)
+	}
+
+	// The oldest message isn't among the latest 50 the messages view loads
+	now := time.Now()
+	var oldest database.Message
+	for i := 0; i < 60; i++ {
+		message := database.Message{WindowID: windows[i%4].ID, Content: fmt.Sprintf("message %d", i),
+			Priority: 5, ScheduledTime: now.Add(time.Duration(i) * time.Minute), Status: database.MessageStatusPending}
+		require.NoError(t, db.Create(&message).Error)
+		if i == 0 {
+			oldest = message
+		}
+	}
+	failed := database.Message{WindowID: windows[1].ID, Content: "broken", Priority: 5,
+		ScheduledTime: now.Add(-time.Hour), Status: database.MessageStatusFailed}
+	require.NoError(t, db.Create(&failed).Error)
+
+	// run executes a refresh command and returns the data messages it produced
+	var run func(cmd tea.Cmd) []tea.Msg
+	run = func(cmd tea.Cmd) []tea.Msg {
+		if cmd == nil {
+			return nil
+		}
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			var msgs []tea.Msg
+			for _, c := range msg {
+				msgs = append(msgs, run(c)...)
+			}
+			return msgs
+		case types.RefreshDataMsg:
+			return []tea.Msg{msg}
+		}
+		return nil
+	}
+
+	windowsView := views.NewWindows(db, nil, nil)
+	windowsView.SetSize(80, 30)
+	for _, msg := range run(windowsView.SelectWindow("dev:2")) {
+		windowsView, _ = windowsView.Update(msg)
+	}
+	require.NotNil(t, windowsView.SelectedWindow())
+	assert.Equal(t, "dev:2", windowsView.SelectedWindow().Target)
+
+	messagesView := views.NewMessages(db, nil, nil)
+	messagesView.SetSize(100, 30)
+	for _, msg := range run(messagesView.SelectMessage(oldest.ID, false)) {
+		messagesView, _ = messagesView.Update(msg)
+	}
+	require.NotNil(t, messagesView.SelectedMessage())
+	assert.Equal(t, oldest.ID, messagesView.SelectedMessage().ID)
+
+	for _, msg := range run(messagesView.SelectMessage(failed.ID, true)) {
+		messagesView, _ = messagesView.Update(msg)
+	}
+	require.NotNil(t, messagesView.SelectedMessage())
+	assert.Equal(t, failed.ID, messagesView.SelectedMessage().ID)
+}
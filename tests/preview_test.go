@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/tmux"
+	"github.com/derekxwang/tcs/internal/tui/views"
+	"github.com/derekxwang/tcs/internal/types"
+)
+
+// previewTmuxClient adds the preview stream and window switching to TestTmuxClient
+type previewTmuxClient struct {
+	*TestTmuxClient
+	monitored []string
+	switched  string
+}
+
+func (t *previewTmuxClient) MonitorWindowContext(ctx context.Context, target string, interval time.Duration, opts tmux.MonitorOptions) (<-chan string, error) {
+	t.monitored = append(t.monitored, target)
+	updates := make(chan string, 1)
+	updates <- "\x1b[32m✻ Working in " + target + "\x1b[0m\n"
+	go func() {
+		<-ctx.Done()
+		close(updates)
+	}()
+	return updates, nil
+}
+
+func (t *previewTmuxClient) SwitchClient(target string) error {
+	t.switched = target
+	return nil
+}
+
+// TestWindowsPreview tests that the preview follows the highlighted window
+func TestWindowsPreview(t *testing.T) {
+	db := setupForceTestDB(t)
+	testTmux := &previewTmuxClient{TestTmuxClient: NewTestTmuxClient()}
+
+	windows := []database.TmuxWindow{
+		{SessionName: "preview", WindowIndex: 0, Target: "preview:0", Active: true},
+		{SessionName: "preview", WindowIndex: 1, Target: "preview:1", Active: true},
+	}
+	require.NoError(t, db.Create(&windows).Error)
+
+	w := views.NewWindows(db, nil, testTmux)
+	w.SetSize(140, 40)
+
+	// stream starts the preview and delivers its first content. The command
+	// returned after that waits for more content, which never comes.
+	stream := func(cmd tea.Cmd) {
+		require.NotNil(t, cmd)
+		w, cmd = w.Update(cmd()) // stream started
+		require.NotNil(t, cmd)
+		w, _ = w.Update(cmd()) // first content
+	}
+
+	var cmd tea.Cmd
+	w, cmd = w.Update(types.RefreshDataMsg{Type: "windows", Data: windows})
+	stream(cmd)
+	assert.Equal(t, []string{"preview:0"}, testTmux.monitored)
+	assert.Contains(t, w.View(), "Working in preview:0")
+
+	// Refreshing keeps the stream while the selection is unchanged
+	_, cmd = w.Update(types.RefreshDataMsg{Type: "windows", Data: windows})
+	assert.Nil(t, cmd)
+
+	// Moving down streams the next window instead
+	w, cmd = w.Update(tea.KeyMsg{Type: tea.KeyDown})
+	stream(cmd)
+	assert.Equal(t, []string{"preview:0", "preview:1"}, testTmux.monitored)
+	assert.Contains(t, w.View(), "Working in preview:1")
+
+	// Jumping switches the tmux client when running inside tmux
+	t.Setenv("TMUX", "/tmp/tmux-test/default,1,0")
+	_, cmd = w.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	cmd()
+	assert.Equal(t, "preview:1", testTmux.switched)
+
+	// Leaving the view stops the stream, showing it again restarts it
+	w.StopPreview()
+	assert.NotContains(t, w.View(), "Working in")
+	for _, refresh := range w.Refresh()().(tea.BatchMsg) {
+		if msg, ok := refresh().(views.WindowPreviewMsg); ok {
+			stream(func() tea.Msg { return msg })
+		}
+	}
+	assert.Equal(t, []string{"preview:0", "preview:1", "preview:1"}, testTmux.monitored)
+	assert.Contains(t, w.View(), "Working in preview:1")
+
+	// Hiding the preview stops the stream
+	w, cmd = w.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	assert.Nil(t, cmd)
+	assert.NotContains(t, w.View(), "Preview ·")
+}
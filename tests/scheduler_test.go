@@ -1,6 +1,9 @@
 package tests
 
 import (
+	"errors"
+	"fmt"
+	"os/exec"
 	"testing"
 	"time"
 
@@ -9,6 +12,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/derekxwang/tcs/internal/config"
 	"github.com/derekxwang/tcs/internal/database"
 	"github.com/derekxwang/tcs/internal/monitor"
 	"github.com/derekxwang/tcs/internal/scheduler"
@@ -25,7 +29,12 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&database.TmuxWindow{},
 		&database.WindowMessageQueue{},
 		&database.Message{},
+		&database.MessageAttempt{},
 		&database.UsageWindow{},
+		&database.WindowGroup{},
+		&database.SchedulerState{},
+		&database.Event{},
+		&database.DaemonRequest{},
 	)
 	require.NoError(t, err)
 
@@ -407,3 +416,880 @@ func TestSchedulerShutdown(t *testing.T) {
 	err = s.Stop()
 	assert.NoError(t, err)
 }
+
+// TestScheduleRoutedMessage tests routing messages by model and permission mode
+func TestScheduleRoutedMessage(t *testing.T) {
+	db := setupTestDB(t)
+
+	usageMonitor := monitor.NewUsageMonitor(db)
+	require.NoError(t, usageMonitor.Initialize())
+
+	windows := []*database.TmuxWindow{
+		{SessionName: "route", WindowIndex: 0, Target: "route:0", HasClaude: true, Active: true,
+			ClaudeModel: "claude-sonnet-4-5", ClaudePermissionMode: "default"},
+		{SessionName: "route", WindowIndex: 1, Target: "route:1", HasClaude: true, Active: true,
+			ClaudeModel: "Opus 4.1", ClaudePermissionMode: "plan", ClaudeVersion: "1.0.88"},
+		{SessionName: "route", WindowIndex: 2, Target: "route:2", HasClaude: false, Active: true,
+			ClaudeModel: "opus"},
+	}
+	for _, window := range windows {
+		require.NoError(t, db.Create(window).Error)
+	}
+
+	s := scheduler.NewScheduler(db, tmux.NewClient(), usageMonitor, nil)
+	require.NoError(t, s.Initialize())
+
+	msg, err := s.ScheduleMessage("any:model=Opus,mode=plan", "Routed message", time.Now().Add(time.Hour), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "any:model=opus,mode=plan", msg.Route)
+	assert.Equal(t, windows[1].ID, msg.WindowID, "provisional window should match the route")
+
+	route, err := scheduler.ParseRoute("any:model=sonnet")
+	require.NoError(t, err)
+	window, err := scheduler.ResolveRoute(db, nil, route)
+	require.NoError(t, err)
+	assert.Equal(t, "route:0", window.Target)
+
+	// Windows without Claude never match
+	route, err = scheduler.ParseRoute("any:model=opus,version=1.1")
+	require.NoError(t, err)
+	_, err = scheduler.ResolveRoute(db, nil, route)
+	assert.ErrorIs(t, err, scheduler.ErrNoRouteMatch)
+
+	_, err = scheduler.ParseRoute("any:model=opus,color=blue")
+	assert.Error(t, err)
+	_, err = scheduler.ParseRoute("any:mode=sideways")
+	assert.Error(t, err)
+}
+
+// TestDispatchRoutedMessage tests that a routed message is picked up for sending
+func TestDispatchRoutedMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no Claude usage recorded, so sending is allowed
+	db := setupTestDB(t)
+
+	usageMonitor := monitor.NewUsageMonitor(db)
+	require.NoError(t, usageMonitor.Initialize())
+
+	// A window created without a queue, as discovery leaves new windows
+	window := &database.TmuxWindow{SessionName: "route", WindowIndex: 0, Target: "route:0",
+		HasClaude: true, Active: true, ClaudeModel: "opus"}
+	require.NoError(t, db.Create(window).Error)
+
+	s := scheduler.NewScheduler(db, tmux.NewClient(), usageMonitor, nil)
+	require.NoError(t, s.Initialize())
+
+	dispatched := make(chan uint, 10)
+	s.AddMessageCallback(func(event *scheduler.MessageEvent) {
+		if event.Type == "processing" {
+			dispatched <- event.Message.ID
+		}
+	})
+
+	msg, err := s.ScheduleMessage("any:model=opus", "Routed message", time.Now().Add(-time.Second), 5)
+	require.NoError(t, err)
+
+	pending, err := database.GetPendingMessagesForAllWindows(db, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, msg.ID, pending[0].ID)
+
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Stop() })
+	s.TriggerImmediateProcessing()
+
+	select {
+	case id := <-dispatched:
+		assert.Equal(t, msg.ID, id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("routed message was not dispatched")
+	}
+}
+
+// TestGroupRouting tests picking the least busy member of a window group
+func TestGroupRouting(t *testing.T) {
+	db := setupTestDB(t)
+
+	earlier := time.Now().Add(-time.Hour)
+	windows := []*database.TmuxWindow{
+		{SessionName: "pool", WindowIndex: 0, Target: "pool:0", HasClaude: true, Active: true, Priority: 5},
+		{SessionName: "pool", WindowIndex: 1, Target: "pool:1", HasClaude: true, Active: true, Priority: 5, LastActivity: &earlier},
+		{SessionName: "pool", WindowIndex: 2, Target: "pool:2", HasClaude: true, Active: true, Priority: 5},
+		{SessionName: "other", WindowIndex: 0, Target: "other:0", HasClaude: true, Active: true, Priority: 9},
+	}
+	for _, window := range windows {
+		require.NoError(t, db.Create(window).Error)
+	}
+
+	group, err := database.CreateWindowGroup(db, "backend", "")
+	require.NoError(t, err)
+	for _, window := range windows[:3] {
+		require.NoError(t, database.AddWindowToGroup(db, group, window))
+	}
+
+	route, err := scheduler.ParseRoute("group:backend")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", route.Group)
+	assert.True(t, route.Idle)
+	assert.Equal(t, "group:backend", route.String())
+
+	// Never-used windows go first
+	window, err := scheduler.ResolveRoute(db, nil, route)
+	require.NoError(t, err)
+	assert.Equal(t, "pool:0", window.Target)
+
+	// Queue depth outweighs recency
+	for i := 0; i < 2; i++ {
+		require.NoError(t, db.Create(&database.Message{WindowID: windows[0].ID, Content: "queued",
+			ScheduledTime: time.Now().Add(-time.Minute), Status: database.MessageStatusPending}).Error)
+	}
+	window, err = scheduler.ResolveRoute(db, nil, route)
+	require.NoError(t, err)
+	assert.Equal(t, "pool:2", window.Target)
+
+	// Window priority breaks ties in queue depth
+	require.NoError(t, db.Model(windows[1]).Update("priority", 8).Error)
+	window, err = scheduler.ResolveRoute(db, nil, route)
+	require.NoError(t, err)
+	assert.Equal(t, "pool:1", window.Target)
+
+	// Removed members are no longer picked
+	require.NoError(t, database.RemoveWindowFromGroup(db, group, windows[1]))
+	window, err = scheduler.ResolveRoute(db, nil, route)
+	require.NoError(t, err)
+	assert.Equal(t, "pool:2", window.Target)
+
+	route, err = scheduler.ParseRoute("group:missing")
+	require.NoError(t, err)
+	_, err = scheduler.ResolveRoute(db, nil, route)
+	assert.Error(t, err)
+}
+
+func TestWindowQueueLimits(t *testing.T) {
+	db := setupTestDB(t)
+
+	window := &database.TmuxWindow{SessionName: "limits", WindowIndex: 0, Target: "limits:0", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(window).Error)
+	other := &database.TmuxWindow{SessionName: "limits", WindowIndex: 1, Target: "limits:1", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(other).Error)
+
+	// One message in flight per window
+	acquired, err := database.AcquireQueueSlot(db, window.ID)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = database.AcquireQueueSlot(db, window.ID)
+	require.NoError(t, err)
+	assert.False(t, acquired, "second claim should fail while a message is in flight")
+
+	// Routes skip the busy window
+	route, err := scheduler.ParseRoute("any:")
+	require.NoError(t, err)
+	picked, err := scheduler.ResolveRoute(db, nil, route)
+	require.NoError(t, err)
+	assert.Equal(t, "limits:1", picked.Target)
+
+	require.NoError(t, database.ReleaseQueueSlot(db, window.ID, true))
+	acquired, err = database.AcquireQueueSlot(db, window.ID)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, database.ReleaseQueueSlot(db, window.ID, true))
+
+	// Minimum spacing since the last processed message
+	queue, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(queue).Update("min_spacing", time.Hour).Error)
+
+	acquired, err = database.AcquireQueueSlot(db, window.ID)
+	require.NoError(t, err)
+	assert.False(t, acquired, "claim should wait out the minimum spacing")
+
+	queue, err = database.GetOrCreateWindowMessageQueue(db, window.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "spacing", queue.State())
+
+	require.NoError(t, db.Model(queue).Update("min_spacing", 0).Error)
+
+	// Paused queues keep their messages pending
+	require.NoError(t, database.SetQueueActive(db, window.ID, false))
+	acquired, err = database.AcquireQueueSlot(db, window.ID)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	message := &database.Message{WindowID: window.ID, Content: "held", ScheduledTime: time.Now().Add(-time.Minute),
+		Status: database.MessageStatusPending}
+	require.NoError(t, db.Create(message).Error)
+
+	pending, err := database.GetPendingMessagesForAllWindows(db, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	require.NoError(t, database.SetQueueActive(db, window.ID, true))
+	pending, err = database.GetPendingMessagesForAllWindows(db, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, message.ID, pending[0].ID)
+}
+
+func TestQueueMessageCounts(t *testing.T) {
+	db := setupTestDB(t)
+
+	usageMonitor := monitor.NewUsageMonitor(db)
+	require.NoError(t, usageMonitor.Initialize())
+	sched := scheduler.NewScheduler(db, tmux.NewClient(), usageMonitor, nil)
+	require.NoError(t, sched.Initialize())
+
+	message, err := sched.ScheduleMessage("counts:0", "first", time.Now().Add(time.Hour), 5)
+	require.NoError(t, err)
+	_, err = database.GetOrCreateWindowMessageQueue(db, message.WindowID)
+	require.NoError(t, err)
+
+	_, err = sched.ScheduleMessage("counts:0", "second", time.Now().Add(time.Hour), 5)
+	require.NoError(t, err)
+
+	queue, err := database.GetOrCreateWindowMessageQueue(db, message.WindowID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, queue.MessageCount)
+
+	require.NoError(t, database.UpdateMessageStatus(db, message.ID, database.MessageStatusSent, ""))
+	queue, err = database.GetOrCreateWindowMessageQueue(db, message.WindowID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queue.MessageCount)
+
+	// Counts drift when messages change behind the scheduler's back
+	require.NoError(t, db.Model(queue).Update("message_count", 42).Error)
+	require.NoError(t, database.SyncQueueMessageCounts(db))
+	queue, err = database.GetOrCreateWindowMessageQueue(db, message.WindowID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queue.MessageCount)
+}
+
+func TestClaimMessageOnce(t *testing.T) {
+	db := setupTestDB(t)
+
+	window := &database.TmuxWindow{SessionName: "claim", WindowIndex: 0, Target: "claim:0", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(window).Error)
+	message := &database.Message{WindowID: window.ID, Content: "once", ScheduledTime: time.Now(),
+		Status: database.MessageStatusPending}
+	require.NoError(t, db.Create(message).Error)
+
+	claimed, err := database.ClaimMessage(db, message.ID, "host-a:1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = database.ClaimMessage(db, message.ID, "host-b:2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a message can only be claimed once")
+
+	pending, err := database.GetPendingMessagesForAllWindows(db, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	inFlight, err := database.GetInFlightMessages(db, window.ID)
+	require.NoError(t, err)
+	require.Len(t, inFlight, 1)
+	assert.Equal(t, "host-a:1", inFlight[0].LeaseOwner)
+	require.NotNil(t, inFlight[0].LeaseExpiresAt)
+
+	// Live leases are left alone, expired ones are reclaimed
+	reclaimed, err := database.ReclaimExpiredLeases(db)
+	require.NoError(t, err)
+	assert.Zero(t, reclaimed)
+
+	require.NoError(t, db.Model(&database.Message{}).Where("id = ?", message.ID).
+		Update("lease_expires_at", time.Now().Add(-time.Second)).Error)
+	reclaimed, err = database.ReclaimExpiredLeases(db)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reclaimed)
+
+	claimed, err = database.ClaimMessage(db, message.ID, "host-b:2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Only the lease owner can hand the message back
+	require.NoError(t, database.ReleaseMessageLease(db, message.ID, "host-a:1"))
+	inFlight, err = database.GetInFlightMessages(db, 0)
+	require.NoError(t, err)
+	assert.Len(t, inFlight, 1)
+
+	require.NoError(t, database.ReleaseMessageLease(db, message.ID, "host-b:2"))
+	inFlight, err = database.GetInFlightMessages(db, 0)
+	require.NoError(t, err)
+	assert.Empty(t, inFlight)
+
+	// Finishing a message drops its lease
+	_, err = database.ClaimMessage(db, message.ID, "host-a:1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, database.UpdateMessageStatus(db, message.ID, database.MessageStatusSent, ""))
+	var sent database.Message
+	require.NoError(t, db.First(&sent, message.ID).Error)
+	assert.Empty(t, sent.LeaseOwner)
+	assert.Nil(t, sent.LeaseExpiresAt)
+}
+
+func TestSchedulingPolicies(t *testing.T) {
+	now := time.Now()
+	messages := func() []database.Message {
+		return []database.Message{
+			{Model: gorm.Model{ID: 1}, WindowID: 1, Priority: 9, ScheduledTime: now.Add(-time.Minute)},
+			{Model: gorm.Model{ID: 2}, WindowID: 1, Priority: 8, ScheduledTime: now.Add(-2 * time.Minute)},
+			{Model: gorm.Model{ID: 3}, WindowID: 2, Priority: 5, ScheduledTime: now.Add(-time.Hour)},
+		}
+	}
+	ids := func(messages []database.Message) []uint {
+		var ids []uint
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+		}
+		return ids
+	}
+
+	_, err := scheduler.NewPolicy(nil, "lottery", 0)
+	assert.Error(t, err)
+	_, err = scheduler.NewPolicy(nil, "fair", 0)
+	assert.Error(t, err, "only the names the config accepts")
+	_, err = scheduler.NewPolicy(nil, scheduler.PolicyPaced, 0)
+	assert.Error(t, err)
+
+	// Fair-share is the default
+	policy, err := scheduler.NewPolicy(nil, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, scheduler.PolicyFairShare, policy.Name())
+
+	// Priority keeps the database order
+	policy, err = scheduler.NewPolicy(nil, scheduler.PolicyPriority, 0)
+	require.NoError(t, err)
+	ordered := messages()
+	policy.Order(ordered)
+	assert.Equal(t, []uint{1, 2, 3}, ids(ordered))
+
+	// Deadline sends the longest due first
+	policy, err = scheduler.NewPolicy(nil, scheduler.PolicyDeadline, 0)
+	require.NoError(t, err)
+	ordered = messages()
+	policy.Order(ordered)
+	assert.Equal(t, []uint{3, 2, 1}, ids(ordered))
+
+	// Paced sends one message per interval
+	policy, err = scheduler.NewPolicy(nil, scheduler.PolicyPaced, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, policy.Budget(now, 3))
+	policy.Dispatched(&ordered[0], now)
+	assert.Equal(t, 0, policy.Budget(now.Add(30*time.Second), 3))
+	assert.Equal(t, 1, policy.Budget(now.Add(time.Minute), 3))
+}
+
+func TestFairShareScheduling(t *testing.T) {
+	db := setupTestDB(t)
+
+	busy := &database.TmuxWindow{SessionName: "wfq", WindowIndex: 0, Target: "wfq:0", HasClaude: true, Active: true, Priority: 8}
+	quiet := &database.TmuxWindow{SessionName: "wfq", WindowIndex: 1, Target: "wfq:1", HasClaude: true, Active: true, Priority: 4}
+	require.NoError(t, db.Create(busy).Error)
+	require.NoError(t, db.Create(quiet).Error)
+	for _, window := range []*database.TmuxWindow{busy, quiet} {
+		_, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+		require.NoError(t, err)
+	}
+
+	// The busy window already sent 3 messages and has a long high priority backlog
+	sentAt := time.Now().Add(-time.Minute)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Create(&database.Message{WindowID: busy.ID, Content: "sent", ScheduledTime: sentAt,
+			Status: database.MessageStatusSent, SentAt: &sentAt}).Error)
+	}
+	for i := 0; i < 5; i++ {
+		require.NoError(t, db.Create(&database.Message{WindowID: busy.ID, Content: "urgent", Priority: 10,
+			ScheduledTime: sentAt, Status: database.MessageStatusPending}).Error)
+	}
+	require.NoError(t, db.Create(&database.Message{WindowID: quiet.ID, Content: "routine", Priority: 1,
+		ScheduledTime: sentAt, Status: database.MessageStatusPending}).Error)
+
+	shares, _, err := scheduler.FairShares(db)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+
+	// Weights 8 and 4 split 3 sends 2:1, so the quiet window is owed one
+	assert.Equal(t, quiet.ID, shares[0].Window.ID)
+	assert.InDelta(t, 1.0/3, shares[0].Share, 0.001)
+	assert.InDelta(t, 1.0, shares[0].Deficit, 0.001)
+	assert.Equal(t, 1, shares[0].Pending)
+	assert.Equal(t, busy.ID, shares[1].Window.ID)
+	assert.Equal(t, 3, shares[1].Sent)
+	assert.InDelta(t, -1.0, shares[1].Deficit, 0.001)
+
+	// The quiet window goes first despite its lower message priority
+	pending, err := database.GetPendingMessagesForAllWindows(db, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 6)
+	assert.Equal(t, busy.ID, pending[0].WindowID)
+
+	policy, err := scheduler.NewPolicy(db, scheduler.PolicyFairShare, 0)
+	require.NoError(t, err)
+	policy.Order(pending)
+	assert.Equal(t, quiet.ID, pending[0].WindowID)
+
+	// A window without a backlog doesn't take a share from the others
+	idle := &database.TmuxWindow{SessionName: "wfq", WindowIndex: 2, Target: "wfq:2", HasClaude: true, Active: true, Priority: 8}
+	require.NoError(t, db.Create(idle).Error)
+	for i := 0; i < 6; i++ {
+		require.NoError(t, db.Create(&database.Message{WindowID: idle.ID, Content: "done", ScheduledTime: sentAt,
+			Status: database.MessageStatusSent, SentAt: &sentAt}).Error)
+	}
+
+	shares, _, err = scheduler.FairShares(db)
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+	assert.Equal(t, quiet.ID, shares[0].Window.ID)
+	assert.InDelta(t, 1.0/3, shares[0].Share, 0.001)
+	assert.InDelta(t, 1.0, shares[0].Deficit, 0.001)
+	assert.Equal(t, busy.ID, shares[1].Window.ID)
+	assert.InDelta(t, 2.0/3, shares[1].Share, 0.001)
+	assert.Equal(t, idle.ID, shares[2].Window.ID)
+	assert.Zero(t, shares[2].Share)
+	assert.Zero(t, shares[2].Deficit)
+	assert.Equal(t, 6, shares[2].Sent)
+}
+
+func TestMessageExpiry(t *testing.T) {
+	db := setupTestDB(t)
+
+	usageMonitor := monitor.NewUsageMonitor(db)
+	require.NoError(t, usageMonitor.Initialize())
+	sched := scheduler.NewScheduler(db, tmux.NewClient(), usageMonitor, nil)
+	require.NoError(t, sched.Initialize())
+
+	due := time.Now().Add(time.Hour)
+	tooEarly := due.Add(-time.Minute)
+	_, err := sched.ScheduleMessageWithExpiry("standup:0", "summary", due, 5, &tooEarly)
+	assert.Error(t, err, "a message cannot expire before it is due")
+
+	deadline := due.Add(30 * time.Minute)
+	message, err := sched.ScheduleMessageWithExpiry("standup:0", "summary", due, 5, &deadline)
+	require.NoError(t, err)
+	require.NotNil(t, message.ExpiresAt)
+	assert.False(t, message.IsExpired())
+
+	// Overdue messages expire instead of being claimed
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, db.Model(message).Updates(map[string]interface{}{
+		"scheduled_time": past.Add(-time.Hour),
+		"expires_at":     past,
+	}).Error)
+
+	claimed, err := database.ClaimMessage(db, message.ID, "host:1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	expired, err := database.ExpireOverdueMessages(db)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, message.ID, expired[0].ID)
+
+	var stored database.Message
+	require.NoError(t, db.First(&stored, message.ID).Error)
+	assert.Equal(t, database.MessageStatusExpired, stored.Status)
+
+	expired, err = database.ExpireOverdueMessages(db)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+}
+
+// TestExpiryWithoutUsage tests that deadlines pass while usage is exhausted
+func TestExpiryWithoutUsage(t *testing.T) {
+	db := setupTestDB(t)
+
+	// An uninitialized monitor has no usage window, so no usage is available
+	usageMonitor := monitor.NewUsageMonitor(db)
+	require.Zero(t, usageMonitor.GetAvailableUsage())
+	sched := scheduler.NewScheduler(db, tmux.NewClient(), usageMonitor, nil)
+	require.NoError(t, sched.Initialize())
+
+	expired := make(chan uint, 10)
+	sched.AddMessageCallback(func(event *scheduler.MessageEvent) {
+		if event.Type == "expired" {
+			expired <- event.Message.ID
+		}
+	})
+
+	past := time.Now().Add(-time.Minute)
+	deadline := past.Add(30 * time.Second)
+	message, err := sched.ScheduleMessageWithExpiry("standup:0", "summary", past, 5, &deadline)
+	require.NoError(t, err)
+
+	require.NoError(t, sched.Start())
+	t.Cleanup(func() { _ = sched.Stop() })
+	sched.TriggerImmediateProcessing()
+
+	select {
+	case id := <-expired:
+		assert.Equal(t, message.ID, id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("overdue message did not expire")
+	}
+
+	var stored database.Message
+	require.NoError(t, db.First(&stored, message.ID).Error)
+	assert.Equal(t, database.MessageStatusExpired, stored.Status)
+}
+
+func TestEarliestDeadlineFirst(t *testing.T) {
+	db := setupTestDB(t)
+
+	window := &database.TmuxWindow{SessionName: "edf", WindowIndex: 0, Target: "edf:0", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(window).Error)
+	_, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+	require.NoError(t, err)
+
+	due := time.Now().Add(-time.Hour)
+	later := time.Now().Add(2 * time.Hour)
+	sooner := time.Now().Add(time.Hour)
+	messages := []*database.Message{
+		{WindowID: window.ID, Content: "no deadline", Priority: 5, ScheduledTime: due.Add(-time.Minute)},
+		{WindowID: window.ID, Content: "later", Priority: 5, ScheduledTime: due, ExpiresAt: &later},
+		{WindowID: window.ID, Content: "sooner", Priority: 5, ScheduledTime: due, ExpiresAt: &sooner},
+		{WindowID: window.ID, Content: "urgent", Priority: 9, ScheduledTime: due},
+	}
+	for _, message := range messages {
+		message.Status = database.MessageStatusPending
+		require.NoError(t, db.Create(message).Error)
+	}
+
+	contents := func(messages []database.Message) []string {
+		var contents []string
+		for _, message := range messages {
+			contents = append(contents, message.Content)
+		}
+		return contents
+	}
+
+	// Deadlines break ties between equal priorities
+	pending, err := database.GetPendingMessagesForAllWindows(db, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent", "sooner", "later", "no deadline"}, contents(pending))
+
+	// The deadline policy puts deadlines before priority
+	policy, err := scheduler.NewPolicy(db, scheduler.PolicyDeadline, 0)
+	require.NoError(t, err)
+	policy.Order(pending)
+	assert.Equal(t, []string{"sooner", "later", "no deadline", "urgent"}, contents(pending))
+}
+
+func TestRetryPolicies(t *testing.T) {
+	// Wrapped tmux errors keep their class
+	assert.Equal(t, scheduler.ErrorClassTmuxDown, scheduler.ClassifyError(fmt.Errorf("invalid target: %w", tmux.ErrTmuxNotRunning)))
+	assert.Equal(t, scheduler.ErrorClassTargetNotFound, scheduler.ClassifyError(fmt.Errorf("%w: window 3 does not exist", tmux.ErrTargetNotFound)))
+	assert.Equal(t, scheduler.ErrorClassPaneBusy, scheduler.ClassifyError(fmt.Errorf("%w: Claude is working", tmux.ErrPaneBusy)))
+	assert.Equal(t, scheduler.ErrorClassOther, scheduler.ClassifyError(errors.New("send-keys failed")))
+
+	backoff := scheduler.RetryPolicy{Backoff: 10 * time.Second, MaxBackoff: time.Minute, Multiplier: 2, MaxAttempts: 5}
+	assert.Equal(t, 10*time.Second, backoff.Delay(1))
+	assert.Equal(t, 20*time.Second, backoff.Delay(2))
+	assert.Equal(t, 40*time.Second, backoff.Delay(3))
+	assert.Equal(t, time.Minute, backoff.Delay(4), "delay is capped at the max backoff")
+	assert.True(t, backoff.CanRetry(4))
+	assert.False(t, backoff.CanRetry(5))
+
+	backoff.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := backoff.Delay(1)
+		assert.GreaterOrEqual(t, delay, 5*time.Second)
+		assert.LessOrEqual(t, delay, 15*time.Second)
+	}
+
+	db := setupTestDB(t)
+	window := &database.TmuxWindow{SessionName: "retry", WindowIndex: 0, Target: "retry:0", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(window).Error)
+
+	policies := scheduler.RetryPolicies{
+		scheduler.ErrorClassPaneBusy:       {Backoff: time.Minute, Multiplier: 2, MaxAttempts: 3, DeadLetter: true},
+		scheduler.ErrorClassTargetNotFound: {MaxAttempts: 1, DeadLetter: false},
+	}
+	busy := fmt.Errorf("%w: Claude is working in retry:0", tmux.ErrPaneBusy)
+
+	message := &database.Message{WindowID: window.ID, Content: "hello", Priority: 5, ScheduledTime: time.Now(), Status: database.MessageStatusSending}
+	require.NoError(t, db.Create(message).Error)
+	message.Window = *window
+
+	// Busy panes are retried with a growing backoff, then dead-lettered
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now()
+		record, err := scheduler.RecordFailedAttempt(db, policies, message, busy, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, attempt, record.Attempt)
+		assert.Equal(t, scheduler.ErrorClassPaneBusy, record.ErrorClass)
+
+		require.NoError(t, db.First(message, message.ID).Error)
+		message.Window = *window
+		if attempt < 3 {
+			assert.Equal(t, database.AttemptOutcomeRetry, record.Outcome)
+			assert.Equal(t, database.MessageStatusPending, message.Status)
+			assert.WithinDuration(t, before.Add(policies.For(scheduler.ErrorClassPaneBusy).Delay(attempt)), message.ScheduledTime, time.Second)
+		} else {
+			assert.Equal(t, database.AttemptOutcomeDeadLetter, record.Outcome)
+			assert.Equal(t, database.MessageStatusFailed, message.Status)
+			assert.Nil(t, record.NextRetryAt)
+		}
+	}
+
+	attempts, err := database.GetMessageAttempts(db, message.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 3)
+	assert.Equal(t, 3, attempts[2].Attempt)
+	assert.Equal(t, 3, message.Retries)
+
+	// Without dead-lettering a message that runs out of attempts is dropped
+	gone := &database.Message{WindowID: window.ID, Content: "bye", Priority: 5, ScheduledTime: time.Now(), Status: database.MessageStatusSending}
+	require.NoError(t, db.Create(gone).Error)
+	gone.Window = *window
+	record, err := scheduler.RecordFailedAttempt(db, policies, gone, fmt.Errorf("%w: window 0", tmux.ErrTargetNotFound), 0)
+	require.NoError(t, err)
+	assert.Equal(t, database.AttemptOutcomeDropped, record.Outcome)
+	assert.ErrorIs(t, db.First(&database.Message{}, gone.ID).Error, gorm.ErrRecordNotFound)
+
+	// Unknown classes fall back to the policy for other errors
+	assert.Equal(t, scheduler.RetryPoliciesFromConfig(config.DefaultRetryConfig())[scheduler.ErrorClassOther], policies.For(scheduler.ErrorClassOther))
+}
+
+func TestDeadLetterRequeue(t *testing.T) {
+	db := setupTestDB(t)
+
+	first := &database.TmuxWindow{SessionName: "dlq", WindowIndex: 0, Target: "dlq:0", HasClaude: true, Active: true}
+	second := &database.TmuxWindow{SessionName: "dlq", WindowIndex: 1, Target: "dlq:1", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(first).Error)
+	require.NoError(t, db.Create(second).Error)
+	for _, window := range []*database.TmuxWindow{first, second} {
+		_, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+		require.NoError(t, err)
+	}
+
+	policies := scheduler.RetryPolicies{scheduler.ErrorClassOther: {MaxAttempts: 1, DeadLetter: true}}
+	past := time.Now().Add(-time.Hour)
+	var failed []*database.Message
+	for i, content := range []string{"one", "two", "three"} {
+		message := &database.Message{WindowID: first.ID, Content: content, Priority: 5, ScheduledTime: past, Status: database.MessageStatusSending}
+		if i == 0 {
+			message.ExpiresAt = &past // the deadline passed while it was failing
+		}
+		require.NoError(t, db.Create(message).Error)
+		message.Window = *first
+		_, err := scheduler.RecordFailedAttempt(db, policies, message, errors.New("send-keys failed"), 0)
+		require.NoError(t, err)
+		failed = append(failed, message)
+	}
+	pending := &database.Message{WindowID: first.ID, Content: "pending", Priority: 5, ScheduledTime: time.Now().Add(time.Hour), Status: database.MessageStatusPending}
+	require.NoError(t, db.Create(pending).Error)
+
+	messages, err := database.GetFailedMessages(db, first.ID)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, "dlq:0", messages[0].Window.Target)
+
+	attempts, err := database.GetMessageAttempts(db, failed[0].ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, database.AttemptOutcomeDeadLetter, attempts[0].Outcome)
+
+	// Requeue one message to another window; only failed messages are touched
+	requeued, err := database.RequeueFailedMessages(db, []uint{failed[0].ID, pending.ID}, second.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+
+	var stored database.Message
+	require.NoError(t, db.First(&stored, failed[0].ID).Error)
+	assert.Equal(t, database.MessageStatusPending, stored.Status)
+	assert.Equal(t, second.ID, stored.WindowID)
+	assert.Zero(t, stored.Retries)
+	assert.Empty(t, stored.Error)
+	assert.Nil(t, stored.ExpiresAt, "a passed deadline is dropped on requeue")
+	assert.WithinDuration(t, time.Now(), stored.ScheduledTime, time.Minute)
+
+	queue, err := database.GetOrCreateWindowMessageQueue(db, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queue.MessageCount)
+
+	// Bulk delete the rest of the dead-letter queue
+	deleted, err := database.DeleteFailedMessages(db, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	messages, err = database.GetFailedMessages(db, 0)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	var untouched database.Message
+	require.NoError(t, db.First(&untouched, pending.ID).Error)
+	assert.Equal(t, database.MessageStatusPending, untouched.Status)
+}
+
+func TestQuietHoursAndPause(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	periods, err := scheduler.ParseQuietHours([]config.QuietHours{
+		{Name: "night", Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"},
+		{Name: "weekend lunch", Start: "12:00", End: "13:30", Days: []string{"sat", "sun"}, Timezone: "UTC"},
+	})
+	require.NoError(t, err)
+	night, lunch := periods[0], periods[1]
+
+	// Overnight periods run past midnight in their own timezone
+	end, active := night.ActiveAt(time.Date(2025, 3, 14, 23, 0, 0, 0, berlin))
+	assert.True(t, active)
+	assert.True(t, end.Equal(time.Date(2025, 3, 15, 7, 0, 0, 0, berlin)))
+	end, active = night.ActiveAt(time.Date(2025, 3, 15, 5, 0, 0, 0, time.UTC)) // 06:00 in Berlin
+	assert.True(t, active)
+	assert.True(t, end.Equal(time.Date(2025, 3, 15, 7, 0, 0, 0, berlin)))
+	_, active = night.ActiveAt(time.Date(2025, 3, 15, 12, 0, 0, 0, berlin))
+	assert.False(t, active)
+
+	// Day filters apply to the day a period starts on (2025-03-15 is a Saturday)
+	_, active = lunch.ActiveAt(time.Date(2025, 3, 15, 12, 30, 0, 0, time.UTC))
+	assert.True(t, active)
+	_, active = lunch.ActiveAt(time.Date(2025, 3, 14, 12, 30, 0, 0, time.UTC))
+	assert.False(t, active)
+	_, active = lunch.ActiveAt(time.Date(2025, 3, 15, 13, 30, 0, 0, time.UTC))
+	assert.False(t, active, "the end time is not quiet")
+
+	_, err = scheduler.ParseQuietHours([]config.QuietHours{{Start: "22:00", End: "07:00", Days: []string{"someday"}}})
+	assert.Error(t, err)
+
+	// Without a timezone the period follows local time, not UTC
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+	periods, err = scheduler.ParseQuietHours([]config.QuietHours{{Name: "night", Start: "22:00", End: "07:00"}})
+	require.NoError(t, err)
+	_, active = periods[0].ActiveAt(time.Date(2025, 3, 14, 23, 0, 0, 0, time.Local))
+	assert.True(t, active)
+	_, active = periods[0].ActiveAt(time.Date(2025, 3, 14, 23, 0, 0, 0, time.UTC)) // 04:00 the next day locally
+	assert.True(t, active)
+	_, active = periods[0].ActiveAt(time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)) // 17:00 locally
+	assert.False(t, active)
+	assert.Equal(t, "night 22:00-07:00", periods[0].String())
+	periods = []scheduler.QuietPeriod{night, lunch}
+
+	db := setupTestDB(t)
+
+	// Nothing holds messages back outside quiet hours
+	noon := time.Date(2025, 3, 14, 12, 0, 0, 0, berlin)
+	hold, err := scheduler.CurrentHold(db, periods, noon)
+	require.NoError(t, err)
+	assert.Nil(t, hold)
+
+	hold, err = scheduler.CurrentHold(db, periods, time.Date(2025, 3, 14, 23, 0, 0, 0, berlin))
+	require.NoError(t, err)
+	require.NotNil(t, hold)
+	assert.False(t, hold.Paused)
+	assert.Contains(t, hold.String(), "night")
+
+	// A pause holds everything until resumed
+	require.NoError(t, database.PauseScheduling(db, nil, "using Claude by hand"))
+	hold, err = scheduler.CurrentHold(db, periods, noon)
+	require.NoError(t, err)
+	require.NotNil(t, hold)
+	assert.True(t, hold.Paused)
+	assert.Nil(t, hold.Until)
+	assert.Equal(t, "paused (using Claude by hand)", hold.String())
+
+	require.NoError(t, database.ResumeScheduling(db))
+	hold, err = scheduler.CurrentHold(db, periods, noon)
+	require.NoError(t, err)
+	assert.Nil(t, hold)
+
+	// Timed pauses end on their own
+	over := time.Now().Add(-time.Minute)
+	require.NoError(t, database.PauseScheduling(db, &over, ""))
+	state, err := database.GetSchedulerState(db, database.SchedulerGlobal)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+	assert.False(t, state.IsPaused())
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, database.PauseScheduling(db, &later, ""))
+	hold, err = scheduler.CurrentHold(db, nil, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, hold)
+	assert.WithinDuration(t, later, *hold.Until, time.Second)
+}
+
+func TestDeferMessage(t *testing.T) {
+	db := setupTestDB(t)
+
+	window := &database.TmuxWindow{SessionName: "typing", WindowIndex: 0, Target: "typing:0", HasClaude: true, Active: true}
+	require.NoError(t, db.Create(window).Error)
+	message := &database.Message{WindowID: window.ID, Content: "later", Priority: 5, ScheduledTime: time.Now(), Status: database.MessageStatusPending}
+	require.NoError(t, db.Create(message).Error)
+
+	claimed, err := database.ClaimMessage(db, message.ID, "owner-a", database.MessageLeaseDuration)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// Only the lease owner can hand the message back
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, database.DeferMessage(db, message.ID, "owner-b", later))
+	var stored database.Message
+	require.NoError(t, db.First(&stored, message.ID).Error)
+	assert.Equal(t, database.MessageStatusSending, stored.Status)
+
+	require.NoError(t, database.DeferMessage(db, message.ID, "owner-a", later))
+	var deferred database.Message
+	require.NoError(t, db.First(&deferred, message.ID).Error)
+	assert.Equal(t, database.MessageStatusPending, deferred.Status)
+	assert.Empty(t, deferred.LeaseOwner)
+	assert.WithinDuration(t, later, deferred.ScheduledTime, time.Second)
+	assert.Zero(t, deferred.Retries, "a deferral is not a failed attempt")
+
+	attempts, err := database.GetMessageAttempts(db, message.ID)
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+}
+
+// TestDeferredMessageSpacing tests that a deferred message doesn't count as processed
+func TestDeferredMessageSpacing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no Claude usage recorded, so sending is allowed
+	sessionName := "test-defer-spacing"
+	setupTestTmuxSession(t, sessionName)
+	target := sessionName + ":0"
+
+	// Someone scrolling back in the window keeps messages out
+	require.NoError(t, exec.Command("tmux", "copy-mode", "-t", target).Run())
+
+	db := setupDaemonTestDB(t)
+	usageMonitor := monitor.NewUsageMonitor(db)
+	require.NoError(t, usageMonitor.Initialize())
+
+	window := &database.TmuxWindow{SessionName: sessionName, WindowIndex: 0, Target: target, HasClaude: true, Active: true}
+	require.NoError(t, db.Create(window).Error)
+	queue, err := database.GetOrCreateWindowMessageQueue(db, window.ID)
+	require.NoError(t, err)
+	lastProcessed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, db.Model(queue).Update("last_processed", lastProcessed).Error)
+
+	s := scheduler.NewScheduler(db, tmux.NewClient(), usageMonitor, nil)
+	require.NoError(t, s.Initialize())
+	deferred := make(chan uint, 10)
+	s.AddMessageCallback(func(event *scheduler.MessageEvent) {
+		if event.Type == "deferred" {
+			deferred <- event.Message.ID
+		}
+	})
+
+	msg, err := s.ScheduleMessage(target, "Not while you're reading", time.Now().Add(-time.Second), 5)
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Stop() })
+	s.TriggerImmediateProcessing()
+
+	select {
+	case id := <-deferred:
+		assert.Equal(t, msg.ID, id)
+	case <-time.After(10 * time.Second):
+		t.Fatal("message was not deferred")
+	}
+
+	var released database.WindowMessageQueue
+	assert.Eventually(t, func() bool {
+		require.NoError(t, db.First(&released, queue.ID).Error)
+		return released.InFlightSince == nil
+	}, 5*time.Second, 50*time.Millisecond, "queue slot was not released")
+	require.NotNil(t, released.LastProcessed)
+	assert.True(t, released.LastProcessed.Equal(lastProcessed), "a deferral must not start the queue's spacing")
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/derekxwang/tcs/internal/database"
+	"github.com/derekxwang/tcs/internal/discovery"
+	"github.com/derekxwang/tcs/internal/tmux"
+)
+
+// setupSupervisedWindow creates a tmux window showing stale Claude output and
+// registers it as a window that lost Claude
+func setupSupervisedWindow(t *testing.T, db *gorm.DB, sessionName, restartCommand string) *database.TmuxWindow {
+	setupTestTmuxSession(t, sessionName)
+
+	client := tmux.NewClient()
+	target := sessionName + ":0"
+	require.NoError(t, client.StartCommand(target, "printf 'Old %s output\\n' 'Claude Code'"))
+	time.Sleep(300 * time.Millisecond)
+
+	window := &database.TmuxWindow{
+		SessionName:       sessionName,
+		WindowIndex:       0,
+		Target:            target,
+		HasClaude:         false,
+		Active:            true,
+		SupervisionPolicy: database.SupervisionRestart,
+		RestartCommand:    restartCommand,
+	}
+	require.NoError(t, db.Create(window).Error)
+
+	return window
+}
+
+// TestSupervisorRestartsClaude tests that the restart policy brings Claude back
+func TestSupervisorRestartsClaude(t *testing.T) {
+	skipIfNoTmux(t)
+
+	db := setupTestDB(t)
+	window := setupSupervisedWindow(t, db, "test-supervise-restart", "printf 'Welcome to %s\\n' 'Claude Code'; sleep 30")
+
+	supervisor := discovery.NewSupervisor(db, tmux.NewClient(), &discovery.SupervisorConfig{
+		DefaultPolicy:  database.SupervisionNone,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		MaxAttempts:    1,
+		StartupTimeout: 15 * time.Second, // the shell can be slow to start under load
+	})
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	restarted := make(chan *database.TmuxWindow, 1)
+	supervisor.OnRestarted(func(w *database.TmuxWindow) {
+		restarted <- w
+	})
+
+	supervisor.HandleClaudeLost(window)
+
+	select {
+	case w := <-restarted:
+		assert.Equal(t, window.ID, w.ID)
+		assert.True(t, w.HasClaude)
+	case <-time.After(20 * time.Second):
+		t.Fatal("supervisor did not restart Claude")
+	}
+
+	var updated database.TmuxWindow
+	require.NoError(t, db.First(&updated, window.ID).Error)
+	assert.True(t, updated.HasClaude)
+}
+
+// TestSupervisorRetriesFailedRestart tests that a failed restart is tried again
+func TestSupervisorRetriesFailedRestart(t *testing.T) {
+	skipIfNoTmux(t)
+
+	db := setupTestDB(t)
+	marker := filepath.Join(t.TempDir(), "tried")
+	// The first run only leaves the marker, the second one starts "Claude"
+	command := fmt.Sprintf("test -f %s && { printf 'Welcome to %%s\\n' 'Claude Code'; sleep 30; } || touch %s", marker, marker)
+	window := setupSupervisedWindow(t, db, "test-supervise-retry", command)
+
+	supervisor := discovery.NewSupervisor(db, tmux.NewClient(), &discovery.SupervisorConfig{
+		DefaultPolicy:  database.SupervisionNone,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		MaxAttempts:    2,
+		StartupTimeout: 3 * time.Second,
+	})
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	restarted := make(chan *database.TmuxWindow, 1)
+	supervisor.OnRestarted(func(w *database.TmuxWindow) {
+		restarted <- w
+	})
+
+	supervisor.HandleClaudeLost(window)
+
+	select {
+	case w := <-restarted:
+		assert.Equal(t, window.ID, w.ID)
+	case <-time.After(20 * time.Second):
+		t.Fatal("supervisor did not retry the failed restart")
+	}
+}
+
+// TestSupervisorIgnoresStaleOutput tests that old Claude output left in the pane
+// does not count as a successful restart
+func TestSupervisorIgnoresStaleOutput(t *testing.T) {
+	skipIfNoTmux(t)
+
+	db := setupTestDB(t)
+	window := setupSupervisedWindow(t, db, "test-supervise-stale", "true")
+
+	supervisor := discovery.NewSupervisor(db, tmux.NewClient(), &discovery.SupervisorConfig{
+		DefaultPolicy:  database.SupervisionNone,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		MaxAttempts:    1,
+		StartupTimeout: 1 * time.Second,
+	})
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	restarted := make(chan *database.TmuxWindow, 1)
+	supervisor.OnRestarted(func(w *database.TmuxWindow) {
+		restarted <- w
+	})
+
+	supervisor.HandleClaudeLost(window)
+
+	select {
+	case <-restarted:
+		t.Fatal("stale output was treated as a restarted Claude session")
+	case <-time.After(3 * time.Second):
+	}
+
+	var updated database.TmuxWindow
+	require.NoError(t, db.First(&updated, window.ID).Error)
+	assert.False(t, updated.HasClaude)
+}
+
+// TestSupervisorPolicyDefaults tests per-window policy and command fallbacks
+func TestSupervisorPolicyDefaults(t *testing.T) {
+	supervisor := discovery.NewSupervisor(nil, nil, &discovery.SupervisorConfig{
+		DefaultPolicy:  database.SupervisionNotify,
+		RestartCommand: "claude --continue",
+	})
+
+	window := &database.TmuxWindow{}
+	assert.Equal(t, database.SupervisionNotify, supervisor.PolicyFor(window))
+	assert.Equal(t, "claude --continue", supervisor.RestartCommandFor(window))
+
+	window.SupervisionPolicy = database.SupervisionRestart
+	window.RestartCommand = "claude --resume"
+	assert.Equal(t, database.SupervisionRestart, supervisor.PolicyFor(window))
+	assert.Equal(t, "claude --resume", supervisor.RestartCommandFor(window))
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/derekxwang/tcs/internal/tui/components"
+	"github.com/derekxwang/tcs/internal/tui/theme"
+)
+
+// TestThemeRegistry tests the built-in themes, switching and cycling
+func TestThemeRegistry(t *testing.T) {
+	t.Cleanup(func() { _ = theme.Set(theme.Default) })
+
+	names := theme.Names()
+	require.GreaterOrEqual(t, len(names), 4)
+	assert.Equal(t, []string{theme.Default, theme.Dark, theme.Light, theme.HighContrast}, names[:4])
+
+	highContrast, ok := theme.Get(theme.HighContrast)
+	require.True(t, ok)
+	assert.True(t, highContrast.ASCII)
+
+	fallback, ok := theme.Get("no-such-theme")
+	assert.False(t, ok)
+	assert.Equal(t, theme.Default, fallback.Name)
+	assert.Error(t, theme.Set("no-such-theme"))
+
+	require.NoError(t, theme.Set(theme.Dark))
+	assert.Equal(t, theme.Dark, theme.Current().Name)
+	assert.Equal(t, theme.Light, theme.Next().Name)
+	assert.Equal(t, theme.Light, theme.Current().Name)
+
+	// Cycling wraps around to the first theme
+	for range names {
+		theme.Next()
+	}
+	assert.Equal(t, theme.Light, theme.Current().Name)
+}
+
+// TestCustomThemes tests loading custom themes from YAML files
+func TestCustomThemes(t *testing.T) {
+	t.Cleanup(func() { _ = theme.Set(theme.Default) })
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("ocean.yaml", "base: dark\naccent: \"#7aa2f7\"\nselected: \"24\"\n")
+	write("mono.yml", "name: mono-ascii\nascii: true\ndanger: \"15\"\n")
+	write("broken.yaml", "base: no-such-theme\n")
+	write("notes.txt", "not a theme")
+
+	names, err := theme.LoadDir(dir)
+	require.Error(t, err, "the broken theme is reported")
+	assert.Contains(t, err.Error(), "broken.yaml")
+	assert.Equal(t, []string{"mono-ascii", "ocean"}, names)
+
+	// Unset colors come from the base theme
+	ocean, ok := theme.Get("ocean")
+	require.True(t, ok)
+	dark, _ := theme.Get(theme.Dark)
+	assert.Equal(t, lipgloss.Color("#7aa2f7"), ocean.Accent)
+	assert.Equal(t, lipgloss.Color("24"), ocean.Selected)
+	assert.Equal(t, dark.Danger, ocean.Danger)
+	assert.False(t, ocean.ASCII)
+
+	mono, ok := theme.Get("mono-ascii")
+	require.True(t, ok)
+	assert.True(t, mono.ASCII)
+	assert.Equal(t, lipgloss.Color("15"), mono.Danger)
+
+	// Custom themes join the cycle and are used by components
+	assert.Contains(t, theme.Names(), "ocean")
+	require.NoError(t, theme.Set("mono-ascii"))
+	burn := components.BurnRate{Used: 30000, Limit: 100000, Elapsed: time.Hour, Remaining: 4 * time.Hour}
+	line := components.BurnRateLine(burn, 60, theme.Current())
+	assert.NotContains(t, line, "█")
+	assert.Contains(t, line, "!")
+
+	// A missing directory has no themes
+	names, err = theme.LoadDir(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
@@ -223,28 +223,31 @@ func TestTmuxClientDiscoverClaudeSessions(t *testing.T) {
 	client := tmux.NewClient()
 	target := fmt.Sprintf("%s:0", sessionName)
 
-	// Send Claude-like content
+	discovered := func() bool {
+		claudeWindows, err := client.DiscoverClaudeSessions()
+		assert.NoError(t, err)
+		for _, window := range claudeWindows {
+			if window.SessionName == sessionName {
+				return true
+			}
+		}
+		return false
+	}
+
+	// A shell merely mentioning Claude is not enough
 	err := client.SendKeys(target, "Human: Hello Claude")
 	assert.NoError(t, err)
 	err = client.SendKeys(target, "Enter")
 	assert.NoError(t, err)
-
 	time.Sleep(100 * time.Millisecond)
+	assert.False(t, discovered(), "Should not discover a shell mentioning Claude")
 
-	// Discover Claude sessions
-	claudeWindows, err := client.DiscoverClaudeSessions()
+	// Show Claude Code's interface
+	err = client.SendKeys(target, "printf 'Welcome to %s\\n  ? for %s\\n' 'Claude Code' shortcuts")
 	assert.NoError(t, err)
-
-	// Check if our session was discovered
-	found := false
-	for _, window := range claudeWindows {
-		if window.SessionName == sessionName {
-			found = true
-			break
-		}
-	}
-
-	assert.True(t, found, "Should discover session with Claude content")
+	err = client.SendKeys(target, "Enter")
+	assert.NoError(t, err)
+	assert.Eventually(t, discovered, 15*time.Second, 100*time.Millisecond, "Should discover session with Claude content")
 }
 
 // TestTmuxClientMonitorWindow tests window monitoring
@@ -359,6 +362,66 @@ func TestTmuxClientCreateAndKillSession(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestTmuxClientCreateWindow tests creating windows in new and existing sessions
+func TestTmuxClientCreateWindow(t *testing.T) {
+	skipIfNoTmux(t)
+
+	sessionName := "test-create-window"
+	client := tmux.NewClient()
+	_ = client.KillSession(sessionName)
+	t.Cleanup(func() {
+		_ = client.KillSession(sessionName)
+	})
+
+	// First window creates the session
+	first, err := client.CreateWindow(sessionName, "first", t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, sessionName, first.SessionName)
+	assert.Equal(t, "first", first.WindowName)
+	assert.True(t, client.SessionExists(sessionName))
+
+	// Second window is added to the existing session
+	second, err := client.CreateWindow(sessionName, "second", "")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.WindowIndex, second.WindowIndex)
+	assert.Equal(t, fmt.Sprintf("%s:%d", sessionName, second.WindowIndex), second.Target)
+	assert.NoError(t, client.ValidateTarget(second.Target))
+}
+
+// TestTmuxClientSpawnClaudeWindow tests waiting for the Claude prompt after spawning
+func TestTmuxClientSpawnClaudeWindow(t *testing.T) {
+	skipIfNoTmux(t)
+
+	sessionName := "test-spawn"
+	client := tmux.NewClient()
+	_ = client.KillSession(sessionName)
+	t.Cleanup(func() {
+		_ = client.KillSession(sessionName)
+	})
+
+	// The echoed command line itself must not count as a detection
+	window, err := client.SpawnClaudeWindow(tmux.SpawnOptions{
+		SessionName:    sessionName,
+		WindowName:     "idle",
+		Command:        "true",
+		StartupTimeout: 1 * time.Second,
+	})
+	assert.Error(t, err)
+	require.NotNil(t, window)
+
+	// A command that prints the Claude banner is detected
+	window, err = client.SpawnClaudeWindow(tmux.SpawnOptions{
+		SessionName:    sessionName,
+		WindowName:     "claude",
+		WorkingDir:     t.TempDir(),
+		Command:        "printf 'Welcome to %s\\n' 'Claude Code'; sleep 30",
+		StartupTimeout: 15 * time.Second,
+	})
+	assert.NoError(t, err)
+	require.NotNil(t, window)
+	assert.Equal(t, "claude", window.WindowName)
+}
+
 // TestTmuxMessageSenderConcurrency tests concurrent message sending
 func TestTmuxMessageSenderConcurrency(t *testing.T) {
 	skipIfNoTmux(t)